@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"example.com/quotaapi/internal/database"
+	osapi "example.com/quotaapi/internal/openstack"
+	"example.com/quotaapi/internal/openstack/openstacktest"
+)
+
+// TestServeMuxQuotaAPI boots the whole mux against the fake cloud.
+func TestServeMuxQuotaAPI(t *testing.T) {
+	fake := openstacktest.NewServer()
+	defer fake.Close()
+	cfg := fake.Config()
+	osc, err := osapi.NewServiceClients(cfg)
+	if err != nil {
+		t.Fatalf("NewServiceClients: %v", err)
+	}
+	srv := httptest.NewServer(newServeMux(cfg, database.NewMemoryStore(), osc))
+	defer srv.Close()
+
+	pid := fake.AddProject("student-project", "")
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		fault    *openstacktest.Fault
+		wantCode int
+		check    func(t *testing.T)
+	}{
+		{name: "healthz", method: http.MethodGet, path: "/healthz", wantCode: http.StatusOK},
+		{name: "current quota", method: http.MethodGet, path: "/quota/current?projectId=" + pid, wantCode: http.StatusOK},
+		{name: "missing project id", method: http.MethodGet, path: "/quota/current", wantCode: http.StatusBadRequest},
+		{
+			name:     "nova unavailable",
+			method:   http.MethodGet,
+			path:     "/quota/current?projectId=" + pid,
+			fault:    &openstacktest.Fault{Path: "/compute/", Status: http.StatusServiceUnavailable},
+			wantCode: http.StatusBadGateway,
+		},
+		{
+			name:     "apply",
+			method:   http.MethodPost,
+			path:     "/quota/apply",
+			body:     `{"projectId":"` + pid + `","nova":{"cores":6},"neutron":{"router":2}}`,
+			wantCode: http.StatusOK,
+			check: func(t *testing.T) {
+				if got := fake.Quota(openstacktest.Compute, pid)["cores"].Limit; got != 6 {
+					t.Errorf("cores = %d, want 6", got)
+				}
+				if got := fake.Quota(openstacktest.Network, pid)["router"].Limit; got != 2 {
+					t.Errorf("router = %d, want 2", got)
+				}
+			},
+		},
+		{
+			name:     "apply rolled back on neutron failure",
+			method:   http.MethodPost,
+			path:     "/quota/apply",
+			body:     `{"projectId":"` + pid + `","nova":{"cores":12},"neutron":{"router":5}}`,
+			fault:    &openstacktest.Fault{Method: http.MethodPut, Path: "/networking/v2.0/quotas"},
+			wantCode: http.StatusBadGateway,
+			check: func(t *testing.T) {
+				if got := fake.Quota(openstacktest.Compute, pid)["cores"].Limit; got != 6 {
+					t.Errorf("cores = %d, want rolled back to 6", got)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer fake.ClearFaults()
+			if tt.fault != nil {
+				fake.Inject(*tt.fault)
+			}
+			req, _ := http.NewRequest(tt.method, srv.URL+tt.path, strings.NewReader(tt.body))
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantCode)
+			}
+			if tt.check != nil {
+				tt.check(t)
+			}
+		})
+	}
+}
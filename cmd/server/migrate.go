@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"example.com/quotaapi/internal/config"
+	"example.com/quotaapi/internal/database"
+)
+
+const migrateUsage = "usage: server migrate [up | down [N] | status]"
+
+// runMigrate implements `server migrate ...`. 서버를 띄우지 않고 스키마만
+// 올리거나 되돌릴 때 사용한다 (OpenStack 설정은 필요 없음).
+func runMigrate(args []string) error {
+	cmd := "up"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+
+	dbCfg, err := config.LoadDatabase()
+	if err != nil {
+		return err
+	}
+	db, err := database.Open(dbCfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch cmd {
+	case "up":
+		applied, err := db.MigrateUp()
+		for _, m := range applied {
+			fmt.Printf("applied  %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+		return nil
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid step count %q\n%s", args[1], migrateUsage)
+			}
+			steps = n
+		}
+		reverted, err := db.MigrateDown(steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+
+	case "status":
+		states, err := db.MigrationStatus()
+		if err != nil {
+			return err
+		}
+		for _, st := range states {
+			applied := "pending"
+			if st.Applied {
+				applied = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-24s %s\n", st.Version, st.Name, applied)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", cmd, migrateUsage)
+	}
+}
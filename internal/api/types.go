@@ -1,17 +1,62 @@
 package api
 
+import (
+	"example.com/quotaapi/internal/models"
+	"example.com/quotaapi/internal/openstack"
+)
+
 type ApplyQuotaRequest struct {
 	ProjectID string `json:"projectId"`
 
 	Nova *struct {
-		Cores     *int `json:"cores"`
-		RAMMB     *int `json:"ramMB"`
-		Instances *int `json:"instances"`
+		Cores              *int `json:"cores"`
+		RAMMB              *int `json:"ramMB"`
+		Instances          *int `json:"instances"`
+		KeyPairs           *int `json:"keyPairs"`
+		ServerGroups       *int `json:"serverGroups"`
+		ServerGroupMembers *int `json:"serverGroupMembers"`
+		MetadataItems      *int `json:"metadataItems"`
 	} `json:"nova,omitempty"`
 
 	Cinder *struct {
-		Volumes   *int `json:"volumes"`
-		Snapshots *int `json:"snapshots"`
-		Gigabytes *int `json:"gigabytes"`
+		Volumes         *int                              `json:"volumes"`
+		Snapshots       *int                              `json:"snapshots"`
+		Gigabytes       *int                              `json:"gigabytes"`
+		Backups         *int                              `json:"backups"`
+		BackupGigabytes *int                              `json:"backupGigabytes"`
+		VolumeTypes     map[string]models.VolumeTypeQuota `json:"volumeTypes"` // -1 = 타입별 제한 없음
 	} `json:"cinder,omitempty"`
+
+	Neutron *struct {
+		Ports              *int `json:"port"`
+		FloatingIPs        *int `json:"floatingIP"`
+		Networks           *int `json:"network"`
+		Subnets            *int `json:"subnet"`
+		Routers            *int `json:"router"`
+		SecurityGroups     *int `json:"securityGroup"`
+		SecurityGroupRules *int `json:"securityGroupRule"`
+	} `json:"neutron,omitempty"`
+}
+
+// ApplyQuotaResponse is the body of POST /quota/apply.
+type ApplyQuotaResponse struct {
+	ProjectID string                         `json:"projectId"`
+	Status    string                         `json:"status"` // applied | failed
+	Results   []openstack.QuotaServiceResult `json:"results"`
+	Current   *openstack.ProjectQuota        `json:"current,omitempty"` // 적용 후 다시 읽은 값
+	Error     string                         `json:"error,omitempty"`
+}
+
+// ApplyProfileResponse is the body of POST /quota/applyProfile.
+type ApplyProfileResponse struct {
+	ProjectID string                         `json:"projectId"`
+	Profile   string                         `json:"profile"`
+	Version   int                            `json:"version"`
+	Plan      models.QuotaProfile            `json:"plan"`
+	DryRun    bool                           `json:"dryRun"`
+	Applied   bool                           `json:"applied"`
+	Current   *openstack.ProjectQuota        `json:"current"`
+	Diff      *openstack.QuotaDiff           `json:"diff,omitempty"`    // includeDiff 일 때만, 적용 전 기준
+	Results   []openstack.QuotaServiceResult `json:"results,omitempty"` // dryRun 이면 없음
+	Error     string                         `json:"error,omitempty"`
 }
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// AuthConfig controls Keystone token authentication of API callers.
+// 역할 이름은 Keystone 에 실제로 만들어 둔 role 이름과 맞춰야 한다.
+type AuthConfig struct {
+	Enabled  bool          // AUTH_ENABLED (기본 true, false 는 로컬 개발용)
+	CacheTTL time.Duration // AUTH_CACHE_TTL (기본 60s, 0 = 캐시 안 함)
+
+	AdminRole      string // AUTH_ROLE_ADMIN (기본 admin)
+	InstructorRole string // AUTH_ROLE_INSTRUCTOR (기본 instructor)
+	TARole         string // AUTH_ROLE_TA (기본 ta)
+	StudentRole    string // AUTH_ROLE_STUDENT (기본 member, 학생 프로젝트에 부여되는 역할)
+
+	// 교직원 역할(admin/instructor/ta)은 이 프로젝트나 도메인으로 스코프된
+	// 토큰에서만 인정한다. 둘 중 하나는 있어야 한다.
+	AdminProjectID string // AUTH_ADMIN_PROJECT_ID (기본 OS_ADMIN_PROJECT_ID)
+	AdminDomainID  string // AUTH_ADMIN_DOMAIN_ID (도메인 스코프 토큰용, 선택)
+}
+
+func loadAuth(adminProjectID string) (AuthConfig, error) {
+	a := AuthConfig{
+		Enabled:        true,
+		AdminProjectID: envOr("AUTH_ADMIN_PROJECT_ID", adminProjectID),
+		AdminDomainID:  os.Getenv("AUTH_ADMIN_DOMAIN_ID"),
+		AdminRole:      envOr("AUTH_ROLE_ADMIN", "admin"),
+		InstructorRole: envOr("AUTH_ROLE_INSTRUCTOR", "instructor"),
+		TARole:         envOr("AUTH_ROLE_TA", "ta"),
+		StudentRole:    envOr("AUTH_ROLE_STUDENT", "member"),
+	}
+	switch v := os.Getenv("AUTH_ENABLED"); v {
+	case "", "true", "1":
+	case "false", "0":
+		a.Enabled = false
+	default:
+		return a, fmt.Errorf("invalid AUTH_ENABLED %q (use true or false)", v)
+	}
+
+	var err error
+	if a.CacheTTL, err = envDuration("AUTH_CACHE_TTL", time.Minute); err != nil {
+		return a, err
+	}
+	if a.CacheTTL < 0 {
+		return a, fmt.Errorf("AUTH_CACHE_TTL must be >= 0")
+	}
+	if a.Enabled && a.AdminProjectID == "" && a.AdminDomainID == "" {
+		return a, fmt.Errorf("AUTH_ADMIN_PROJECT_ID (or OS_ADMIN_PROJECT_ID) or AUTH_ADMIN_DOMAIN_ID is required when AUTH_ENABLED")
+	}
+	return a, nil
+}
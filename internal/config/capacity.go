@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// CapacityMode says what happens when a change would commit more quota
+// than the cluster can deliver at the configured over-commit ratios.
+type CapacityMode string
+
+const (
+	CapacityOff   CapacityMode = "off"   // 검사하지 않음
+	CapacityWarn  CapacityMode = "warn"  // 허용하고 응답과 로그에 경고
+	CapacityBlock CapacityMode = "block" // 409 로 거부
+)
+
+// CapacityConfig controls the cluster capacity guard. 학생 프로젝트 쿼타의
+// 합(committed)이 하이퍼바이저와 Cinder 풀 용량 × 비율을 넘는 변경을 막는다.
+type CapacityConfig struct {
+	Mode      CapacityMode  // CAPACITY_MODE (기본 warn)
+	CPURatio  float64       // CAPACITY_CPU_RATIO (기본 4.0, vCPU 쿼타 합 / 물리 vCPU)
+	RAMRatio  float64       // CAPACITY_RAM_RATIO (기본 1.5)
+	DiskRatio float64       // CAPACITY_DISK_RATIO (기본 1.0, Cinder gigabytes 쿼타 합 / 풀 용량)
+	CacheTTL  time.Duration // CAPACITY_CACHE_TTL (기본 1m, 용량과 committed 합을 다시 계산하는 주기)
+}
+
+// Capacity guard defaults, also used for an empty Mode and zero ratios.
+const (
+	DefaultCapacityMode      = CapacityWarn
+	DefaultCapacityCPURatio  = 4.0
+	DefaultCapacityRAMRatio  = 1.5
+	DefaultCapacityDiskRatio = 1.0
+	DefaultCapacityCacheTTL  = time.Minute
+)
+
+func loadCapacity() (CapacityConfig, error) {
+	c := CapacityConfig{Mode: CapacityMode(os.Getenv("CAPACITY_MODE"))}
+	switch c.Mode {
+	case "":
+		c.Mode = DefaultCapacityMode
+	case CapacityOff, CapacityWarn, CapacityBlock:
+	default:
+		return c, fmt.Errorf("invalid CAPACITY_MODE %q (use off, warn or block)", c.Mode)
+	}
+	var err error
+	if c.CPURatio, err = envFloat("CAPACITY_CPU_RATIO", DefaultCapacityCPURatio); err != nil {
+		return c, err
+	}
+	if c.RAMRatio, err = envFloat("CAPACITY_RAM_RATIO", DefaultCapacityRAMRatio); err != nil {
+		return c, err
+	}
+	if c.DiskRatio, err = envFloat("CAPACITY_DISK_RATIO", DefaultCapacityDiskRatio); err != nil {
+		return c, err
+	}
+	if c.CacheTTL, err = envDuration("CAPACITY_CACHE_TTL", DefaultCapacityCacheTTL); err != nil {
+		return c, err
+	}
+	if c.CPURatio <= 0 || c.RAMRatio <= 0 || c.DiskRatio <= 0 || c.CacheTTL < 0 {
+		return c, fmt.Errorf("CAPACITY_*_RATIO must be > 0 and CAPACITY_CACHE_TTL >= 0")
+	}
+	return c, nil
+}
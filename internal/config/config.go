@@ -2,6 +2,7 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 
 	"github.com/joho/godotenv"
@@ -15,10 +16,23 @@ type Config struct {
 	ProjectName    string // OS_PROJECT_NAME
 	RegionName     string // OS_REGION_NAME
 	AdminProjectID string // OS_ADMIN_PROJECT_ID
+
+	StorageBackend string // STORAGE_BACKEND: postgres(기본) | memory(데모 모드)
+	Database       DatabaseConfig
+	Auth           AuthConfig
+	Lifecycle      LifecycleConfig
+	Reconcile      ReconcileConfig
+	Jobs           JobsConfig
+	Leader         LeaderConfig
+	Capacity       CapacityConfig
 }
 
-func Load() (*Config, error) {
+func loadDotEnv() {
 	_ = godotenv.Load(".env")
+}
+
+func Load() (*Config, error) {
+	loadDotEnv()
 
 	c := &Config{
 		AuthURL:        os.Getenv("OS_AUTH_URL"),
@@ -28,10 +42,55 @@ func Load() (*Config, error) {
 		ProjectName:    os.Getenv("OS_PROJECT_NAME"),
 		RegionName:     os.Getenv("OS_REGION_NAME"),
 		AdminProjectID: os.Getenv("OS_ADMIN_PROJECT_ID"),
+		StorageBackend: os.Getenv("STORAGE_BACKEND"),
 	}
 	if c.AuthURL == "" || c.Username == "" || c.Password == "" ||
 		c.UserDomainID == "" || c.ProjectName == "" || c.RegionName == "" {
 		return nil, errors.New("missing one or more OpenStack envs: OS_AUTH_URL, OS_USERNAME, OS_PASSWORD, OS_USER_DOMAIN_ID, OS_PROJECT_NAME, OS_REGION_NAME, OS_ADMIN_PROJECT_ID")
 	}
+	switch c.StorageBackend {
+	case "":
+		c.StorageBackend = "postgres"
+	case "postgres", "memory":
+	default:
+		return nil, fmt.Errorf("invalid STORAGE_BACKEND %q (use postgres or memory)", c.StorageBackend)
+	}
+	if c.StorageBackend == "postgres" {
+		db, err := LoadDatabase()
+		if err != nil {
+			return nil, err
+		}
+		c.Database = db
+	}
+	auth, err := loadAuth(c.AdminProjectID)
+	if err != nil {
+		return nil, err
+	}
+	c.Auth = auth
+	lifecycle, err := loadLifecycle()
+	if err != nil {
+		return nil, err
+	}
+	c.Lifecycle = lifecycle
+	reconcile, err := loadReconcile()
+	if err != nil {
+		return nil, err
+	}
+	c.Reconcile = reconcile
+	jobs, err := loadJobs()
+	if err != nil {
+		return nil, err
+	}
+	c.Jobs = jobs
+	leader, err := loadLeader()
+	if err != nil {
+		return nil, err
+	}
+	c.Leader = leader
+	capacity, err := loadCapacity()
+	if err != nil {
+		return nil, err
+	}
+	c.Capacity = capacity
 	return c, nil
 }
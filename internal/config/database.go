@@ -0,0 +1,169 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// DatabaseConfig holds PostgreSQL connection and pool settings.
+// DATABASE_URL 이 있으면 그대로 쓰고, 없으면 DB_* 개별 필드로 DSN 을 조립한다.
+// 기본값은 README 의 로컬 docker 컨테이너에 맞춰져 있다.
+type DatabaseConfig struct {
+	URL      string // DATABASE_URL
+	Host     string // DB_HOST (기본 localhost)
+	Port     int    // DB_PORT (기본 5432)
+	User     string // DB_USER (기본 quota_user)
+	Password string // DB_PASSWORD (기본 quota_password)
+	Name     string // DB_NAME (기본 quota_db)
+	SSLMode  string // DB_SSLMODE (기본 disable)
+
+	MaxOpenConns     int           // DB_MAX_OPEN_CONNS (기본 25, 0 = 무제한)
+	MaxIdleConns     int           // DB_MAX_IDLE_CONNS (기본 5)
+	ConnMaxLifetime  time.Duration // DB_CONN_MAX_LIFETIME (기본 30m, 0 = 무제한)
+	ConnMaxIdleTime  time.Duration // DB_CONN_MAX_IDLE_TIME (기본 5m, 0 = 무제한)
+	StatementTimeout time.Duration // DB_STATEMENT_TIMEOUT (기본 30s, 0 = 서버 기본값)
+}
+
+var validSSLModes = map[string]bool{
+	"disable": true, "require": true, "verify-ca": true, "verify-full": true,
+}
+
+// LoadDatabase reads and validates only the database settings, so that
+// `server migrate` works without OpenStack credentials.
+func LoadDatabase() (DatabaseConfig, error) {
+	loadDotEnv()
+
+	d := DatabaseConfig{
+		URL:      os.Getenv("DATABASE_URL"),
+		Host:     envOr("DB_HOST", "localhost"),
+		User:     envOr("DB_USER", "quota_user"),
+		Password: envOr("DB_PASSWORD", "quota_password"),
+		Name:     envOr("DB_NAME", "quota_db"),
+		SSLMode:  envOr("DB_SSLMODE", "disable"),
+	}
+
+	var err error
+	if d.Port, err = envInt("DB_PORT", 5432); err != nil {
+		return d, err
+	}
+	if d.MaxOpenConns, err = envInt("DB_MAX_OPEN_CONNS", 25); err != nil {
+		return d, err
+	}
+	if d.MaxIdleConns, err = envInt("DB_MAX_IDLE_CONNS", 5); err != nil {
+		return d, err
+	}
+	if d.ConnMaxLifetime, err = envDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute); err != nil {
+		return d, err
+	}
+	if d.ConnMaxIdleTime, err = envDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute); err != nil {
+		return d, err
+	}
+	if d.StatementTimeout, err = envDuration("DB_STATEMENT_TIMEOUT", 30*time.Second); err != nil {
+		return d, err
+	}
+
+	return d, d.Validate()
+}
+
+// Validate checks value ranges and combinations.
+func (d DatabaseConfig) Validate() error {
+	if d.URL != "" {
+		u, err := url.Parse(d.URL)
+		if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+			return fmt.Errorf("invalid DATABASE_URL: must be a postgres:// URL")
+		}
+	} else {
+		if d.Host == "" || d.User == "" || d.Name == "" {
+			return fmt.Errorf("DB_HOST, DB_USER and DB_NAME must not be empty")
+		}
+		if d.Port < 1 || d.Port > 65535 {
+			return fmt.Errorf("invalid DB_PORT %d", d.Port)
+		}
+		if !validSSLModes[d.SSLMode] {
+			return fmt.Errorf("invalid DB_SSLMODE %q (use disable, require, verify-ca or verify-full)", d.SSLMode)
+		}
+	}
+
+	if d.MaxOpenConns < 0 || d.MaxIdleConns < 0 {
+		return fmt.Errorf("DB_MAX_OPEN_CONNS and DB_MAX_IDLE_CONNS must be >= 0")
+	}
+	if d.MaxOpenConns > 0 && d.MaxIdleConns > d.MaxOpenConns {
+		return fmt.Errorf("DB_MAX_IDLE_CONNS (%d) must not exceed DB_MAX_OPEN_CONNS (%d)", d.MaxIdleConns, d.MaxOpenConns)
+	}
+	if d.ConnMaxLifetime < 0 || d.ConnMaxIdleTime < 0 || d.StatementTimeout < 0 {
+		return fmt.Errorf("DB_CONN_MAX_LIFETIME, DB_CONN_MAX_IDLE_TIME and DB_STATEMENT_TIMEOUT must be >= 0")
+	}
+	return nil
+}
+
+// DSN returns the connection URL, assembled from the discrete fields when
+// DATABASE_URL is not set.
+func (d DatabaseConfig) DSN() string {
+	if d.URL != "" {
+		return d.URL
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(d.User, d.Password),
+		Host:     net.JoinHostPort(d.Host, strconv.Itoa(d.Port)),
+		Path:     "/" + d.Name,
+		RawQuery: url.Values{"sslmode": {d.SSLMode}}.Encode(),
+	}
+	return u.String()
+}
+
+// Redacted returns DSN with the password masked, for logging.
+func (d DatabaseConfig) Redacted() string {
+	u, err := url.Parse(d.DSN())
+	if err != nil {
+		return "<invalid>"
+	}
+	return u.Redacted()
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func envInt(key string, def int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, v, err)
+	}
+	return n, nil
+}
+
+func envFloat(key string, def float64) (float64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, v, err)
+	}
+	return f, nil
+}
+
+func envDuration(key string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q (e.g. 30s, 5m): %w", key, v, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// JobsConfig controls the durable reconciliation job queue workers.
+// 수강 변경으로 생긴 리콘실은 작업으로 저장되고, 실패하면 지수 백오프로
+// 재시도한 뒤 MaxAttempts 를 넘으면 dead 가 된다.
+type JobsConfig struct {
+	Workers      int           // JOBS_WORKERS (기본 2, 0 = 워커 끔)
+	PollInterval time.Duration // JOBS_POLL_INTERVAL (기본 2s, 큐가 비었을 때 대기)
+	MaxAttempts  int           // JOBS_MAX_ATTEMPTS (기본 8)
+	BackoffBase  time.Duration // JOBS_BACKOFF_BASE (기본 5s, 첫 재시도 대기)
+	BackoffMax   time.Duration // JOBS_BACKOFF_MAX (기본 10m)
+}
+
+// Job queue defaults, also used for zero JobsConfig fields.
+const (
+	DefaultJobWorkers      = 2
+	DefaultJobPollInterval = 2 * time.Second
+	DefaultJobMaxAttempts  = 8
+	DefaultJobBackoffBase  = 5 * time.Second
+	DefaultJobBackoffMax   = 10 * time.Minute
+)
+
+func loadJobs() (JobsConfig, error) {
+	var j JobsConfig
+	var err error
+	if j.Workers, err = envInt("JOBS_WORKERS", DefaultJobWorkers); err != nil {
+		return j, err
+	}
+	if j.PollInterval, err = envDuration("JOBS_POLL_INTERVAL", DefaultJobPollInterval); err != nil {
+		return j, err
+	}
+	if j.MaxAttempts, err = envInt("JOBS_MAX_ATTEMPTS", DefaultJobMaxAttempts); err != nil {
+		return j, err
+	}
+	if j.BackoffBase, err = envDuration("JOBS_BACKOFF_BASE", DefaultJobBackoffBase); err != nil {
+		return j, err
+	}
+	if j.BackoffMax, err = envDuration("JOBS_BACKOFF_MAX", DefaultJobBackoffMax); err != nil {
+		return j, err
+	}
+	if j.Workers < 0 || j.MaxAttempts < 1 {
+		return j, fmt.Errorf("JOBS_WORKERS must be >= 0 and JOBS_MAX_ATTEMPTS >= 1")
+	}
+	if j.PollInterval <= 0 || j.BackoffBase <= 0 || j.BackoffMax < j.BackoffBase {
+		return j, fmt.Errorf("JOBS_POLL_INTERVAL and JOBS_BACKOFF_BASE must be > 0 and JOBS_BACKOFF_MAX >= JOBS_BACKOFF_BASE")
+	}
+	return j, nil
+}
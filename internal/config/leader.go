@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// LeaderConfig controls leader election between server replicas. 리더
+// 레플리카만 리콘실 스케줄러, 과목 종료 처리, 작업 큐 워커를 실행한다.
+type LeaderConfig struct {
+	ID            string        // LEADER_ID (기본 <hostname>-<pid>, 레플리카마다 달라야 함)
+	LeaseTTL      time.Duration // LEADER_LEASE_TTL (기본 30s, 갱신이 없으면 이 시간 뒤 다른 레플리카가 리더)
+	RenewInterval time.Duration // LEADER_RENEW_INTERVAL (기본 10s)
+}
+
+// Leader election defaults, also used for zero LeaderConfig fields.
+const (
+	DefaultLeaderLeaseTTL      = 30 * time.Second
+	DefaultLeaderRenewInterval = 10 * time.Second
+)
+
+// DefaultLeaderID identifies this process when LEADER_ID is unset.
+func DefaultLeaderID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "server"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+func loadLeader() (LeaderConfig, error) {
+	l := LeaderConfig{ID: envOr("LEADER_ID", DefaultLeaderID())}
+	var err error
+	if l.LeaseTTL, err = envDuration("LEADER_LEASE_TTL", DefaultLeaderLeaseTTL); err != nil {
+		return l, err
+	}
+	if l.RenewInterval, err = envDuration("LEADER_RENEW_INTERVAL", DefaultLeaderRenewInterval); err != nil {
+		return l, err
+	}
+	if l.RenewInterval <= 0 || l.LeaseTTL < 2*l.RenewInterval {
+		return l, fmt.Errorf("LEADER_RENEW_INTERVAL must be > 0 and LEADER_LEASE_TTL at least twice as long")
+	}
+	return l, nil
+}
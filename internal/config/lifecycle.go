@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// LifecycleConfig controls the end-of-course quota lifecycle: 과목이 끝난
+// 수강은 completed 로 바뀌고, 유예 기간 동안 과목 쿼타를 유지한 뒤 회수한다.
+type LifecycleConfig struct {
+	Interval    time.Duration // LIFECYCLE_INTERVAL (기본 10m, 0 = 스케줄러 끔)
+	GracePeriod time.Duration // LIFECYCLE_GRACE_PERIOD (기본 168h, 과목 종료 후 쿼타 유지 기간)
+	WarnBefore  time.Duration // LIFECYCLE_WARN_BEFORE (기본 48h, 회수 전 경고 시점)
+	Archive     bool          // LIFECYCLE_ARCHIVE (기본 false, 남은 과목이 없으면 서버를 shelve)
+}
+
+func loadLifecycle() (LifecycleConfig, error) {
+	var l LifecycleConfig
+	var err error
+	if l.Interval, err = envDuration("LIFECYCLE_INTERVAL", 10*time.Minute); err != nil {
+		return l, err
+	}
+	if l.GracePeriod, err = envDuration("LIFECYCLE_GRACE_PERIOD", 7*24*time.Hour); err != nil {
+		return l, err
+	}
+	if l.WarnBefore, err = envDuration("LIFECYCLE_WARN_BEFORE", 48*time.Hour); err != nil {
+		return l, err
+	}
+	if l.Interval < 0 || l.GracePeriod < 0 || l.WarnBefore < 0 {
+		return l, fmt.Errorf("LIFECYCLE_INTERVAL, LIFECYCLE_GRACE_PERIOD and LIFECYCLE_WARN_BEFORE must be >= 0")
+	}
+	switch v := os.Getenv("LIFECYCLE_ARCHIVE"); v {
+	case "", "false", "0":
+	case "true", "1":
+		l.Archive = true
+	default:
+		return l, fmt.Errorf("invalid LIFECYCLE_ARCHIVE %q (use true or false)", v)
+	}
+	return l, nil
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ReconcileConfig controls bulk reconciliation: the background scheduler,
+// the worker pool size and per-service OpenStack request rates.
+type ReconcileConfig struct {
+	Schedule    Schedule // RECONCILE_SCHEDULE (기본 off)
+	Concurrency int      // RECONCILE_CONCURRENCY (기본 8, 동시에 처리할 학생 수)
+
+	// 서비스별 초당 요청 수 (0 = 제한 없음)
+	NovaRPS    float64 // RECONCILE_NOVA_RPS (기본 10)
+	CinderRPS  float64 // RECONCILE_CINDER_RPS (기본 10)
+	NeutronRPS float64 // RECONCILE_NEUTRON_RPS (기본 10)
+}
+
+// DefaultReconcileConcurrency is the worker count used when unset.
+const DefaultReconcileConcurrency = 8
+
+// Schedule is a cron-style schedule descriptor:
+//
+//	"@every 30m"  이전 실행 시작 기준 30분마다
+//	"@hourly"     매시 정각 (UTC)
+//	"@daily"      매일 자정 (UTC)
+//	"" 또는 "off"  스케줄 없음
+type Schedule struct {
+	spec  string
+	every time.Duration // @every
+	align time.Duration // @hourly / @daily: 이 단위의 경계에서 실행
+}
+
+// ParseSchedule parses a Schedule descriptor.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "" || spec == "off":
+		return Schedule{}, nil
+	case spec == "@hourly":
+		return Schedule{spec: spec, align: time.Hour}, nil
+	case spec == "@daily" || spec == "@midnight":
+		return Schedule{spec: spec, align: 24 * time.Hour}, nil
+	case strings.HasPrefix(spec, "@every "):
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return Schedule{}, fmt.Errorf("invalid schedule %q: %w", spec, err)
+		}
+		if d < time.Minute {
+			return Schedule{}, fmt.Errorf("invalid schedule %q: interval must be at least 1m", spec)
+		}
+		return Schedule{spec: spec, every: d}, nil
+	}
+	return Schedule{}, fmt.Errorf("invalid schedule %q (use @every <duration>, @hourly, @daily or off)", spec)
+}
+
+// Enabled reports whether the schedule ever fires.
+func (s Schedule) Enabled() bool { return s.every > 0 || s.align > 0 }
+
+// Next returns the first activation strictly after t.
+func (s Schedule) Next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Add(s.every)
+	}
+	return t.UTC().Truncate(s.align).Add(s.align)
+}
+
+// String returns the descriptor the schedule was parsed from ("off" if disabled).
+func (s Schedule) String() string {
+	if !s.Enabled() {
+		return "off"
+	}
+	return s.spec
+}
+
+func loadReconcile() (ReconcileConfig, error) {
+	schedule, err := ParseSchedule(os.Getenv("RECONCILE_SCHEDULE"))
+	if err != nil {
+		return ReconcileConfig{}, fmt.Errorf("RECONCILE_SCHEDULE: %w", err)
+	}
+	r := ReconcileConfig{Schedule: schedule}
+	if r.Concurrency, err = envInt("RECONCILE_CONCURRENCY", DefaultReconcileConcurrency); err != nil {
+		return r, err
+	}
+	if r.Concurrency < 1 || r.Concurrency > 256 {
+		return r, fmt.Errorf("RECONCILE_CONCURRENCY must be between 1 and 256")
+	}
+	for _, v := range []struct {
+		key string
+		dst *float64
+	}{
+		{"RECONCILE_NOVA_RPS", &r.NovaRPS},
+		{"RECONCILE_CINDER_RPS", &r.CinderRPS},
+		{"RECONCILE_NEUTRON_RPS", &r.NeutronRPS},
+	} {
+		if *v.dst, err = envFloat(v.key, 10); err != nil {
+			return r, err
+		}
+		if *v.dst < 0 {
+			return r, fmt.Errorf("%s must be >= 0", v.key)
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"example.com/quotaapi/internal/models"
+	"github.com/lib/pq"
+)
+
+// ErrAggregationRuleNotFound is returned when deleting a rule that does not exist.
+var ErrAggregationRuleNotFound = errors.New("aggregation rule not found")
+
+const aggregationRuleColumns = `scope, value, policy, COALESCE(cap_profile, ''), weights, created_at, updated_at`
+
+// SetAggregationRule creates or replaces the rule for (scope, value)
+func (db *Database) SetAggregationRule(rule *models.AggregationRule) error {
+	query := `
+		INSERT INTO aggregation_rules (scope, value, policy, cap_profile, weights)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		ON CONFLICT (scope, value) DO UPDATE SET
+			policy = EXCLUDED.policy,
+			cap_profile = EXCLUDED.cap_profile,
+			weights = EXCLUDED.weights,
+			updated_at = now()
+		RETURNING created_at, updated_at
+	`
+	var weights any
+	if len(rule.Weights) > 0 {
+		weights = pq.Array(rule.Weights)
+	}
+	err := db.db.QueryRow(query, rule.Scope, rule.Value, rule.Policy, rule.CapProfile, weights).
+		Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		if isPQError(err, pqForeignKeyViolation) {
+			return fmt.Errorf("%w: %s", ErrProfileNotFound, rule.CapProfile)
+		}
+		return fmt.Errorf("failed to set aggregation rule: %w", err)
+	}
+	return nil
+}
+
+// ListAggregationRules retrieves every aggregation rule
+func (db *Database) ListAggregationRules() ([]models.AggregationRule, error) {
+	query := "SELECT " + aggregationRuleColumns + " FROM aggregation_rules ORDER BY scope DESC, value"
+	return db.queryAggregationRules(query)
+}
+
+// MatchAggregationRules retrieves the global rule and the rule of the
+// given department, if they exist.
+func (db *Database) MatchAggregationRules(department string) ([]models.AggregationRule, error) {
+	query := "SELECT " + aggregationRuleColumns + ` FROM aggregation_rules
+		WHERE scope = 'global' OR (scope = 'department' AND value = $1)
+		ORDER BY scope DESC, value`
+	return db.queryAggregationRules(query, department)
+}
+
+// DeleteAggregationRule deletes the rule for (scope, value)
+func (db *Database) DeleteAggregationRule(scope models.AggregationScope, value string) error {
+	result, err := db.db.Exec(`DELETE FROM aggregation_rules WHERE scope = $1 AND value = $2`, scope, value)
+	if err != nil {
+		return fmt.Errorf("failed to delete aggregation rule: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s=%s", ErrAggregationRuleNotFound, scope, value)
+	}
+	return nil
+}
+
+func (db *Database) queryAggregationRules(query string, args ...any) ([]models.AggregationRule, error) {
+	rows, err := db.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list aggregation rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []models.AggregationRule
+	for rows.Next() {
+		var r models.AggregationRule
+		var weights pq.Float64Array
+		if err := rows.Scan(&r.Scope, &r.Value, &r.Policy, &r.CapProfile, &weights, &r.CreatedAt, &r.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan aggregation rule: %w", err)
+		}
+		if len(weights) > 0 {
+			r.Weights = weights
+		}
+		rules = append(rules, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating aggregation rules: %w", err)
+	}
+	return rules, nil
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"example.com/quotaapi/internal/models"
+)
+
+// ErrBaselineRuleNotFound is returned when deleting a rule that does not exist.
+var ErrBaselineRuleNotFound = errors.New("baseline rule not found")
+
+const baselineRuleColumns = `scope, value, profile_name, created_at, updated_at`
+
+// SetBaselineRule creates or replaces the rule for (scope, value)
+func (db *Database) SetBaselineRule(rule *models.BaselineRule) error {
+	query := `
+		INSERT INTO baseline_rules (scope, value, profile_name)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (scope, value) DO UPDATE SET
+			profile_name = EXCLUDED.profile_name,
+			updated_at = now()
+		RETURNING created_at, updated_at
+	`
+	err := db.db.QueryRow(query, rule.Scope, rule.Value, rule.ProfileName).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		if isPQError(err, pqForeignKeyViolation) {
+			return fmt.Errorf("%w: %s", ErrProfileNotFound, rule.ProfileName)
+		}
+		return fmt.Errorf("failed to set baseline rule: %w", err)
+	}
+	return nil
+}
+
+// ListBaselineRules retrieves the rules of one scope, or all rules if scope is empty
+func (db *Database) ListBaselineRules(scope models.BaselineScope) ([]models.BaselineRule, error) {
+	query := "SELECT " + baselineRuleColumns + " FROM baseline_rules WHERE $1 = '' OR scope = $1 ORDER BY scope, value"
+	return db.queryBaselineRules(query, string(scope))
+}
+
+// MatchBaselineRules retrieves every rule that applies to the student
+// (학과, cohort, 학생 ID 중 하나라도 일치하는 규칙)
+func (db *Database) MatchBaselineRules(student *models.Student) ([]models.BaselineRule, error) {
+	query := "SELECT " + baselineRuleColumns + ` FROM baseline_rules
+		WHERE (scope = 'department' AND value = $1)
+		   OR (scope = 'cohort' AND value = $2 AND $2 <> '')
+		   OR (scope = 'student' AND value = $3)
+		ORDER BY scope, value`
+	return db.queryBaselineRules(query, student.Department, student.Cohort, student.StudentID)
+}
+
+// DeleteBaselineRule deletes the rule for (scope, value)
+func (db *Database) DeleteBaselineRule(scope models.BaselineScope, value string) error {
+	result, err := db.db.Exec(`DELETE FROM baseline_rules WHERE scope = $1 AND value = $2`, scope, value)
+	if err != nil {
+		return fmt.Errorf("failed to delete baseline rule: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s=%s", ErrBaselineRuleNotFound, scope, value)
+	}
+	return nil
+}
+
+func (db *Database) queryBaselineRules(query string, args ...any) ([]models.BaselineRule, error) {
+	rows, err := db.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list baseline rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []models.BaselineRule
+	for rows.Next() {
+		var r models.BaselineRule
+		if err := rows.Scan(&r.Scope, &r.Value, &r.ProfileName, &r.CreatedAt, &r.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan baseline rule: %w", err)
+		}
+		rules = append(rules, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating baseline rules: %w", err)
+	}
+	return rules, nil
+}
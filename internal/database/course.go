@@ -12,16 +12,12 @@ import (
 // CreateCourse creates a new course in the database
 func (db *Database) CreateCourse(course *models.Course) error {
 	query := `
-		INSERT INTO courses (course_id, title, department, semester, start_at, end_at, quota_profile, defaults, created_at)
+		INSERT INTO courses (course_id, title, department, semester, start_at, end_at, profile_name, defaults, created_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
-	quotaProfileJSON, err := json.Marshal(course.QuotaProfile)
-	if err != nil {
-		return fmt.Errorf("failed to marshal quota profile: %w", err)
-	}
-
 	var defaultsJSON []byte
+	var err error
 	if course.Defaults != nil {
 		// 디버깅: 실제 데이터 확인
 		fmt.Printf("DEBUG: course.Defaults = %+v\n", course.Defaults)
@@ -41,12 +37,15 @@ func (db *Database) CreateCourse(course *models.Course) error {
 		course.Semester,
 		course.StartAt,
 		course.EndAt,
-		quotaProfileJSON,
+		course.ProfileName,
 		defaultsJSON,
 		course.CreatedAt,
 	)
 
 	if err != nil {
+		if isPQError(err, pqForeignKeyViolation) {
+			return fmt.Errorf("%w: %s", ErrProfileNotFound, course.ProfileName)
+		}
 		return fmt.Errorf("failed to create course: %w", err)
 	}
 
@@ -56,16 +55,16 @@ func (db *Database) CreateCourse(course *models.Course) error {
 // GetCourse retrieves a course by ID
 func (db *Database) GetCourse(courseID string) (*models.Course, error) {
 	query := `
-		SELECT course_id, title, department, semester, start_at, end_at, quota_profile, defaults, created_at
+		SELECT course_id, title, department, semester, start_at, end_at, profile_name, defaults, created_at
 		FROM courses WHERE course_id = $1
 	`
 	row := db.db.QueryRow(query, courseID)
 
 	var course models.Course
-	var quotaProfileJSON, defaultsJSON []byte
+	var defaultsJSON []byte
 
 	err := row.Scan(&course.CourseID, &course.Title, &course.Department, &course.Semester,
-		&course.StartAt, &course.EndAt, &quotaProfileJSON, &defaultsJSON, &course.CreatedAt)
+		&course.StartAt, &course.EndAt, &course.ProfileName, &defaultsJSON, &course.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("course not found: %s", courseID)
@@ -73,11 +72,6 @@ func (db *Database) GetCourse(courseID string) (*models.Course, error) {
 		return nil, fmt.Errorf("failed to get course: %w", err)
 	}
 
-	// Parse quota profile JSON
-	if err := json.Unmarshal(quotaProfileJSON, &course.QuotaProfile); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal quota profile: %w", err)
-	}
-
 	// Parse defaults JSON if present
 	if defaultsJSON != nil {
 		course.Defaults = &models.CourseDefaults{}
@@ -91,6 +85,11 @@ func (db *Database) GetCourse(courseID string) (*models.Course, error) {
 
 // UpdateCourse updates an existing course
 func (db *Database) UpdateCourse(courseID string, updates map[string]interface{}) error {
+	return updateCourse(db.db, courseID, updates)
+}
+
+// updateCourse runs UpdateCourse on db or inside a transaction.
+func updateCourse(ex execer, courseID string, updates map[string]interface{}) error {
 	if len(updates) == 0 {
 		return nil
 	}
@@ -111,8 +110,11 @@ func (db *Database) UpdateCourse(courseID string, updates map[string]interface{}
 	query += fmt.Sprintf(", updated_at = $%d WHERE course_id = $%d", argCount, argCount+1)
 	args = append(args, time.Now(), courseID)
 
-	_, err := db.db.Exec(query, args...)
+	_, err := ex.Exec(query, args...)
 	if err != nil {
+		if isPQError(err, pqForeignKeyViolation) {
+			return fmt.Errorf("%w: %v", ErrProfileNotFound, updates["profile_name"])
+		}
 		return fmt.Errorf("failed to update course: %w", err)
 	}
 	return nil
@@ -143,19 +145,19 @@ func (db *Database) ListCourses(department, semester string) ([]models.Course, e
 	var args []interface{}
 
 	if department != "" && semester != "" {
-		query = `SELECT course_id, title, department, semester, start_at, end_at, quota_profile, defaults, created_at
+		query = `SELECT course_id, title, department, semester, start_at, end_at, profile_name, defaults, created_at
 				 FROM courses WHERE department = $1 AND semester = $2 ORDER BY start_at DESC`
 		args = []interface{}{department, semester}
 	} else if department != "" {
-		query = `SELECT course_id, title, department, semester, start_at, end_at, quota_profile, defaults, created_at
+		query = `SELECT course_id, title, department, semester, start_at, end_at, profile_name, defaults, created_at
 				 FROM courses WHERE department = $1 ORDER BY start_at DESC`
 		args = []interface{}{department}
 	} else if semester != "" {
-		query = `SELECT course_id, title, department, semester, start_at, end_at, quota_profile, defaults, created_at
-				 FROM semester = $1 ORDER BY start_at DESC`
+		query = `SELECT course_id, title, department, semester, start_at, end_at, profile_name, defaults, created_at
+				 FROM courses WHERE semester = $1 ORDER BY start_at DESC`
 		args = []interface{}{semester}
 	} else {
-		query = `SELECT course_id, title, department, semester, start_at, end_at, quota_profile, defaults, created_at
+		query = `SELECT course_id, title, department, semester, start_at, end_at, profile_name, defaults, created_at
 				 FROM courses ORDER BY start_at DESC`
 	}
 
@@ -168,19 +170,14 @@ func (db *Database) ListCourses(department, semester string) ([]models.Course, e
 	var courses []models.Course
 	for rows.Next() {
 		var course models.Course
-		var quotaProfileJSON, defaultsJSON []byte
+		var defaultsJSON []byte
 
 		err := rows.Scan(&course.CourseID, &course.Title, &course.Department, &course.Semester,
-			&course.StartAt, &course.EndAt, &quotaProfileJSON, &defaultsJSON, &course.CreatedAt)
+			&course.StartAt, &course.EndAt, &course.ProfileName, &defaultsJSON, &course.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan course: %w", err)
 		}
 
-		// Parse quota profile JSON
-		if err := json.Unmarshal(quotaProfileJSON, &course.QuotaProfile); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal quota profile: %w", err)
-		}
-
 		// Parse defaults JSON if present
 		if defaultsJSON != nil {
 			course.Defaults = &models.CourseDefaults{}
@@ -202,7 +199,7 @@ func (db *Database) ListCourses(department, semester string) ([]models.Course, e
 // GetActiveCourses retrieves courses that are currently active
 func (db *Database) GetActiveCourses() ([]models.Course, error) {
 	query := `
-		SELECT course_id, title, department, semester, start_at, end_at, quota_profile, defaults, created_at
+		SELECT course_id, title, department, semester, start_at, end_at, profile_name, defaults, created_at
 		FROM courses 
 		WHERE start_at <= $1 AND end_at >= $1
 		ORDER BY start_at DESC
@@ -218,19 +215,14 @@ func (db *Database) GetActiveCourses() ([]models.Course, error) {
 	var courses []models.Course
 	for rows.Next() {
 		var course models.Course
-		var quotaProfileJSON, defaultsJSON []byte
+		var defaultsJSON []byte
 
 		err := rows.Scan(&course.CourseID, &course.Title, &course.Department, &course.Semester,
-			&course.StartAt, &course.EndAt, &quotaProfileJSON, &defaultsJSON, &course.CreatedAt)
+			&course.StartAt, &course.EndAt, &course.ProfileName, &defaultsJSON, &course.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan course: %w", err)
 		}
 
-		// Parse quota profile JSON
-		if err := json.Unmarshal(quotaProfileJSON, &course.QuotaProfile); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal quota profile: %w", err)
-		}
-
 		// Parse defaults JSON if present
 		if defaultsJSON != nil {
 			course.Defaults = &models.CourseDefaults{}
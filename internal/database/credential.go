@@ -0,0 +1,63 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrCredentialTokenInvalid is returned when a retrieval token does not
+// match, has expired or was already redeemed.
+var ErrCredentialTokenInvalid = errors.New("credential token is invalid, expired or already used")
+
+// SaveCredentialToken stores (or replaces) the hashed one-time retrieval
+// token for a student. 이전 토큰은 즉시 무효화된다.
+func (db *Database) SaveCredentialToken(studentID, tokenHash string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO student_credentials (student_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (student_id) DO UPDATE SET
+			token_hash = EXCLUDED.token_hash,
+			expires_at = EXCLUDED.expires_at,
+			created_at = now(),
+			redeemed_at = NULL
+	`
+	if _, err := db.db.Exec(query, studentID, tokenHash, expiresAt); err != nil {
+		return fmt.Errorf("failed to save credential token: %w", err)
+	}
+	return nil
+}
+
+// ConsumeCredentialToken atomically marks the token as redeemed. 일치하는
+// 미사용·미만료 토큰이 없으면 ErrCredentialTokenInvalid 를 돌려준다.
+func (db *Database) ConsumeCredentialToken(studentID, tokenHash string) error {
+	query := `
+		UPDATE student_credentials SET redeemed_at = now()
+		WHERE student_id = $1 AND token_hash = $2
+		  AND redeemed_at IS NULL AND expires_at > now()
+	`
+	result, err := db.db.Exec(query, studentID, tokenHash)
+	if err != nil {
+		return fmt.Errorf("failed to consume credential token: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to consume credential token: %w", err)
+	} else if n == 0 {
+		return ErrCredentialTokenInvalid
+	}
+	return nil
+}
+
+// RearmCredentialToken makes a consumed token usable again with its
+// original expiry. 그 사이 새 토큰이 발급되어 token_hash 가 바뀌었으면 새
+// 토큰을 덮어쓰지 않도록 아무것도 하지 않는다.
+func (db *Database) RearmCredentialToken(studentID, tokenHash string) error {
+	query := `
+		UPDATE student_credentials SET redeemed_at = NULL
+		WHERE student_id = $1 AND token_hash = $2 AND redeemed_at IS NOT NULL
+	`
+	if _, err := db.db.Exec(query, studentID, tokenHash); err != nil {
+		return fmt.Errorf("failed to re-arm credential token: %w", err)
+	}
+	return nil
+}
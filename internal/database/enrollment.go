@@ -1,22 +1,85 @@
 package database
 
 import (
+	"database/sql"
 	"fmt"
+	"time"
 
 	"example.com/quotaapi/internal/models"
 )
 
+// enrollmentColumns is the SELECT list matched by scanEnrollment.
+const enrollmentColumns = `student_id, course_id, status, start_at, end_at, grace_until, warned_at, reclaimed_at`
+
+func scanEnrollment(row rowScanner, e *models.Enrollment) error {
+	var graceUntil, warnedAt, reclaimedAt sql.NullTime
+	if err := row.Scan(&e.StudentID, &e.CourseID, &e.Status, &e.StartAt, &e.EndAt, &graceUntil, &warnedAt, &reclaimedAt); err != nil {
+		return err
+	}
+	e.GraceUntil = nullTimePtr(graceUntil)
+	e.WarnedAt = nullTimePtr(warnedAt)
+	e.ReclaimedAt = nullTimePtr(reclaimedAt)
+	return nil
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// queryEnrollments runs a SELECT of enrollmentColumns and scans every row
+func (db *Database) queryEnrollments(query string, args ...any) ([]models.Enrollment, error) {
+	rows, err := db.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query enrollments: %w", err)
+	}
+	defer rows.Close()
+
+	var enrollments []models.Enrollment
+	for rows.Next() {
+		var e models.Enrollment
+		if err := scanEnrollment(rows, &e); err != nil {
+			return nil, fmt.Errorf("failed to scan enrollment: %w", err)
+		}
+		enrollments = append(enrollments, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return enrollments, nil
+}
+
+// execer is *sql.DB or *sql.Tx, so a statement can run alone or inside a
+// transaction (예: 수강 변경 + 리콘실 작업 등록).
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// EnrollStudent inserts or replaces an enrollment. 재수강 시 이전 수명주기
+// 기록(grace_until 등)도 요청 값으로 덮어쓴다.
 func (db *Database) EnrollStudent(enrollment *models.Enrollment) error {
+	return enrollStudent(db.db, enrollment)
+}
+
+func enrollStudent(ex execer, enrollment *models.Enrollment) error {
 	query := `
-		INSERT INTO enrollments (student_id, course_id, status, start_at, end_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO enrollments (student_id, course_id, status, start_at, end_at, grace_until, warned_at, reclaimed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		ON CONFLICT (student_id, course_id) DO UPDATE SET
 		status = EXCLUDED.status,
 		start_at = EXCLUDED.start_at,
-		end_at = EXCLUDED.end_at
+		end_at = EXCLUDED.end_at,
+		grace_until = EXCLUDED.grace_until,
+		warned_at = EXCLUDED.warned_at,
+		reclaimed_at = EXCLUDED.reclaimed_at
 	`
 
-	_, err := db.db.Exec(query, enrollment.StudentID, enrollment.CourseID, enrollment.Status, enrollment.StartAt, enrollment.EndAt)
+	_, err := ex.Exec(query, enrollment.StudentID, enrollment.CourseID, enrollment.Status, enrollment.StartAt, enrollment.EndAt,
+		enrollment.GraceUntil, enrollment.WarnedAt, enrollment.ReclaimedAt)
 	if err != nil {
 		return fmt.Errorf("failed to enroll student: %w", err)
 	}
@@ -25,9 +88,13 @@ func (db *Database) EnrollStudent(enrollment *models.Enrollment) error {
 }
 
 func (db *Database) UnenrollStudent(studentID, courseID string) error {
+	return unenrollStudent(db.db, studentID, courseID)
+}
+
+func unenrollStudent(ex execer, studentID, courseID string) error {
 	query := `DELETE FROM enrollments WHERE student_id = $1 AND course_id = $2`
 
-	result, err := db.db.Exec(query, studentID, courseID)
+	result, err := ex.Exec(query, studentID, courseID)
 	if err != nil {
 		return fmt.Errorf("failed to unenroll student: %w", err)
 	}
@@ -45,97 +112,74 @@ func (db *Database) UnenrollStudent(studentID, courseID string) error {
 }
 
 func (db *Database) GetStudentEnrollments(studentID string) ([]models.Enrollment, error) {
-	query := `
-		SELECT student_id, course_id, status, start_at, end_at
-		FROM enrollments
-		WHERE student_id = $1
-		ORDER BY start_at DESC
-	`
-
-	rows, err := db.db.Query(query, studentID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query enrollments: %w", err)
-	}
-	defer rows.Close()
-
-	var enrollments []models.Enrollment
-	for rows.Next() {
-		var e models.Enrollment
-		err := rows.Scan(&e.StudentID, &e.CourseID, &e.Status, &e.StartAt, &e.EndAt)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan enrollment: %w", err)
-		}
-		enrollments = append(enrollments, e)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating rows: %w", err)
-	}
-
-	return enrollments, nil
+	query := "SELECT " + enrollmentColumns + " FROM enrollments WHERE student_id = $1 ORDER BY start_at DESC"
+	return db.queryEnrollments(query, studentID)
 }
 
 func (db *Database) GetActiveEnrollments() ([]models.Enrollment, error) {
-	query := `
-		SELECT student_id, course_id, status, start_at, end_at
+	query := "SELECT " + enrollmentColumns + `
 		FROM enrollments
-		WHERE status = 'active' 
+		WHERE status = 'active'
 		AND now() BETWEEN start_at AND end_at
-		ORDER BY start_at DESC
-	`
-
-	rows, err := db.db.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query active enrollments: %w", err)
-	}
-	defer rows.Close()
-
-	var enrollments []models.Enrollment
-	for rows.Next() {
-		var e models.Enrollment
-		err := rows.Scan(&e.StudentID, &e.CourseID, &e.Status, &e.StartAt, &e.EndAt)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan enrollment: %w", err)
-		}
-		enrollments = append(enrollments, e)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating rows: %w", err)
-	}
-
-	return enrollments, nil
+		ORDER BY start_at DESC`
+	return db.queryEnrollments(query)
 }
 
 func (db *Database) GetActiveEnrollmentsByStudent(studentID string) ([]models.Enrollment, error) {
-	query := `
-		SELECT student_id, course_id, status, start_at, end_at
+	query := "SELECT " + enrollmentColumns + `
 		FROM enrollments
-		WHERE student_id = $1 
-		AND status = 'active' 
+		WHERE student_id = $1
+		AND status = 'active'
 		AND now() BETWEEN start_at AND end_at
-		ORDER BY start_at DESC
-	`
+		ORDER BY start_at DESC`
+	return db.queryEnrollments(query, studentID)
+}
 
-	rows, err := db.db.Query(query, studentID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query active enrollments: %w", err)
+// ListCourseEnrollments lists every enrollment of a course regardless of status
+func (db *Database) ListCourseEnrollments(courseID string) ([]models.Enrollment, error) {
+	query := "SELECT " + enrollmentColumns + " FROM enrollments WHERE course_id = $1 ORDER BY student_id"
+	return db.queryEnrollments(query, courseID)
+}
+
+// ListEnrollmentsByStatus retrieves every enrollment with the status,
+// ordered by end_at (수명주기 스케줄러가 종료 순서대로 처리한다)
+func (db *Database) ListEnrollmentsByStatus(status string) ([]models.Enrollment, error) {
+	query := "SELECT " + enrollmentColumns + " FROM enrollments WHERE status = $1 ORDER BY end_at, student_id, course_id"
+	return db.queryEnrollments(query, status)
+}
+
+// UpdateEnrollment updates columns of one enrollment
+func (db *Database) UpdateEnrollment(studentID, courseID string, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return nil
 	}
-	defer rows.Close()
 
-	var enrollments []models.Enrollment
-	for rows.Next() {
-		var e models.Enrollment
-		err := rows.Scan(&e.StudentID, &e.CourseID, &e.Status, &e.StartAt, &e.EndAt)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan enrollment: %w", err)
+	query := "UPDATE enrollments SET "
+	args := []interface{}{}
+	argCount := 1
+
+	for field, value := range updates {
+		if argCount > 1 {
+			query += ", "
 		}
-		enrollments = append(enrollments, e)
+		query += fmt.Sprintf("%s = $%d", field, argCount)
+		args = append(args, value)
+		argCount++
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating rows: %w", err)
-	}
+	query += fmt.Sprintf(" WHERE student_id = $%d AND course_id = $%d", argCount, argCount+1)
+	args = append(args, studentID, courseID)
 
-	return enrollments, nil
+	result, err := db.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update enrollment: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("enrollment not found")
+	}
+	return nil
 }
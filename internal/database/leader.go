@@ -0,0 +1,67 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"example.com/quotaapi/internal/models"
+)
+
+const leaderLeaseColumns = "name, holder, acquired_at, renewed_at, expires_at"
+
+func scanLeaderLease(row rowScanner, lease *models.LeaderLease) error {
+	return row.Scan(&lease.Name, &lease.Holder, &lease.AcquiredAt, &lease.RenewedAt, &lease.ExpiresAt)
+}
+
+// AcquireLeaderLease takes or renews the named lease for holder for ttl and
+// returns the lease as it stands afterwards. 다른 holder 의 lease 가 아직
+// 유효하면 바꾸지 않고 그 lease 를 돌려주므로, 호출자는 Holder 로 리더인지 판단한다.
+// 만료 판단은 DB 시각 기준이라 레플리카 간 시계 차이에 영향받지 않는다.
+func (db *Database) AcquireLeaderLease(name, holder string, ttl time.Duration) (*models.LeaderLease, error) {
+	query := `
+		INSERT INTO leader_leases (name, holder, acquired_at, renewed_at, expires_at)
+		VALUES ($1, $2, now(), now(), now() + $3 * interval '1 millisecond')
+		ON CONFLICT (name) DO UPDATE SET
+			holder = EXCLUDED.holder,
+			acquired_at = CASE WHEN leader_leases.holder = EXCLUDED.holder
+				THEN leader_leases.acquired_at ELSE now() END,
+			renewed_at = now(),
+			expires_at = EXCLUDED.expires_at
+		WHERE leader_leases.holder = EXCLUDED.holder OR leader_leases.expires_at < now()
+		RETURNING ` + leaderLeaseColumns
+	lease := &models.LeaderLease{}
+	err := scanLeaderLease(db.db.QueryRow(query, name, holder, ttl.Milliseconds()), lease)
+	if err == sql.ErrNoRows {
+		// 다른 holder 가 유효한 lease 를 가지고 있음
+		return db.GetLeaderLease(name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire leader lease %s: %w", name, err)
+	}
+	return lease, nil
+}
+
+// ReleaseLeaderLease gives up the lease if holder still has it, so another
+// replica can take over without waiting for it to expire.
+func (db *Database) ReleaseLeaderLease(name, holder string) error {
+	_, err := db.db.Exec("DELETE FROM leader_leases WHERE name = $1 AND holder = $2", name, holder)
+	if err != nil {
+		return fmt.Errorf("failed to release leader lease %s: %w", name, err)
+	}
+	return nil
+}
+
+// GetLeaderLease returns the named lease, or nil if nobody has taken it.
+// 만료된 lease 도 그대로 돌려준다.
+func (db *Database) GetLeaderLease(name string) (*models.LeaderLease, error) {
+	lease := &models.LeaderLease{}
+	query := "SELECT " + leaderLeaseColumns + " FROM leader_leases WHERE name = $1"
+	if err := scanLeaderLease(db.db.QueryRow(query, name), lease); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get leader lease %s: %w", name, err)
+	}
+	return lease, nil
+}
@@ -0,0 +1,1095 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"example.com/quotaapi/internal/models"
+)
+
+// MemoryStore is an in-memory Store for demo mode and unit tests.
+// PostgreSQL 구현과 같은 제약(PK 중복, FK, ON DELETE CASCADE, upsert)을 흉내 낸다.
+type MemoryStore struct {
+	mu          sync.RWMutex
+	students    map[string]models.Student
+	courses     map[string]models.Course
+	enrollments map[string]map[string]models.Enrollment // studentID → courseID → enrollment
+	credentials map[string]credentialToken              // studentID → retrieval token
+	profiles    map[string]models.Profile
+	versions    map[string][]models.ProfileVersion // profile name → 버전 이력 (오래된 순)
+	baselines   map[baselineKey]models.BaselineRule
+	aggregation map[aggregationKey]models.AggregationRule
+	runs        map[int64]models.ReconciliationRun
+	runResults  map[int64][]models.ReconciliationResult
+	nextRunID   int64 // BIGSERIAL
+	jobs        map[int64]models.ReconcileJob
+	nextJobID   int64 // BIGSERIAL
+	leases      map[string]models.LeaderLease
+}
+
+type aggregationKey struct {
+	scope models.AggregationScope
+	value string
+}
+
+type baselineKey struct {
+	scope models.BaselineScope
+	value string
+}
+
+type credentialToken struct {
+	hash      string
+	expiresAt time.Time
+	redeemed  bool
+}
+
+// NewMemoryStore creates an in-memory store whose profile catalog is
+// seeded with models.BuiltinProfiles, like migration 6 does.
+func NewMemoryStore() *MemoryStore {
+	m := &MemoryStore{
+		students:    map[string]models.Student{},
+		courses:     map[string]models.Course{},
+		enrollments: map[string]map[string]models.Enrollment{},
+		credentials: map[string]credentialToken{},
+		profiles:    map[string]models.Profile{},
+		versions:    map[string][]models.ProfileVersion{},
+		baselines:   map[baselineKey]models.BaselineRule{},
+		aggregation: map[aggregationKey]models.AggregationRule{},
+		runs:        map[int64]models.ReconciliationRun{},
+		runResults:  map[int64][]models.ReconciliationResult{},
+		jobs:        map[int64]models.ReconcileJob{},
+		leases:      map[string]models.LeaderLease{},
+	}
+	for name, limits := range models.BuiltinProfiles {
+		_ = m.CreateProfile(&models.Profile{Name: name, Limits: limits})
+	}
+	return m
+}
+
+// Close is a no-op kept for symmetry with *Database
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+// ---- students ----
+
+func (m *MemoryStore) CreateStudent(student *models.Student) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.students[student.StudentID]; ok {
+		return fmt.Errorf("failed to create student: duplicate student_id %s", student.StudentID)
+	}
+	if student.BootstrapState == "" {
+		student.BootstrapState = models.BootstrapPending
+	}
+	s := *student
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = time.Now()
+	}
+	m.students[s.StudentID] = s
+	return nil
+}
+
+func (m *MemoryStore) GetStudent(studentID string) (*models.Student, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.students[studentID]
+	if !ok {
+		return nil, fmt.Errorf("failed to get student %s: %w", studentID, sql.ErrNoRows)
+	}
+	return &s, nil
+}
+
+func (m *MemoryStore) UpdateStudent(studentID string, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.students[studentID]
+	if !ok {
+		// UPDATE ... WHERE 와 마찬가지로 대상이 없으면 조용히 무시
+		return nil
+	}
+	for field, value := range updates {
+		var err error
+		switch field {
+		case "name":
+			err = assignColumn(&s.Name, value)
+		case "email":
+			err = assignColumn(&s.Email, value)
+		case "department":
+			err = assignColumn(&s.Department, value)
+		case "cohort":
+			err = assignColumn(&s.Cohort, value)
+		case "keystone_project_id":
+			err = assignColumn(&s.KeystoneProjectID, value)
+		case "keystone_user_id":
+			err = assignColumn(&s.KeystoneUserID, value)
+		case "keystone_project_adopted":
+			err = assignColumn(&s.ProjectAdopted, value)
+		case "keystone_user_adopted":
+			err = assignColumn(&s.UserAdopted, value)
+		case "bootstrap_state":
+			err = assignColumn(&s.BootstrapState, value)
+		case "bootstrap_error":
+			err = assignColumn(&s.BootstrapError, value)
+		case "bootstrap_attempts":
+			err = assignColumn(&s.BootstrapAttempts, value)
+		case "bootstrap_updated_at":
+			err = assignColumn(&s.BootstrapUpdatedAt, value)
+		case "bootstrap_saga_id":
+			err = assignColumn(&s.BootstrapSagaID, value)
+		case "quota_state":
+			err = assignColumn(&s.QuotaState, value)
+		case "quota_blockers":
+			err = assignColumn(&s.QuotaBlockers, value)
+		default:
+			err = fmt.Errorf("column %q does not exist", field)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update student: %w", err)
+		}
+	}
+	m.students[studentID] = s
+	return nil
+}
+
+func (m *MemoryStore) DeleteStudent(studentID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.students[studentID]; !ok {
+		return fmt.Errorf("student not found: %s", studentID)
+	}
+	delete(m.students, studentID)
+	delete(m.enrollments, studentID) // ON DELETE CASCADE
+	delete(m.credentials, studentID)
+	return nil
+}
+
+func (m *MemoryStore) ListStudents(department string) ([]models.Student, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var students []models.Student
+	for _, s := range m.students {
+		if department != "" && s.Department != department {
+			continue
+		}
+		students = append(students, s)
+	}
+	sort.Slice(students, func(i, j int) bool { return students[i].StudentID < students[j].StudentID })
+	return students, nil
+}
+
+func (m *MemoryStore) GetAllStudents() ([]*models.Student, error) {
+	list, _ := m.ListStudents("")
+	students := make([]*models.Student, 0, len(list))
+	for i := range list {
+		students = append(students, &list[i])
+	}
+	return students, nil
+}
+
+func (m *MemoryStore) ListStudentsByBootstrapState(states ...models.BootstrapState) ([]models.Student, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var students []models.Student
+	for _, s := range m.students {
+		for _, st := range states {
+			if s.BootstrapState == st {
+				students = append(students, s)
+				break
+			}
+		}
+	}
+	sort.Slice(students, func(i, j int) bool {
+		if !students[i].CreatedAt.Equal(students[j].CreatedAt) {
+			return students[i].CreatedAt.Before(students[j].CreatedAt)
+		}
+		return students[i].StudentID < students[j].StudentID
+	})
+	return students, nil
+}
+
+// ---- courses ----
+
+func (m *MemoryStore) CreateCourse(course *models.Course) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.courses[course.CourseID]; ok {
+		return fmt.Errorf("failed to create course: duplicate course_id %s", course.CourseID)
+	}
+	if _, ok := m.profiles[course.ProfileName]; !ok {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, course.ProfileName)
+	}
+	c := copyCourse(*course)
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now()
+	}
+	m.courses[c.CourseID] = c
+	return nil
+}
+
+func (m *MemoryStore) GetCourse(courseID string) (*models.Course, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c, ok := m.courses[courseID]
+	if !ok {
+		return nil, fmt.Errorf("course not found: %s", courseID)
+	}
+	c = copyCourse(c)
+	return &c, nil
+}
+
+func (m *MemoryStore) UpdateCourse(courseID string, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.updateCourseLocked(courseID, updates)
+}
+
+func (m *MemoryStore) updateCourseLocked(courseID string, updates map[string]interface{}) error {
+	c, ok := m.courses[courseID]
+	if !ok {
+		return nil
+	}
+	for field, value := range updates {
+		var err error
+		switch field {
+		case "title":
+			err = assignColumn(&c.Title, value)
+		case "department":
+			err = assignColumn(&c.Department, value)
+		case "semester":
+			err = assignColumn(&c.Semester, value)
+		case "start_at":
+			err = assignColumn(&c.StartAt, value)
+		case "end_at":
+			err = assignColumn(&c.EndAt, value)
+		case "profile_name":
+			err = assignColumn(&c.ProfileName, value)
+			if _, ok := m.profiles[c.ProfileName]; err == nil && !ok {
+				return fmt.Errorf("%w: %s", ErrProfileNotFound, c.ProfileName)
+			}
+		case "defaults":
+			err = assignColumn(&c.Defaults, value)
+		default:
+			err = fmt.Errorf("column %q does not exist", field)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update course: %w", err)
+		}
+	}
+	m.courses[courseID] = c
+	return nil
+}
+
+func (m *MemoryStore) DeleteCourse(courseID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.courses[courseID]; !ok {
+		return fmt.Errorf("course not found: %s", courseID)
+	}
+	delete(m.courses, courseID)
+	for _, byCourse := range m.enrollments {
+		delete(byCourse, courseID) // ON DELETE CASCADE
+	}
+	return nil
+}
+
+func (m *MemoryStore) ListCourses(department, semester string) ([]models.Course, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var courses []models.Course
+	for _, c := range m.courses {
+		if department != "" && c.Department != department {
+			continue
+		}
+		if semester != "" && c.Semester != semester {
+			continue
+		}
+		courses = append(courses, copyCourse(c))
+	}
+	sortCourses(courses)
+	return courses, nil
+}
+
+func (m *MemoryStore) GetActiveCourses() ([]models.Course, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	var courses []models.Course
+	for _, c := range m.courses {
+		if !c.StartAt.After(now) && !c.EndAt.Before(now) {
+			courses = append(courses, copyCourse(c))
+		}
+	}
+	sortCourses(courses)
+	return courses, nil
+}
+
+// ---- enrollments ----
+
+func (m *MemoryStore) EnrollStudent(enrollment *models.Enrollment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.enrollLocked(enrollment)
+}
+
+func (m *MemoryStore) enrollLocked(enrollment *models.Enrollment) error {
+	if _, ok := m.students[enrollment.StudentID]; !ok {
+		return fmt.Errorf("failed to enroll student: student %s does not exist", enrollment.StudentID)
+	}
+	if _, ok := m.courses[enrollment.CourseID]; !ok {
+		return fmt.Errorf("failed to enroll student: course %s does not exist", enrollment.CourseID)
+	}
+	switch enrollment.Status {
+	case "active", "completed", "dropped":
+	default:
+		return fmt.Errorf("failed to enroll student: invalid status %q", enrollment.Status)
+	}
+
+	if m.enrollments[enrollment.StudentID] == nil {
+		m.enrollments[enrollment.StudentID] = map[string]models.Enrollment{}
+	}
+	m.enrollments[enrollment.StudentID][enrollment.CourseID] = *enrollment // ON CONFLICT DO UPDATE
+	return nil
+}
+
+func (m *MemoryStore) UnenrollStudent(studentID, courseID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.unenrollLocked(studentID, courseID)
+}
+
+func (m *MemoryStore) unenrollLocked(studentID, courseID string) error {
+	if _, ok := m.enrollments[studentID][courseID]; !ok {
+		return fmt.Errorf("enrollment not found")
+	}
+	delete(m.enrollments[studentID], courseID)
+	return nil
+}
+
+func (m *MemoryStore) GetStudentEnrollments(studentID string) ([]models.Enrollment, error) {
+	return m.filterEnrollments(func(e models.Enrollment) bool { return e.StudentID == studentID }), nil
+}
+
+func (m *MemoryStore) GetActiveEnrollments() ([]models.Enrollment, error) {
+	now := time.Now()
+	return m.filterEnrollments(func(e models.Enrollment) bool { return isActiveAt(e, now) }), nil
+}
+
+func (m *MemoryStore) GetActiveEnrollmentsByStudent(studentID string) ([]models.Enrollment, error) {
+	now := time.Now()
+	return m.filterEnrollments(func(e models.Enrollment) bool {
+		return e.StudentID == studentID && isActiveAt(e, now)
+	}), nil
+}
+
+func (m *MemoryStore) ListCourseEnrollments(courseID string) ([]models.Enrollment, error) {
+	enrollments := m.filterEnrollments(func(e models.Enrollment) bool { return e.CourseID == courseID })
+	sort.SliceStable(enrollments, func(i, j int) bool { return enrollments[i].StudentID < enrollments[j].StudentID })
+	return enrollments, nil
+}
+
+func (m *MemoryStore) ListEnrollmentsByStatus(status string) ([]models.Enrollment, error) {
+	enrollments := m.filterEnrollments(func(e models.Enrollment) bool { return e.Status == status })
+	sort.SliceStable(enrollments, func(i, j int) bool {
+		if !enrollments[i].EndAt.Equal(enrollments[j].EndAt) {
+			return enrollments[i].EndAt.Before(enrollments[j].EndAt)
+		}
+		if enrollments[i].StudentID != enrollments[j].StudentID {
+			return enrollments[i].StudentID < enrollments[j].StudentID
+		}
+		return enrollments[i].CourseID < enrollments[j].CourseID
+	})
+	return enrollments, nil
+}
+
+func (m *MemoryStore) UpdateEnrollment(studentID, courseID string, updates map[string]interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.enrollments[studentID][courseID]
+	if !ok {
+		return fmt.Errorf("enrollment not found")
+	}
+	for field, value := range updates {
+		var err error
+		switch field {
+		case "status":
+			err = assignColumn(&e.Status, value)
+		case "start_at":
+			err = assignColumn(&e.StartAt, value)
+		case "end_at":
+			err = assignColumn(&e.EndAt, value)
+		case "grace_until":
+			err = assignColumn(&e.GraceUntil, value)
+		case "warned_at":
+			err = assignColumn(&e.WarnedAt, value)
+		case "reclaimed_at":
+			err = assignColumn(&e.ReclaimedAt, value)
+		default:
+			err = fmt.Errorf("column %q does not exist", field)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update enrollment: %w", err)
+		}
+	}
+	m.enrollments[studentID][courseID] = e
+	return nil
+}
+
+func (m *MemoryStore) filterEnrollments(keep func(models.Enrollment) bool) []models.Enrollment {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var enrollments []models.Enrollment
+	for _, byCourse := range m.enrollments {
+		for _, e := range byCourse {
+			if keep(e) {
+				enrollments = append(enrollments, e)
+			}
+		}
+	}
+	sort.Slice(enrollments, func(i, j int) bool {
+		if !enrollments[i].StartAt.Equal(enrollments[j].StartAt) {
+			return enrollments[i].StartAt.After(enrollments[j].StartAt)
+		}
+		return enrollments[i].CourseID < enrollments[j].CourseID
+	})
+	return enrollments
+}
+
+// isActiveAt mirrors `status = 'active' AND now() BETWEEN start_at AND end_at`
+func isActiveAt(e models.Enrollment, now time.Time) bool {
+	return e.Status == "active" && !e.StartAt.After(now) && !e.EndAt.Before(now)
+}
+
+// ---- credentials ----
+
+func (m *MemoryStore) SaveCredentialToken(studentID, tokenHash string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.students[studentID]; !ok {
+		return fmt.Errorf("failed to save credential token: student %s does not exist", studentID)
+	}
+	m.credentials[studentID] = credentialToken{hash: tokenHash, expiresAt: expiresAt}
+	return nil
+}
+
+func (m *MemoryStore) ConsumeCredentialToken(studentID, tokenHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.credentials[studentID]
+	if !ok || c.redeemed || c.hash != tokenHash || !time.Now().Before(c.expiresAt) {
+		return ErrCredentialTokenInvalid
+	}
+	c.redeemed = true
+	m.credentials[studentID] = c
+	return nil
+}
+
+func (m *MemoryStore) RearmCredentialToken(studentID, tokenHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if c, ok := m.credentials[studentID]; ok && c.redeemed && c.hash == tokenHash {
+		c.redeemed = false
+		m.credentials[studentID] = c
+	}
+	return nil
+}
+
+// ---- profiles ----
+
+func (m *MemoryStore) CreateProfile(profile *models.Profile) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.profiles[profile.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrProfileExists, profile.Name)
+	}
+	now := time.Now()
+	profile.Version = 1
+	profile.CreatedAt = now
+	profile.UpdatedAt = now
+	m.profiles[profile.Name] = *profile
+	m.versions[profile.Name] = []models.ProfileVersion{snapshotProfile(*profile)}
+	return nil
+}
+
+func (m *MemoryStore) GetProfile(name string) (*models.Profile, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	p, ok := m.profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	return &p, nil
+}
+
+func (m *MemoryStore) ListProfiles() ([]models.Profile, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	profiles := make([]models.Profile, 0, len(m.profiles))
+	for _, p := range m.profiles {
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+func (m *MemoryStore) UpdateProfile(profile *models.Profile) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.profiles[profile.Name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, profile.Name)
+	}
+	if current.Version != profile.Version {
+		return fmt.Errorf("%w: %s", ErrProfileVersionConflict, profile.Name)
+	}
+	current.Description = profile.Description
+	current.Limits = profile.Limits
+	current.Version++
+	current.UpdatedAt = time.Now()
+	m.profiles[profile.Name] = current
+	m.versions[profile.Name] = append(m.versions[profile.Name], snapshotProfile(current))
+
+	profile.Version = current.Version
+	profile.UpdatedAt = current.UpdatedAt
+	return nil
+}
+
+func (m *MemoryStore) DeleteProfile(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.profiles[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	for _, c := range m.courses {
+		if c.ProfileName == name {
+			return fmt.Errorf("%w: %s", ErrProfileInUse, name)
+		}
+	}
+	for _, r := range m.baselines {
+		if r.ProfileName == name {
+			return fmt.Errorf("%w: %s", ErrProfileInUse, name)
+		}
+	}
+	for _, r := range m.aggregation {
+		if r.CapProfile == name {
+			return fmt.Errorf("%w: %s", ErrProfileInUse, name)
+		}
+	}
+	delete(m.profiles, name)
+	delete(m.versions, name) // ON DELETE CASCADE
+	return nil
+}
+
+func (m *MemoryStore) ListProfileVersions(name string) ([]models.ProfileVersion, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	history, ok := m.versions[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	versions := make([]models.ProfileVersion, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		versions = append(versions, history[i])
+	}
+	return versions, nil
+}
+
+// ---- baseline rules ----
+
+func (m *MemoryStore) SetBaselineRule(rule *models.BaselineRule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.profiles[rule.ProfileName]; !ok {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, rule.ProfileName)
+	}
+	key := baselineKey{rule.Scope, rule.Value}
+	now := time.Now()
+	if existing, ok := m.baselines[key]; ok {
+		rule.CreatedAt = existing.CreatedAt
+	} else {
+		rule.CreatedAt = now
+	}
+	rule.UpdatedAt = now
+	m.baselines[key] = *rule
+	return nil
+}
+
+func (m *MemoryStore) ListBaselineRules(scope models.BaselineScope) ([]models.BaselineRule, error) {
+	return m.filterBaselineRules(func(r models.BaselineRule) bool { return scope == "" || r.Scope == scope }), nil
+}
+
+func (m *MemoryStore) MatchBaselineRules(student *models.Student) ([]models.BaselineRule, error) {
+	return m.filterBaselineRules(func(r models.BaselineRule) bool { return r.Matches(student) }), nil
+}
+
+func (m *MemoryStore) DeleteBaselineRule(scope models.BaselineScope, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := baselineKey{scope, value}
+	if _, ok := m.baselines[key]; !ok {
+		return fmt.Errorf("%w: %s=%s", ErrBaselineRuleNotFound, scope, value)
+	}
+	delete(m.baselines, key)
+	return nil
+}
+
+func (m *MemoryStore) filterBaselineRules(keep func(models.BaselineRule) bool) []models.BaselineRule {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var rules []models.BaselineRule
+	for _, r := range m.baselines {
+		if keep(r) {
+			rules = append(rules, r)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Scope != rules[j].Scope {
+			return rules[i].Scope < rules[j].Scope
+		}
+		return rules[i].Value < rules[j].Value
+	})
+	return rules
+}
+
+// ---- aggregation rules ----
+
+func (m *MemoryStore) SetAggregationRule(rule *models.AggregationRule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if rule.CapProfile != "" {
+		if _, ok := m.profiles[rule.CapProfile]; !ok {
+			return fmt.Errorf("%w: %s", ErrProfileNotFound, rule.CapProfile)
+		}
+	}
+	key := aggregationKey{rule.Scope, rule.Value}
+	now := time.Now()
+	if existing, ok := m.aggregation[key]; ok {
+		rule.CreatedAt = existing.CreatedAt
+	} else {
+		rule.CreatedAt = now
+	}
+	rule.UpdatedAt = now
+	r := *rule
+	r.Weights = append([]float64(nil), rule.Weights...)
+	m.aggregation[key] = r
+	return nil
+}
+
+func (m *MemoryStore) ListAggregationRules() ([]models.AggregationRule, error) {
+	return m.filterAggregationRules(func(models.AggregationRule) bool { return true }), nil
+}
+
+func (m *MemoryStore) MatchAggregationRules(department string) ([]models.AggregationRule, error) {
+	return m.filterAggregationRules(func(r models.AggregationRule) bool {
+		return r.Scope == models.AggregationScopeGlobal ||
+			(r.Scope == models.AggregationScopeDepartment && r.Value == department)
+	}), nil
+}
+
+func (m *MemoryStore) DeleteAggregationRule(scope models.AggregationScope, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := aggregationKey{scope, value}
+	if _, ok := m.aggregation[key]; !ok {
+		return fmt.Errorf("%w: %s=%s", ErrAggregationRuleNotFound, scope, value)
+	}
+	delete(m.aggregation, key)
+	return nil
+}
+
+func (m *MemoryStore) filterAggregationRules(keep func(models.AggregationRule) bool) []models.AggregationRule {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var rules []models.AggregationRule
+	for _, r := range m.aggregation {
+		if keep(r) {
+			r.Weights = append([]float64(nil), r.Weights...)
+			rules = append(rules, r)
+		}
+	}
+	// ORDER BY scope DESC, value (global 먼저)
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Scope != rules[j].Scope {
+			return rules[i].Scope > rules[j].Scope
+		}
+		return rules[i].Value < rules[j].Value
+	})
+	return rules
+}
+
+// ---- helpers ----
+
+func snapshotProfile(p models.Profile) models.ProfileVersion {
+	return models.ProfileVersion{
+		Name:        p.Name,
+		Version:     p.Version,
+		Description: p.Description,
+		Limits:      p.Limits,
+		CreatedAt:   p.UpdatedAt,
+	}
+}
+
+func sortCourses(courses []models.Course) {
+	sort.Slice(courses, func(i, j int) bool {
+		if !courses[i].StartAt.Equal(courses[j].StartAt) {
+			return courses[i].StartAt.After(courses[j].StartAt)
+		}
+		return courses[i].CourseID < courses[j].CourseID
+	})
+}
+
+// copyCourse returns a copy that shares no pointers with the stored value
+func copyCourse(c models.Course) models.Course {
+	if c.Defaults != nil {
+		d := *c.Defaults
+		d.FlavorIDs = append([]string(nil), c.Defaults.FlavorIDs...)
+		c.Defaults = &d
+	}
+	return c
+}
+
+// assignColumn stores an UpdateXxx map value into dst. 값의 타입이 다르면
+// (예: *CourseDefaults → *CourseDefaults, JSONB 바이트) JSON 으로 변환해 넣는다.
+// ---- reconciliation runs ----
+
+func (m *MemoryStore) CreateReconciliationRun(run *models.ReconciliationRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextRunID++
+	run.ID = m.nextRunID
+	stored := *run
+	stored.Results = nil
+	m.runs[run.ID] = stored
+	return nil
+}
+
+func (m *MemoryStore) UpdateReconciliationRun(run *models.ReconciliationRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.runs[run.ID]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrReconciliationRunNotFound, run.ID)
+	}
+	stored := *run
+	stored.Trigger = existing.Trigger
+	stored.Scope = existing.Scope
+	stored.TotalStudents = existing.TotalStudents
+	stored.StartedAt = existing.StartedAt
+	stored.Holder = existing.Holder
+	stored.Results = nil
+	m.runs[run.ID] = stored
+	return nil
+}
+
+func (m *MemoryStore) AddReconciliationResult(runID int64, result *models.ReconciliationResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.runs[runID]; !ok {
+		return fmt.Errorf("%w: %d", ErrReconciliationRunNotFound, runID)
+	}
+	for _, r := range m.runResults[runID] {
+		if r.StudentID == result.StudentID {
+			return fmt.Errorf("failed to add reconciliation result: duplicate student %s", result.StudentID)
+		}
+	}
+	result.CreatedAt = time.Now()
+	m.runResults[runID] = append(m.runResults[runID], *result)
+	return nil
+}
+
+func (m *MemoryStore) GetReconciliationRun(id int64) (*models.ReconciliationRun, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	run, ok := m.runs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrReconciliationRunNotFound, id)
+	}
+	run.Results = append([]models.ReconciliationResult(nil), m.runResults[id]...)
+	sort.Slice(run.Results, func(i, j int) bool { return run.Results[i].StudentID < run.Results[j].StudentID })
+	return &run, nil
+}
+
+func (m *MemoryStore) ListReconciliationRuns(limit int) ([]models.ReconciliationRun, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var runs []models.ReconciliationRun
+	for _, run := range m.runs {
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].ID > runs[j].ID })
+	if len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
+
+func (m *MemoryStore) FailOrphanedReconciliationRuns(leaseName, reason string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	lease, held := m.leases[leaseName]
+	held = held && lease.ExpiresAt.After(now)
+	n := 0
+	for id, run := range m.runs {
+		if run.Status != models.ReconciliationRunning || (held && lease.Holder == run.Holder) {
+			continue
+		}
+		run.Status = models.ReconciliationFailed
+		run.Error = reason
+		run.FinishedAt = &now
+		m.runs[id] = run
+		n++
+	}
+	return n, nil
+}
+
+func assignColumn(dst any, value any) error {
+	var raw []byte
+	switch v := value.(type) {
+	case []byte:
+		raw = v
+	case json.RawMessage:
+		raw = v
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		raw = b
+	}
+	return json.Unmarshal(raw, dst)
+}
+
+// ---- reconcile jobs ----
+
+func (m *MemoryStore) EnqueueReconcileJob(job *models.ReconcileJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.enqueueLocked(job)
+	return nil
+}
+
+func (m *MemoryStore) enqueueLocked(job *models.ReconcileJob) {
+	if job.Status == "" {
+		job.Status = models.ReconcileJobPending
+	}
+	now := time.Now()
+	if job.RunAfter.IsZero() {
+		job.RunAfter = now
+	}
+	m.nextJobID++
+	job.ID = m.nextJobID
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	m.jobs[job.ID] = *job
+}
+
+func (m *MemoryStore) EnrollStudentWithJob(enrollment *models.Enrollment, job *models.ReconcileJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.enrollLocked(enrollment); err != nil {
+		return err
+	}
+	m.enqueueLocked(job)
+	return nil
+}
+
+func (m *MemoryStore) UnenrollStudentWithJob(studentID, courseID string, job *models.ReconcileJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.unenrollLocked(studentID, courseID); err != nil {
+		return err
+	}
+	m.enqueueLocked(job)
+	return nil
+}
+
+func (m *MemoryStore) UpdateCourseWithJobs(courseID string, updates map[string]interface{}, reason string) ([]models.ReconcileJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.updateCourseLocked(courseID, updates); err != nil {
+		return nil, err
+	}
+	var studentIDs []string
+	for studentID, byCourse := range m.enrollments {
+		if _, ok := byCourse[courseID]; ok {
+			studentIDs = append(studentIDs, studentID)
+		}
+	}
+	sort.Strings(studentIDs)
+
+	jobs := make([]models.ReconcileJob, 0, len(studentIDs))
+	for _, studentID := range studentIDs {
+		job := models.ReconcileJob{StudentID: studentID, CourseID: courseID, Reason: reason}
+		m.enqueueLocked(&job)
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func (m *MemoryStore) ClaimReconcileJob(lease time.Duration) (*models.ReconcileJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var next *models.ReconcileJob
+	for _, job := range m.jobs {
+		ready := (job.Status == models.ReconcileJobPending && !job.RunAfter.After(now)) ||
+			(job.Status == models.ReconcileJobRunning && job.LockedUntil != nil && job.LockedUntil.Before(now))
+		if !ready {
+			continue
+		}
+		if next == nil || job.RunAfter.Before(next.RunAfter) || (job.RunAfter.Equal(next.RunAfter) && job.ID < next.ID) {
+			j := job
+			next = &j
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+	lockedUntil := now.Add(lease)
+	next.Status = models.ReconcileJobRunning
+	next.Attempts++
+	next.LockedUntil = &lockedUntil
+	next.UpdatedAt = now
+	m.jobs[next.ID] = *next
+	return next, nil
+}
+
+func (m *MemoryStore) UpdateReconcileJob(job *models.ReconcileJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.jobs[job.ID]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrReconcileJobNotFound, job.ID)
+	}
+	stored := *job
+	stored.StudentID = existing.StudentID
+	stored.CourseID = existing.CourseID
+	stored.Reason = existing.Reason
+	stored.CreatedAt = existing.CreatedAt
+	stored.UpdatedAt = time.Now()
+	m.jobs[job.ID] = stored
+	job.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+func (m *MemoryStore) GetReconcileJob(id int64) (*models.ReconcileJob, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrReconcileJobNotFound, id)
+	}
+	return &job, nil
+}
+
+func (m *MemoryStore) ListReconcileJobs(status models.ReconcileJobStatus, limit int) ([]models.ReconcileJob, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var jobs []models.ReconcileJob
+	for _, job := range m.jobs {
+		if status == "" || job.Status == status {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID > jobs[j].ID })
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, nil
+}
+
+// ---- leader leases ----
+
+func (m *MemoryStore) AcquireLeaderLease(name, holder string, ttl time.Duration) (*models.LeaderLease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	lease, ok := m.leases[name]
+	switch {
+	case ok && lease.Holder == holder:
+	case ok && !lease.ExpiresAt.Before(now):
+		return &lease, nil
+	default:
+		lease = models.LeaderLease{Name: name, Holder: holder, AcquiredAt: now}
+	}
+	lease.RenewedAt = now
+	lease.ExpiresAt = now.Add(ttl)
+	m.leases[name] = lease
+	return &lease, nil
+}
+
+func (m *MemoryStore) ReleaseLeaderLease(name, holder string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if lease, ok := m.leases[name]; ok && lease.Holder == holder {
+		delete(m.leases, name)
+	}
+	return nil
+}
+
+func (m *MemoryStore) GetLeaderLease(name string) (*models.LeaderLease, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	lease, ok := m.leases[name]
+	if !ok {
+		return nil, nil
+	}
+	return &lease, nil
+}
@@ -0,0 +1,668 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Migration is one numbered, reversible schema change.
+// Version 은 1부터 증가하며 한 번 배포된 마이그레이션은 수정하지 않는다.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationState reports whether a migration has been applied.
+type MigrationState struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// migrationLockID is the pg_advisory_lock key that serializes migrators
+// across server replicas.
+const migrationLockID = 72_210_003
+
+// migrations is the ordered list of schema changes. 새 변경은 항상 끝에 추가한다.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "initial_schema",
+		// 기존 initSchema 와 동일 (IF NOT EXISTS 로 이미 만들어진 DB 도 그대로 채택)
+		Up: `
+		CREATE TABLE IF NOT EXISTS students (
+			student_id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			email TEXT NOT NULL,
+			department TEXT NOT NULL,
+			keystone_project_id TEXT NOT NULL,
+			keystone_user_id TEXT,
+			created_at TIMESTAMPTZ DEFAULT now()
+		);
+
+		CREATE TABLE IF NOT EXISTS courses (
+			course_id TEXT PRIMARY KEY,
+			title TEXT NOT NULL,
+			department TEXT NOT NULL,
+			semester TEXT NOT NULL,
+			start_at TIMESTAMPTZ NOT NULL,
+			end_at TIMESTAMPTZ NOT NULL,
+			quota_profile JSONB NOT NULL,
+			defaults JSONB,
+			created_at TIMESTAMPTZ DEFAULT now()
+		);
+
+		CREATE TABLE IF NOT EXISTS enrollments (
+			student_id TEXT NOT NULL REFERENCES students(student_id) ON DELETE CASCADE,
+			course_id TEXT NOT NULL REFERENCES courses(course_id) ON DELETE CASCADE,
+			status TEXT NOT NULL CHECK (status IN ('active', 'completed', 'dropped')),
+			start_at TIMESTAMPTZ NOT NULL,
+			end_at TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (student_id, course_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_enrollments_student_id ON enrollments(student_id);
+		CREATE INDEX IF NOT EXISTS idx_enrollments_course_id ON enrollments(course_id);
+		CREATE INDEX IF NOT EXISTS idx_courses_date_range ON courses(start_at, end_at);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS enrollments;
+		DROP TABLE IF EXISTS courses;
+		DROP TABLE IF EXISTS students;
+		`,
+	},
+	{
+		Version: 2,
+		Name:    "courses_updated_at",
+		// UpdateCourse 가 이미 updated_at 을 쓰고 있으나 스키마에 없던 컬럼
+		Up: `
+		ALTER TABLE courses ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
+		`,
+		Down: `
+		ALTER TABLE courses DROP COLUMN IF EXISTS updated_at;
+		`,
+	},
+	{
+		Version: 3,
+		Name:    "student_credentials",
+		// 비밀번호는 저장하지 않고 일회용 조회 토큰의 SHA-256 해시만 보관
+		Up: `
+		CREATE TABLE student_credentials (
+			student_id TEXT PRIMARY KEY REFERENCES students(student_id) ON DELETE CASCADE,
+			token_hash TEXT NOT NULL,
+			expires_at TIMESTAMPTZ NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			redeemed_at TIMESTAMPTZ
+		);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS student_credentials;
+		`,
+	},
+	{
+		Version: 4,
+		Name:    "student_bootstrap_state",
+		// 이미 프로젝트가 연결된 기존 학생은 ready, 나머지는 pending 으로 시작
+		Up: `
+		ALTER TABLE students
+			ADD COLUMN bootstrap_state TEXT NOT NULL DEFAULT 'pending'
+				CHECK (bootstrap_state IN ('pending', 'project_created', 'user_created',
+					'role_assigned', 'quota_applied', 'ready', 'failed')),
+			ADD COLUMN bootstrap_error TEXT,
+			ADD COLUMN bootstrap_attempts INTEGER NOT NULL DEFAULT 0,
+			ADD COLUMN bootstrap_updated_at TIMESTAMPTZ;
+		UPDATE students SET bootstrap_state = 'ready' WHERE keystone_project_id <> '';
+		CREATE INDEX idx_students_bootstrap_state ON students(bootstrap_state)
+			WHERE bootstrap_state <> 'ready';
+		`,
+		Down: `
+		DROP INDEX IF EXISTS idx_students_bootstrap_state;
+		ALTER TABLE students
+			DROP COLUMN bootstrap_updated_at,
+			DROP COLUMN bootstrap_attempts,
+			DROP COLUMN bootstrap_error,
+			DROP COLUMN bootstrap_state;
+		`,
+	},
+	{
+		Version: 5,
+		Name:    "student_keystone_adoption",
+		Up: `
+		ALTER TABLE students
+			ADD COLUMN keystone_project_adopted BOOLEAN NOT NULL DEFAULT false,
+			ADD COLUMN keystone_user_adopted BOOLEAN NOT NULL DEFAULT false;
+		`,
+		Down: `
+		ALTER TABLE students
+			DROP COLUMN keystone_user_adopted,
+			DROP COLUMN keystone_project_adopted;
+		`,
+	},
+	{
+		Version: 6,
+		Name:    "quota_profile_catalog",
+		// 과목에 내장돼 있던 quota_profile JSONB 는 course-<id>-<md5 앞 8자리> 이름의 카탈로그 항목으로 옮기고
+		// 과목은 profile_name 으로만 참조한다 (참조 중인 프로파일은 FK 로 삭제 불가).
+		// 정규화만 하면 "CS 101"/"cs-101" 이 같은 이름이 되므로 원래 course_id 의 해시를 붙이고,
+		// 그래도 겹치면 조용히 하나를 고르지 않고 PK 위반으로 마이그레이션을 실패시킨다.
+		Up: `
+		CREATE TABLE quota_profiles (
+			name TEXT PRIMARY KEY,
+			description TEXT NOT NULL DEFAULT '',
+			version INTEGER NOT NULL DEFAULT 1,
+			limits JSONB NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+
+		CREATE TABLE quota_profile_versions (
+			name TEXT NOT NULL REFERENCES quota_profiles(name) ON DELETE CASCADE,
+			version INTEGER NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			limits JSONB NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (name, version)
+		);
+
+		INSERT INTO quota_profiles (name, description, limits) VALUES
+			('basic', 'Baseline quota for every student project',
+				'{"instances":10,"cores":8,"ramMB":16384,"volumes":10,"gigabytes":100,"ports":10,"floatingIPs":5,"snapshots":10}'),
+			('lab', 'Lab course quota',
+				'{"instances":20,"cores":16,"ramMB":32768,"volumes":20,"gigabytes":200,"ports":20,"floatingIPs":10,"snapshots":20}');
+
+		ALTER TABLE courses ADD COLUMN profile_name TEXT REFERENCES quota_profiles(name);
+		UPDATE courses SET profile_name = 'course-' || regexp_replace(lower(course_id), '[^a-z0-9_-]', '-', 'g')
+			|| '-' || left(md5(course_id), 8);
+		INSERT INTO quota_profiles (name, description, limits)
+			SELECT profile_name, 'Migrated from course ' || course_id, quota_profile
+			FROM courses ORDER BY course_id;
+		INSERT INTO quota_profile_versions (name, version, description, limits, created_at)
+			SELECT name, version, description, limits, created_at FROM quota_profiles;
+
+		ALTER TABLE courses
+			ALTER COLUMN profile_name SET NOT NULL,
+			DROP COLUMN quota_profile;
+		CREATE INDEX idx_courses_profile_name ON courses(profile_name);
+		`,
+		Down: `
+		ALTER TABLE courses ADD COLUMN quota_profile JSONB;
+		UPDATE courses c SET quota_profile = p.limits FROM quota_profiles p WHERE p.name = c.profile_name;
+		ALTER TABLE courses
+			ALTER COLUMN quota_profile SET NOT NULL,
+			DROP COLUMN profile_name;
+		DROP TABLE IF EXISTS quota_profile_versions;
+		DROP TABLE IF EXISTS quota_profiles;
+		`,
+	},
+	{
+		Version: 7,
+		Name:    "baseline_rules",
+		// 학생 > cohort > 학과 순으로 가장 구체적인 규칙이 기본 쿼타를 정한다 (없으면 basic)
+		Up: `
+		ALTER TABLE students ADD COLUMN cohort TEXT;
+
+		CREATE TABLE baseline_rules (
+			scope TEXT NOT NULL CHECK (scope IN ('department', 'cohort', 'student')),
+			value TEXT NOT NULL,
+			profile_name TEXT NOT NULL REFERENCES quota_profiles(name),
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (scope, value)
+		);
+		CREATE INDEX idx_baseline_rules_profile_name ON baseline_rules(profile_name);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS baseline_rules;
+		ALTER TABLE students DROP COLUMN cohort;
+		`,
+	},
+	{
+		Version: 8,
+		Name:    "aggregation_rules",
+		// 과목 프로파일 합산 방식: 학과 규칙 > 전역 규칙 > sum
+		Up: `
+		CREATE TABLE aggregation_rules (
+			scope TEXT NOT NULL CHECK (scope IN ('global', 'department')),
+			value TEXT NOT NULL DEFAULT '',
+			policy TEXT NOT NULL CHECK (policy IN ('sum', 'max', 'sum_with_cap', 'weighted')),
+			cap_profile TEXT REFERENCES quota_profiles(name),
+			weights DOUBLE PRECISION[],
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (scope, value),
+			CHECK (scope <> 'global' OR value = '')
+		);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS aggregation_rules;
+		`,
+	},
+	{
+		Version: 9,
+		Name:    "student_quota_state",
+		// 사용량보다 작은 쿼타로 줄여야 할 때 over_quota_pending 과 반납 대상 자원을 남긴다
+		Up: `
+		ALTER TABLE students
+			ADD COLUMN quota_state TEXT CHECK (quota_state IN ('in_sync', 'over_quota_pending')),
+			ADD COLUMN quota_blockers JSONB;
+		`,
+		Down: `
+		ALTER TABLE students
+			DROP COLUMN quota_blockers,
+			DROP COLUMN quota_state;
+		`,
+	},
+	{
+		Version: 10,
+		Name:    "enrollment_lifecycle",
+		// 과목 종료 → completed → 유예 기간 → 경고 → 쿼타 회수
+		Up: `
+		ALTER TABLE enrollments
+			ADD COLUMN grace_until TIMESTAMPTZ,
+			ADD COLUMN warned_at TIMESTAMPTZ,
+			ADD COLUMN reclaimed_at TIMESTAMPTZ;
+		CREATE INDEX idx_enrollments_status_end_at ON enrollments(status, end_at);
+		`,
+		Down: `
+		DROP INDEX IF EXISTS idx_enrollments_status_end_at;
+		ALTER TABLE enrollments
+			DROP COLUMN reclaimed_at,
+			DROP COLUMN warned_at,
+			DROP COLUMN grace_until;
+		`,
+	},
+	{
+		Version: 11,
+		Name:    "reconciliation_runs",
+		// 대량 리콘실 실행 이력과 학생별 결과 (진행 상황은 processed 로 갱신)
+		Up: `
+		CREATE TABLE reconciliation_runs (
+			id BIGSERIAL PRIMARY KEY,
+			trigger TEXT NOT NULL,
+			status TEXT NOT NULL CHECK (status IN ('running', 'completed', 'failed')),
+			total_students INTEGER NOT NULL DEFAULT 0,
+			processed INTEGER NOT NULL DEFAULT 0,
+			success_count INTEGER NOT NULL DEFAULT 0,
+			over_quota_count INTEGER NOT NULL DEFAULT 0,
+			failed_count INTEGER NOT NULL DEFAULT 0,
+			pending_count INTEGER NOT NULL DEFAULT 0,
+			summary TEXT NOT NULL DEFAULT '',
+			error TEXT NOT NULL DEFAULT '',
+			started_at TIMESTAMPTZ NOT NULL,
+			finished_at TIMESTAMPTZ
+		);
+		CREATE INDEX idx_reconciliation_runs_status ON reconciliation_runs(status);
+
+		CREATE TABLE reconciliation_run_results (
+			run_id BIGINT NOT NULL REFERENCES reconciliation_runs(id) ON DELETE CASCADE,
+			student_id TEXT NOT NULL,
+			status TEXT NOT NULL,
+			error_message TEXT NOT NULL DEFAULT '',
+			detail JSONB NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (run_id, student_id)
+		);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS reconciliation_run_results;
+		DROP TABLE IF EXISTS reconciliation_runs;
+		`,
+	},
+	{
+		Version: 12,
+		Name:    "reconciliation_run_scope",
+		// 과목/학과/학기/학생 목록으로 범위를 좁힌 실행 (NULL = 전체)
+		Up: `
+		ALTER TABLE reconciliation_runs ADD COLUMN scope JSONB;
+		`,
+		Down: `
+		ALTER TABLE reconciliation_runs DROP COLUMN IF EXISTS scope;
+		`,
+	},
+	{
+		Version: 13,
+		Name:    "reconcile_jobs",
+		// 수강 변경과 같은 트랜잭션에 쓰는 리콘실 작업 큐 (outbox).
+		// 워커는 FOR UPDATE SKIP LOCKED 로 하나씩 가져간다.
+		Up: `
+		CREATE TABLE reconcile_jobs (
+			id BIGSERIAL PRIMARY KEY,
+			student_id TEXT NOT NULL,
+			course_id TEXT NOT NULL DEFAULT '',
+			reason TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'succeeded', 'dead')),
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			run_after TIMESTAMPTZ NOT NULL,
+			locked_until TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			finished_at TIMESTAMPTZ
+		);
+		CREATE INDEX idx_reconcile_jobs_ready ON reconcile_jobs(status, run_after);
+		CREATE INDEX idx_reconcile_jobs_student_id ON reconcile_jobs(student_id);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS reconcile_jobs;
+		`,
+	},
+	{
+		Version: 14,
+		Name:    "leader_leases",
+		// 레플리카 간 리더 선출. 행 하나가 역할 하나이고 expires_at 이
+		// 지나면 다른 holder 가 가져갈 수 있다.
+		Up: `
+		CREATE TABLE leader_leases (
+			name TEXT PRIMARY KEY,
+			holder TEXT NOT NULL,
+			acquired_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			renewed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			expires_at TIMESTAMPTZ NOT NULL
+		);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS leader_leases;
+		`,
+	},
+	{
+		Version: 15,
+		Name:    "profile_network_limits",
+		// 쿼타 모델에 networks/subnets/routers/securityGroups/securityGroupRules 가
+		// 추가됨. 값이 없으면 0 으로 적용되어 학생 프로젝트가 네트워크를 못 만들게
+		// 되므로, 기본 프로파일과 baseline 규칙이 쓰는 프로파일에 기본값을 새 버전으로
+		// 채운다. 과목 프로파일은 baseline 위에 더해지므로 0 (추가 없음) 그대로 둔다.
+		// 어느 프로파일에 어떤 키를 넣어 몇 버전을 만들었는지 quota_profile_backfills 에
+		// 남겨, Down 이 그 프로파일에서 그 키만 지우고 버전을 되돌릴 수 있게 한다.
+		Up: `
+		CREATE TABLE quota_profile_backfills (
+			migration INTEGER NOT NULL,
+			name TEXT NOT NULL REFERENCES quota_profiles(name) ON DELETE CASCADE,
+			version INTEGER NOT NULL,
+			added_keys TEXT[] NOT NULL,
+			PRIMARY KEY (migration, name)
+		);
+
+		WITH targets AS (
+			SELECT name, defaults, ARRAY(SELECT k FROM jsonb_object_keys(defaults) k WHERE NOT limits ? k) AS added_keys
+			FROM (
+				SELECT name, limits, CASE WHEN name = 'lab'
+					THEN '{"networks":4,"subnets":4,"routers":2,"securityGroups":20,"securityGroupRules":200}'::jsonb
+					ELSE '{"networks":2,"subnets":2,"routers":1,"securityGroups":10,"securityGroupRules":100}'::jsonb
+				END AS defaults
+				FROM quota_profiles
+				WHERE (name IN ('basic', 'lab') OR name IN (SELECT profile_name FROM baseline_rules))
+				  AND NOT limits ? 'networks'
+			) d
+		), updated AS (
+			UPDATE quota_profiles p SET
+				limits = t.defaults || p.limits,
+				version = p.version + 1,
+				updated_at = now()
+			FROM targets t
+			WHERE p.name = t.name
+			RETURNING p.name, p.version, p.description, p.limits, p.updated_at
+		), versions AS (
+			INSERT INTO quota_profile_versions (name, version, description, limits, created_at)
+				SELECT name, version, description, limits, updated_at FROM updated
+		)
+		INSERT INTO quota_profile_backfills (migration, name, version, added_keys)
+			SELECT 15, u.name, u.version, t.added_keys FROM updated u JOIN targets t ON t.name = u.name;
+		`,
+		// 추가한 키만 지우고, 그 뒤로 수정되지 않은 프로파일은 버전과 이력 행도 되돌린다
+		// (이후 버전이 있으면 관리자가 만든 이력이므로 남긴다).
+		Down: `
+		WITH b AS (
+			SELECT name, version, added_keys FROM quota_profile_backfills WHERE migration = 15
+		), reverted AS (
+			UPDATE quota_profiles p SET
+				limits = p.limits - b.added_keys,
+				version = CASE WHEN p.version = b.version THEN p.version - 1 ELSE p.version END,
+				updated_at = now()
+			FROM b
+			WHERE p.name = b.name
+		)
+		DELETE FROM quota_profile_versions v
+			USING b, quota_profiles p
+			WHERE v.name = b.name AND v.version = b.version
+			  AND p.name = b.name AND p.version = b.version;
+		DROP TABLE IF EXISTS quota_profile_backfills;
+		`,
+	},
+	{
+		Version: 16,
+		Name:    "profile_nova_cinder_limits",
+		// keyPairs/serverGroups/serverGroupMembers/metadataItems/backups/backupGigabytes
+		// 도 15 와 같은 이유로 기본 프로파일과 baseline 프로파일에 기본값을 채운다.
+		// volumeTypes 는 없으면 타입별 제한을 두지 않으므로 채우지 않는다.
+		Up: `
+		WITH targets AS (
+			SELECT name, defaults, ARRAY(SELECT k FROM jsonb_object_keys(defaults) k WHERE NOT limits ? k) AS added_keys
+			FROM (
+				SELECT name, limits, CASE WHEN name = 'lab'
+					THEN '{"keyPairs":20,"serverGroups":10,"serverGroupMembers":20,"metadataItems":128,"backups":20,"backupGigabytes":200}'::jsonb
+					ELSE '{"keyPairs":10,"serverGroups":5,"serverGroupMembers":10,"metadataItems":128,"backups":10,"backupGigabytes":100}'::jsonb
+				END AS defaults
+				FROM quota_profiles
+				WHERE (name IN ('basic', 'lab') OR name IN (SELECT profile_name FROM baseline_rules))
+				  AND NOT limits ? 'keyPairs'
+			) d
+		), updated AS (
+			UPDATE quota_profiles p SET
+				limits = t.defaults || p.limits,
+				version = p.version + 1,
+				updated_at = now()
+			FROM targets t
+			WHERE p.name = t.name
+			RETURNING p.name, p.version, p.description, p.limits, p.updated_at
+		), versions AS (
+			INSERT INTO quota_profile_versions (name, version, description, limits, created_at)
+				SELECT name, version, description, limits, updated_at FROM updated
+		)
+		INSERT INTO quota_profile_backfills (migration, name, version, added_keys)
+			SELECT 16, u.name, u.version, t.added_keys FROM updated u JOIN targets t ON t.name = u.name;
+		`,
+		// 15 의 Down 과 같다: 16 이 넣은 키만, 16 이 건드린 프로파일에서만 되돌린다.
+		Down: `
+		WITH b AS (
+			SELECT name, version, added_keys FROM quota_profile_backfills WHERE migration = 16
+		), reverted AS (
+			UPDATE quota_profiles p SET
+				limits = p.limits - b.added_keys,
+				version = CASE WHEN p.version = b.version THEN p.version - 1 ELSE p.version END,
+				updated_at = now()
+			FROM b
+			WHERE p.name = b.name
+		)
+		DELETE FROM quota_profile_versions v
+			USING b, quota_profiles p
+			WHERE v.name = b.name AND v.version = b.version
+			  AND p.name = b.name AND p.version = b.version;
+		DELETE FROM quota_profile_backfills WHERE migration = 16;
+		`,
+	},
+	{
+		Version: 17,
+		Name:    "student_bootstrap_saga_id",
+		// Keystone 생성 직전에 기록하는 saga 식별자(의도). 만든 객체에도 같은 표식을 남겨
+		// 생성 직후 죽었다가 재개될 때 "채택"이 아니라 "생성"으로 판단해 보상 시 지운다.
+		Up: `
+		ALTER TABLE students ADD COLUMN bootstrap_saga_id TEXT;
+		`,
+		Down: `
+		ALTER TABLE students DROP COLUMN IF EXISTS bootstrap_saga_id;
+		`,
+	},
+	{
+		Version: 18,
+		Name:    "reconciliation_run_holder",
+		// 실행을 맡은 프로세스(bulk lease holder). 그 holder 가 lease 를 잃은 running
+		// 실행만 중단된 것으로 본다 (기존 실행은 빈 값 → 중단으로 처리).
+		Up: `
+		ALTER TABLE reconciliation_runs ADD COLUMN holder TEXT NOT NULL DEFAULT '';
+		`,
+		Down: `
+		ALTER TABLE reconciliation_runs DROP COLUMN IF EXISTS holder;
+		`,
+	},
+}
+
+// Migrations returns the registered migrations in version order.
+func Migrations() []Migration {
+	out := append([]Migration(nil), migrations...)
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out
+}
+
+// MigrateUp applies every pending migration and returns the ones applied.
+func (d *Database) MigrateUp() ([]Migration, error) {
+	var applied []Migration
+	err := d.withMigrationLock(func(conn *sql.Conn) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range Migrations() {
+			if _, ok := done[m.Version]; ok {
+				continue
+			}
+			if err := runMigration(conn, m, true); err != nil {
+				return err
+			}
+			applied = append(applied, m)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// MigrateDown reverts the most recent `steps` applied migrations.
+func (d *Database) MigrateDown(steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := d.withMigrationLock(func(conn *sql.Conn) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		all := Migrations()
+		for i := len(all) - 1; i >= 0 && len(reverted) < steps; i-- {
+			m := all[i]
+			if _, ok := done[m.Version]; !ok {
+				continue
+			}
+			if err := runMigration(conn, m, false); err != nil {
+				return err
+			}
+			reverted = append(reverted, m)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// MigrationStatus lists every known migration with its applied state.
+func (d *Database) MigrationStatus() ([]MigrationState, error) {
+	var states []MigrationState
+	err := d.withMigrationLock(func(conn *sql.Conn) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range Migrations() {
+			st := MigrationState{Version: m.Version, Name: m.Name}
+			if at, ok := done[m.Version]; ok {
+				at := at
+				st.Applied = true
+				st.AppliedAt = &at
+			}
+			states = append(states, st)
+		}
+		return nil
+	})
+	return states, err
+}
+
+// withMigrationLock runs fn on a dedicated connection holding the
+// migration advisory lock, creating schema_migrations if needed.
+func (d *Database) withMigrationLock(fn func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+func appliedVersions(conn *sql.Conn) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(context.Background(), `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	done := map[int]time.Time{}
+	for rows.Next() {
+		var v int
+		var at time.Time
+		if err := rows.Scan(&v, &at); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		done[v] = at
+	}
+	return done, rows.Err()
+}
+
+// runMigration applies (up=true) or reverts one migration in a transaction
+// together with its schema_migrations bookkeeping.
+func runMigration(conn *sql.Conn, m Migration, up bool) error {
+	ctx := context.Background()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migration %d: begin: %w", m.Version, err)
+	}
+	defer tx.Rollback()
+
+	if up {
+		if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+			return fmt.Errorf("migration %d (%s) up: %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.Version, m.Name); err != nil {
+			return fmt.Errorf("migration %d: record: %w", m.Version, err)
+		}
+	} else {
+		if _, err := tx.ExecContext(ctx, m.Down); err != nil {
+			return fmt.Errorf("migration %d (%s) down: %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM schema_migrations WHERE version = $1`, m.Version); err != nil {
+			return fmt.Errorf("migration %d: unrecord: %w", m.Version, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("migration %d: commit: %w", m.Version, err)
+	}
+	return nil
+}
@@ -3,6 +3,10 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"net/url"
+	"strconv"
+
+	"example.com/quotaapi/internal/config"
 
 	_ "github.com/lib/pq"
 )
@@ -11,20 +15,46 @@ type Database struct {
 	db *sql.DB
 }
 
-func NewDatabase(connStr string) (*Database, error) {
+// NewDatabase connects to PostgreSQL and applies pending schema migrations
+func NewDatabase(cfg config.DatabaseConfig) (*Database, error) {
+	d, err := Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := d.MigrateUp(); err != nil {
+		d.Close()
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
+	}
+
+	return d, nil
+}
+
+// Open connects to PostgreSQL without touching the schema.
+// `migrate` 서브커맨드처럼 마이그레이션을 직접 제어할 때 사용한다.
+func Open(cfg config.DatabaseConfig) (*Database, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid database config: %w", err)
+	}
+	connStr, err := connString(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	if err := initSchema(db); err != nil {
-		return nil, fmt.Errorf("failed to init schema: %w", err)
-	}
-
 	return &Database{db: db}, nil
 }
 
@@ -36,48 +66,18 @@ func (d *Database) DB() *sql.DB {
 	return d.db
 }
 
-func initSchema(db *sql.DB) error {
-	schema := `
-	-- students 테이블
-	CREATE TABLE IF NOT EXISTS students (
-		student_id TEXT PRIMARY KEY,
-		name TEXT NOT NULL,
-		email TEXT NOT NULL,
-		department TEXT NOT NULL,
-		keystone_project_id TEXT NOT NULL,
-		keystone_user_id TEXT,
-		created_at TIMESTAMPTZ DEFAULT now()
-	);
-
-	-- courses 테이블
-	CREATE TABLE IF NOT EXISTS courses (
-		course_id TEXT PRIMARY KEY,
-		title TEXT NOT NULL,
-		department TEXT NOT NULL,
-		semester TEXT NOT NULL,
-		start_at TIMESTAMPTZ NOT NULL,
-		end_at TIMESTAMPTZ NOT NULL,
-		quota_profile JSONB NOT NULL,
-		defaults JSONB,
-		created_at TIMESTAMPTZ DEFAULT now()
-	);
-
-	-- enrollments 테이블
-	CREATE TABLE IF NOT EXISTS enrollments (
-		student_id TEXT NOT NULL REFERENCES students(student_id) ON DELETE CASCADE,
-		course_id TEXT NOT NULL REFERENCES courses(course_id) ON DELETE CASCADE,
-		status TEXT NOT NULL CHECK (status IN ('active', 'completed', 'dropped')),
-		start_at TIMESTAMPTZ NOT NULL,
-		end_at TIMESTAMPTZ NOT NULL,
-		PRIMARY KEY (student_id, course_id)
-	);
-
-	-- 인덱스 생성
-	CREATE INDEX IF NOT EXISTS idx_enrollments_student_id ON enrollments(student_id);
-	CREATE INDEX IF NOT EXISTS idx_enrollments_course_id ON enrollments(course_id);
-	CREATE INDEX IF NOT EXISTS idx_courses_date_range ON courses(start_at, end_at);
-	`
-
-	_, err := db.Exec(schema)
-	return err
+// connString adds session runtime parameters to the configured DSN.
+// lib/pq 는 알 수 없는 URL 파라미터를 접속 시 SET 으로 넘기므로
+// statement_timeout(ms) 이 모든 커넥션에 적용된다.
+func connString(cfg config.DatabaseConfig) (string, error) {
+	u, err := url.Parse(cfg.DSN())
+	if err != nil {
+		return "", fmt.Errorf("invalid database DSN: %w", err)
+	}
+	if cfg.StatementTimeout > 0 {
+		q := u.Query()
+		q.Set("statement_timeout", strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10))
+		u.RawQuery = q.Encode()
+	}
+	return u.String(), nil
 }
@@ -0,0 +1,238 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"example.com/quotaapi/internal/models"
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrProfileNotFound is returned when no catalog entry has the name.
+	ErrProfileNotFound = errors.New("quota profile not found")
+	// ErrProfileExists is returned when creating a profile whose name is taken.
+	ErrProfileExists = errors.New("quota profile already exists")
+	// ErrProfileVersionConflict is returned when the profile was modified
+	// after the version the caller based its update on.
+	ErrProfileVersionConflict = errors.New("quota profile was modified concurrently")
+	// ErrProfileInUse is returned when deleting a profile still referenced
+	// by a course, a baseline rule or an aggregation cap.
+	ErrProfileInUse = errors.New("quota profile is referenced by a course or rule")
+)
+
+// pq error codes used to translate constraint violations
+const (
+	pqForeignKeyViolation = "23503"
+	pqUniqueViolation     = "23505"
+)
+
+func isPQError(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
+
+// CreateProfile inserts a new profile at version 1 and records its first
+// version snapshot.
+func (db *Database) CreateProfile(profile *models.Profile) error {
+	limitsJSON, err := json.Marshal(profile.Limits)
+	if err != nil {
+		return fmt.Errorf("failed to marshal profile limits: %w", err)
+	}
+
+	tx, err := db.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	_, err = tx.Exec(`
+		INSERT INTO quota_profiles (name, description, version, limits, created_at, updated_at)
+		VALUES ($1, $2, 1, $3, $4, $4)
+	`, profile.Name, profile.Description, limitsJSON, now)
+	if err != nil {
+		if isPQError(err, pqUniqueViolation) {
+			return fmt.Errorf("%w: %s", ErrProfileExists, profile.Name)
+		}
+		return fmt.Errorf("failed to create profile: %w", err)
+	}
+	if err := insertProfileVersion(tx, profile.Name, 1, profile.Description, limitsJSON, now); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit profile: %w", err)
+	}
+
+	profile.Version = 1
+	profile.CreatedAt = now
+	profile.UpdatedAt = now
+	return nil
+}
+
+// GetProfile retrieves the current version of a profile by name
+func (db *Database) GetProfile(name string) (*models.Profile, error) {
+	row := db.db.QueryRow(`
+		SELECT name, description, version, limits, created_at, updated_at
+		FROM quota_profiles WHERE name = $1
+	`, name)
+
+	var p models.Profile
+	var limitsJSON []byte
+	err := row.Scan(&p.Name, &p.Description, &p.Version, &limitsJSON, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+		}
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+	if err := json.Unmarshal(limitsJSON, &p.Limits); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal profile limits: %w", err)
+	}
+	return &p, nil
+}
+
+// ListProfiles retrieves every profile ordered by name
+func (db *Database) ListProfiles() ([]models.Profile, error) {
+	rows, err := db.db.Query(`
+		SELECT name, description, version, limits, created_at, updated_at
+		FROM quota_profiles ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []models.Profile
+	for rows.Next() {
+		var p models.Profile
+		var limitsJSON []byte
+		if err := rows.Scan(&p.Name, &p.Description, &p.Version, &limitsJSON, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan profile: %w", err)
+		}
+		if err := json.Unmarshal(limitsJSON, &p.Limits); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal profile limits: %w", err)
+		}
+		profiles = append(profiles, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+// UpdateProfile replaces description and limits if the stored version is
+// still profile.Version, then bumps the version and snapshots it. 성공 시
+// profile.Version/UpdatedAt 을 새 값으로 갱신한다.
+func (db *Database) UpdateProfile(profile *models.Profile) error {
+	limitsJSON, err := json.Marshal(profile.Limits)
+	if err != nil {
+		return fmt.Errorf("failed to marshal profile limits: %w", err)
+	}
+
+	tx, err := db.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	next := profile.Version + 1
+	result, err := tx.Exec(`
+		UPDATE quota_profiles SET description = $1, limits = $2, version = $3, updated_at = $4
+		WHERE name = $5 AND version = $6
+	`, profile.Description, limitsJSON, next, now, profile.Name, profile.Version)
+	if err != nil {
+		return fmt.Errorf("failed to update profile: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		var exists bool
+		if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM quota_profiles WHERE name = $1)`, profile.Name).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check profile: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrProfileNotFound, profile.Name)
+		}
+		return fmt.Errorf("%w: %s", ErrProfileVersionConflict, profile.Name)
+	}
+	if err := insertProfileVersion(tx, profile.Name, next, profile.Description, limitsJSON, now); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit profile: %w", err)
+	}
+
+	profile.Version = next
+	profile.UpdatedAt = now
+	return nil
+}
+
+// DeleteProfile deletes a profile and its version history.
+// 과목이나 기본 쿼타/합산 규칙이 참조 중이면 ErrProfileInUse.
+func (db *Database) DeleteProfile(name string) error {
+	result, err := db.db.Exec(`DELETE FROM quota_profiles WHERE name = $1`, name)
+	if err != nil {
+		if isPQError(err, pqForeignKeyViolation) {
+			return fmt.Errorf("%w: %s", ErrProfileInUse, name)
+		}
+		return fmt.Errorf("failed to delete profile: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	return nil
+}
+
+// ListProfileVersions retrieves the version history of a profile, newest first
+func (db *Database) ListProfileVersions(name string) ([]models.ProfileVersion, error) {
+	rows, err := db.db.Query(`
+		SELECT name, version, description, limits, created_at
+		FROM quota_profile_versions WHERE name = $1 ORDER BY version DESC
+	`, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profile versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []models.ProfileVersion
+	for rows.Next() {
+		var v models.ProfileVersion
+		var limitsJSON []byte
+		if err := rows.Scan(&v.Name, &v.Version, &v.Description, &limitsJSON, &v.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan profile version: %w", err)
+		}
+		if err := json.Unmarshal(limitsJSON, &v.Limits); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal profile limits: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating profile versions: %w", err)
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	return versions, nil
+}
+
+func insertProfileVersion(tx *sql.Tx, name string, version int, description string, limitsJSON []byte, at time.Time) error {
+	_, err := tx.Exec(`
+		INSERT INTO quota_profile_versions (name, version, description, limits, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, name, version, description, limitsJSON, at)
+	if err != nil {
+		return fmt.Errorf("failed to record profile version: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"example.com/quotaapi/internal/models"
+)
+
+// ErrReconcileJobNotFound is returned when no job has the ID.
+var ErrReconcileJobNotFound = errors.New("reconcile job not found")
+
+const reconcileJobColumns = `id, student_id, course_id, reason, status, attempts,
+	last_error, run_after, locked_until, created_at, updated_at, finished_at`
+
+func scanReconcileJob(row rowScanner, job *models.ReconcileJob) error {
+	var lockedUntil, finishedAt sql.NullTime
+	err := row.Scan(&job.ID, &job.StudentID, &job.CourseID, &job.Reason, &job.Status, &job.Attempts,
+		&job.LastError, &job.RunAfter, &lockedUntil, &job.CreatedAt, &job.UpdatedAt, &finishedAt)
+	if err != nil {
+		return err
+	}
+	job.LockedUntil = nullTimePtr(lockedUntil)
+	job.FinishedAt = nullTimePtr(finishedAt)
+	return nil
+}
+
+// insertReconcileJob enqueues job on db or inside a transaction and sets
+// its ID and timestamps. 상태와 실행 시각이 비어 있으면 pending, 지금.
+func insertReconcileJob(q interface {
+	QueryRow(query string, args ...any) *sql.Row
+}, job *models.ReconcileJob) error {
+	if job.Status == "" {
+		job.Status = models.ReconcileJobPending
+	}
+	if job.RunAfter.IsZero() {
+		job.RunAfter = time.Now()
+	}
+	query := `
+		INSERT INTO reconcile_jobs (student_id, course_id, reason, status, run_after)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+	err := q.QueryRow(query, job.StudentID, job.CourseID, job.Reason, job.Status, job.RunAfter).
+		Scan(&job.ID, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue reconcile job: %w", err)
+	}
+	return nil
+}
+
+// EnqueueReconcileJob adds a job to the queue
+func (db *Database) EnqueueReconcileJob(job *models.ReconcileJob) error {
+	return insertReconcileJob(db.db, job)
+}
+
+// EnrollStudentWithJob enrolls the student and enqueues job in one
+// transaction, so the quota change cannot be lost after the enrollment commits.
+func (db *Database) EnrollStudentWithJob(enrollment *models.Enrollment, job *models.ReconcileJob) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := enrollStudent(tx, enrollment); err != nil {
+		return err
+	}
+	if err := insertReconcileJob(tx, job); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit enrollment: %w", err)
+	}
+	return nil
+}
+
+// UnenrollStudentWithJob removes the enrollment and enqueues job in one
+// transaction.
+func (db *Database) UnenrollStudentWithJob(studentID, courseID string, job *models.ReconcileJob) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := unenrollStudent(tx, studentID, courseID); err != nil {
+		return err
+	}
+	if err := insertReconcileJob(tx, job); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit unenrollment: %w", err)
+	}
+	return nil
+}
+
+// UpdateCourseWithJobs updates the course and enqueues one job with reason
+// for every student enrolled in it, in one transaction. 과목 프로파일이 바뀌면
+// 수강생 쿼타가 모두 달라지므로 변경과 함께 작업을 남긴다.
+func (db *Database) UpdateCourseWithJobs(courseID string, updates map[string]interface{}, reason string) ([]models.ReconcileJob, error) {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := updateCourse(tx, courseID, updates); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(`SELECT DISTINCT student_id FROM enrollments WHERE course_id = $1 ORDER BY student_id`, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list course students: %w", err)
+	}
+	var studentIDs []string
+	for rows.Next() {
+		var studentID string
+		if err := rows.Scan(&studentID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan course student: %w", err)
+		}
+		studentIDs = append(studentIDs, studentID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list course students: %w", err)
+	}
+
+	jobs := make([]models.ReconcileJob, 0, len(studentIDs))
+	for _, studentID := range studentIDs {
+		job := models.ReconcileJob{StudentID: studentID, CourseID: courseID, Reason: reason}
+		if err := insertReconcileJob(tx, &job); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit course update: %w", err)
+	}
+	return jobs, nil
+}
+
+// ClaimReconcileJob leases the oldest runnable job for lease and counts the
+// attempt. pending 이면서 run_after 가 지난 작업, 또는 lease 가 끝난 running
+// 작업(워커 중단)이 대상이며, SKIP LOCKED 로 워커끼리 같은 작업을 가져가지
+// 않는다. 가져갈 작업이 없으면 nil, nil.
+func (db *Database) ClaimReconcileJob(lease time.Duration) (*models.ReconcileJob, error) {
+	query := `
+		UPDATE reconcile_jobs SET
+			status = 'running',
+			attempts = attempts + 1,
+			locked_until = now() + $1 * interval '1 millisecond',
+			updated_at = now()
+		WHERE id = (
+			SELECT id FROM reconcile_jobs
+			WHERE (status = 'pending' AND run_after <= now())
+			   OR (status = 'running' AND locked_until < now())
+			ORDER BY run_after, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING ` + reconcileJobColumns
+	job := &models.ReconcileJob{}
+	if err := scanReconcileJob(db.db.QueryRow(query, lease.Milliseconds()), job); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim reconcile job: %w", err)
+	}
+	return job, nil
+}
+
+// UpdateReconcileJob stores the job's status, attempts, error and schedule
+func (db *Database) UpdateReconcileJob(job *models.ReconcileJob) error {
+	query := `
+		UPDATE reconcile_jobs SET
+			status = $1, attempts = $2, last_error = $3, run_after = $4,
+			locked_until = $5, finished_at = $6, updated_at = now()
+		WHERE id = $7
+		RETURNING updated_at
+	`
+	err := db.db.QueryRow(query, job.Status, job.Attempts, job.LastError, job.RunAfter,
+		job.LockedUntil, job.FinishedAt, job.ID).Scan(&job.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: %d", ErrReconcileJobNotFound, job.ID)
+		}
+		return fmt.Errorf("failed to update reconcile job: %w", err)
+	}
+	return nil
+}
+
+// GetReconcileJob retrieves a job by ID
+func (db *Database) GetReconcileJob(id int64) (*models.ReconcileJob, error) {
+	job := &models.ReconcileJob{}
+	query := "SELECT " + reconcileJobColumns + " FROM reconcile_jobs WHERE id = $1"
+	if err := scanReconcileJob(db.db.QueryRow(query, id), job); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %d", ErrReconcileJobNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get reconcile job: %w", err)
+	}
+	return job, nil
+}
+
+// ListReconcileJobs retrieves the latest jobs, newest first, optionally
+// filtered by status ("" = 전체)
+func (db *Database) ListReconcileJobs(status models.ReconcileJobStatus, limit int) ([]models.ReconcileJob, error) {
+	query := "SELECT " + reconcileJobColumns + " FROM reconcile_jobs"
+	args := []any{}
+	if status != "" {
+		query += " WHERE status = $1"
+		args = append(args, status)
+	}
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args)+1)
+	args = append(args, limit)
+
+	rows, err := db.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reconcile jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []models.ReconcileJob
+	for rows.Next() {
+		var job models.ReconcileJob
+		if err := scanReconcileJob(rows, &job); err != nil {
+			return nil, fmt.Errorf("failed to scan reconcile job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reconcile jobs: %w", err)
+	}
+	return jobs, nil
+}
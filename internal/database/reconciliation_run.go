@@ -0,0 +1,177 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"example.com/quotaapi/internal/models"
+)
+
+// ErrReconciliationRunNotFound is returned when no run has the ID.
+var ErrReconciliationRunNotFound = errors.New("reconciliation run not found")
+
+const reconciliationRunColumns = `id, trigger, status, total_students, processed,
+	success_count, over_quota_count, failed_count, pending_count,
+	summary, error, started_at, finished_at, scope, holder`
+
+func scanReconciliationRun(row rowScanner, run *models.ReconciliationRun) error {
+	var finishedAt sql.NullTime
+	var scope []byte
+	err := row.Scan(&run.ID, &run.Trigger, &run.Status, &run.TotalStudents, &run.Processed,
+		&run.SuccessCount, &run.OverQuotaCount, &run.FailedCount, &run.PendingCount,
+		&run.Summary, &run.Error, &run.StartedAt, &finishedAt, &scope, &run.Holder)
+	if err != nil {
+		return err
+	}
+	run.FinishedAt = nullTimePtr(finishedAt)
+	if scope != nil {
+		run.Scope = &models.ReconciliationScope{}
+		if err := json.Unmarshal(scope, run.Scope); err != nil {
+			return fmt.Errorf("failed to unmarshal run scope: %w", err)
+		}
+	}
+	return nil
+}
+
+// CreateReconciliationRun inserts a run and sets run.ID
+func (db *Database) CreateReconciliationRun(run *models.ReconciliationRun) error {
+	query := `
+		INSERT INTO reconciliation_runs (trigger, status, total_students, started_at, scope, holder)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	var scope []byte
+	if run.Scope != nil {
+		var err error
+		if scope, err = json.Marshal(run.Scope); err != nil {
+			return fmt.Errorf("failed to marshal run scope: %w", err)
+		}
+	}
+	if err := db.db.QueryRow(query, run.Trigger, run.Status, run.TotalStudents, run.StartedAt, scope, run.Holder).Scan(&run.ID); err != nil {
+		return fmt.Errorf("failed to create reconciliation run: %w", err)
+	}
+	return nil
+}
+
+// UpdateReconciliationRun stores the run's progress counters and status
+func (db *Database) UpdateReconciliationRun(run *models.ReconciliationRun) error {
+	query := `
+		UPDATE reconciliation_runs SET
+			status = $1, processed = $2, success_count = $3, over_quota_count = $4,
+			failed_count = $5, pending_count = $6, summary = $7, error = $8, finished_at = $9
+		WHERE id = $10
+	`
+	result, err := db.db.Exec(query, run.Status, run.Processed, run.SuccessCount, run.OverQuotaCount,
+		run.FailedCount, run.PendingCount, run.Summary, run.Error, run.FinishedAt, run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update reconciliation run: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %d", ErrReconciliationRunNotFound, run.ID)
+	}
+	return nil
+}
+
+// AddReconciliationResult records one student's outcome of a run
+func (db *Database) AddReconciliationResult(runID int64, result *models.ReconciliationResult) error {
+	query := `
+		INSERT INTO reconciliation_run_results (run_id, student_id, status, error_message, detail)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`
+	err := db.db.QueryRow(query, runID, result.StudentID, result.Status, result.ErrorMessage, []byte(result.Detail)).
+		Scan(&result.CreatedAt)
+	if err != nil {
+		if isPQError(err, pqForeignKeyViolation) {
+			return fmt.Errorf("%w: %d", ErrReconciliationRunNotFound, runID)
+		}
+		return fmt.Errorf("failed to add reconciliation result: %w", err)
+	}
+	return nil
+}
+
+// GetReconciliationRun retrieves a run together with its per-student results
+func (db *Database) GetReconciliationRun(id int64) (*models.ReconciliationRun, error) {
+	run := &models.ReconciliationRun{}
+	query := "SELECT " + reconciliationRunColumns + " FROM reconciliation_runs WHERE id = $1"
+	if err := scanReconciliationRun(db.db.QueryRow(query, id), run); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %d", ErrReconciliationRunNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get reconciliation run: %w", err)
+	}
+
+	rows, err := db.db.Query(`
+		SELECT student_id, status, error_message, detail, created_at
+		FROM reconciliation_run_results WHERE run_id = $1 ORDER BY student_id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reconciliation results: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r models.ReconciliationResult
+		var detail []byte
+		if err := rows.Scan(&r.StudentID, &r.Status, &r.ErrorMessage, &detail, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reconciliation result: %w", err)
+		}
+		r.Detail = detail
+		run.Results = append(run.Results, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reconciliation results: %w", err)
+	}
+	return run, nil
+}
+
+// ListReconciliationRuns retrieves the latest runs, newest first, without results
+func (db *Database) ListReconciliationRuns(limit int) ([]models.ReconciliationRun, error) {
+	query := "SELECT " + reconciliationRunColumns + " FROM reconciliation_runs ORDER BY id DESC LIMIT $1"
+	rows, err := db.db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reconciliation runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []models.ReconciliationRun
+	for rows.Next() {
+		var run models.ReconciliationRun
+		if err := scanReconciliationRun(rows, &run); err != nil {
+			return nil, fmt.Errorf("failed to scan reconciliation run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reconciliation runs: %w", err)
+	}
+	return runs, nil
+}
+
+// FailOrphanedReconciliationRuns marks running runs as failed when their
+// holder no longer holds the unexpired lease leaseName (프로세스가 죽어 lease
+// 가 끝난 실행). 다른 레플리카에서 진행 중인 실행은 건드리지 않는다.
+func (db *Database) FailOrphanedReconciliationRuns(leaseName, reason string) (int, error) {
+	result, err := db.db.Exec(`
+		UPDATE reconciliation_runs r SET status = $1, error = $2, finished_at = $3
+		WHERE r.status = $4 AND NOT EXISTS (
+			SELECT 1 FROM leader_leases l
+			WHERE l.name = $5 AND l.holder = r.holder AND l.expires_at > now()
+		)
+	`, models.ReconciliationFailed, reason, time.Now(), models.ReconciliationRunning, leaseName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail running reconciliation runs: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(n), nil
+}
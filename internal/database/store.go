@@ -0,0 +1,124 @@
+package database
+
+import (
+	"time"
+
+	"example.com/quotaapi/internal/models"
+)
+
+// StudentStore persists students.
+type StudentStore interface {
+	CreateStudent(student *models.Student) error
+	GetStudent(studentID string) (*models.Student, error)
+	UpdateStudent(studentID string, updates map[string]interface{}) error
+	DeleteStudent(studentID string) error
+	ListStudents(department string) ([]models.Student, error)
+	GetAllStudents() ([]*models.Student, error)
+	ListStudentsByBootstrapState(states ...models.BootstrapState) ([]models.Student, error)
+}
+
+// CourseStore persists courses.
+type CourseStore interface {
+	CreateCourse(course *models.Course) error
+	GetCourse(courseID string) (*models.Course, error)
+	UpdateCourse(courseID string, updates map[string]interface{}) error
+	DeleteCourse(courseID string) error
+	ListCourses(department, semester string) ([]models.Course, error)
+	GetActiveCourses() ([]models.Course, error)
+}
+
+// EnrollmentStore persists student ↔ course enrollments.
+type EnrollmentStore interface {
+	EnrollStudent(enrollment *models.Enrollment) error
+	UnenrollStudent(studentID, courseID string) error
+	GetStudentEnrollments(studentID string) ([]models.Enrollment, error)
+	GetActiveEnrollments() ([]models.Enrollment, error)
+	GetActiveEnrollmentsByStudent(studentID string) ([]models.Enrollment, error)
+	ListCourseEnrollments(courseID string) ([]models.Enrollment, error)
+	ListEnrollmentsByStatus(status string) ([]models.Enrollment, error)
+	UpdateEnrollment(studentID, courseID string, updates map[string]interface{}) error
+}
+
+// CredentialStore persists hashed one-time credential retrieval tokens.
+type CredentialStore interface {
+	SaveCredentialToken(studentID, tokenHash string, expiresAt time.Time) error
+	ConsumeCredentialToken(studentID, tokenHash string) error
+	RearmCredentialToken(studentID, tokenHash string) error
+}
+
+// ProfileStore persists the versioned quota profile catalog.
+type ProfileStore interface {
+	CreateProfile(profile *models.Profile) error
+	GetProfile(name string) (*models.Profile, error)
+	ListProfiles() ([]models.Profile, error)
+	UpdateProfile(profile *models.Profile) error
+	DeleteProfile(name string) error
+	ListProfileVersions(name string) ([]models.ProfileVersion, error)
+}
+
+// BaselineStore persists per-department/cohort/student baseline rules.
+type BaselineStore interface {
+	SetBaselineRule(rule *models.BaselineRule) error
+	ListBaselineRules(scope models.BaselineScope) ([]models.BaselineRule, error)
+	MatchBaselineRules(student *models.Student) ([]models.BaselineRule, error)
+	DeleteBaselineRule(scope models.BaselineScope, value string) error
+}
+
+// AggregationStore persists global/per-department aggregation policy rules.
+type AggregationStore interface {
+	SetAggregationRule(rule *models.AggregationRule) error
+	ListAggregationRules() ([]models.AggregationRule, error)
+	MatchAggregationRules(department string) ([]models.AggregationRule, error)
+	DeleteAggregationRule(scope models.AggregationScope, value string) error
+}
+
+// ReconciliationRunStore persists bulk reconciliation runs and their
+// per-student results.
+type ReconciliationRunStore interface {
+	CreateReconciliationRun(run *models.ReconciliationRun) error
+	UpdateReconciliationRun(run *models.ReconciliationRun) error
+	AddReconciliationResult(runID int64, result *models.ReconciliationResult) error
+	GetReconciliationRun(id int64) (*models.ReconciliationRun, error)
+	ListReconciliationRuns(limit int) ([]models.ReconciliationRun, error)
+	FailOrphanedReconciliationRuns(leaseName, reason string) (int, error)
+}
+
+// ReconcileJobStore persists the durable reconciliation job queue. *WithJob
+// 메서드는 수강 변경과 작업 등록을 한 트랜잭션으로 처리한다 (outbox).
+type ReconcileJobStore interface {
+	EnqueueReconcileJob(job *models.ReconcileJob) error
+	EnrollStudentWithJob(enrollment *models.Enrollment, job *models.ReconcileJob) error
+	UnenrollStudentWithJob(studentID, courseID string, job *models.ReconcileJob) error
+	UpdateCourseWithJobs(courseID string, updates map[string]interface{}, reason string) ([]models.ReconcileJob, error)
+	ClaimReconcileJob(lease time.Duration) (*models.ReconcileJob, error)
+	UpdateReconcileJob(job *models.ReconcileJob) error
+	GetReconcileJob(id int64) (*models.ReconcileJob, error)
+	ListReconcileJobs(status models.ReconcileJobStatus, limit int) ([]models.ReconcileJob, error)
+}
+
+// LeaderStore persists leader election leases shared by server replicas.
+type LeaderStore interface {
+	AcquireLeaderLease(name, holder string, ttl time.Duration) (*models.LeaderLease, error)
+	ReleaseLeaderLease(name, holder string) error
+	GetLeaderLease(name string) (*models.LeaderLease, error)
+}
+
+// Store is the full storage surface used by handlers and services.
+// *Database (PostgreSQL) 와 *MemoryStore (데모/테스트용) 가 구현한다.
+type Store interface {
+	StudentStore
+	CourseStore
+	EnrollmentStore
+	CredentialStore
+	ProfileStore
+	BaselineStore
+	AggregationStore
+	ReconciliationRunStore
+	ReconcileJobStore
+	LeaderStore
+}
+
+var (
+	_ Store = (*Database)(nil)
+	_ Store = (*MemoryStore)(nil)
+)
@@ -0,0 +1,340 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"example.com/quotaapi/internal/config"
+	"example.com/quotaapi/internal/models"
+)
+
+// storeImpls returns every Store to run the conformance tests against.
+// *Database 는 TEST_DATABASE_URL (비워도 되는 스크래치 DB) 이 있을 때만 포함한다.
+func storeImpls(t *testing.T) map[string]Store {
+	t.Helper()
+	stores := map[string]Store{"memory": NewMemoryStore()}
+	if url := os.Getenv("TEST_DATABASE_URL"); url != "" {
+		db, err := NewDatabase(config.DatabaseConfig{URL: url, MaxOpenConns: 4, MaxIdleConns: 2})
+		if err != nil {
+			t.Fatalf("connect TEST_DATABASE_URL: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		stores["postgres"] = db
+	}
+	return stores
+}
+
+// uniq keeps IDs from colliding with earlier runs on a shared database.
+func uniq(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+}
+
+func mustNoErr(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStoreStudents(t *testing.T) {
+	for name, store := range storeImpls(t) {
+		t.Run(name, func(t *testing.T) {
+			dept := uniq("dept")
+			s := &models.Student{StudentID: uniq("s"), Name: "Kim", Email: "kim@example.com", Department: dept}
+			mustNoErr(t, store.CreateStudent(s))
+			if s.BootstrapState != models.BootstrapPending {
+				t.Errorf("bootstrap state = %q, want pending", s.BootstrapState)
+			}
+			if err := store.CreateStudent(&models.Student{StudentID: s.StudentID, Name: "dup", Department: dept}); err == nil {
+				t.Error("duplicate student_id was accepted")
+			}
+
+			mustNoErr(t, store.UpdateStudent(s.StudentID, map[string]interface{}{"keystone_project_id": "p1", "cohort": "2024"}))
+			got, err := store.GetStudent(s.StudentID)
+			mustNoErr(t, err)
+			if got.KeystoneProjectID != "p1" || got.Cohort != "2024" || got.Email != "kim@example.com" {
+				t.Errorf("GetStudent = %+v", got)
+			}
+
+			list, err := store.ListStudents(dept)
+			mustNoErr(t, err)
+			if len(list) != 1 || list[0].StudentID != s.StudentID {
+				t.Errorf("ListStudents(%s) = %+v", dept, list)
+			}
+
+			mustNoErr(t, store.DeleteStudent(s.StudentID))
+			if _, err := store.GetStudent(s.StudentID); !errors.Is(err, sql.ErrNoRows) {
+				t.Errorf("GetStudent after delete: err = %v, want sql.ErrNoRows", err)
+			}
+			if err := store.DeleteStudent(s.StudentID); err == nil {
+				t.Error("deleting a missing student succeeded")
+			}
+		})
+	}
+}
+
+func TestStoreCoursesAndEnrollments(t *testing.T) {
+	for name, store := range storeImpls(t) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			dept := uniq("dept")
+			s := &models.Student{StudentID: uniq("s"), Name: "Lee", Department: dept}
+			mustNoErr(t, store.CreateStudent(s))
+			c := &models.Course{CourseID: uniq("c"), Title: "OS", Department: dept, Semester: "2024-1",
+				StartAt: now.Add(-time.Hour), EndAt: now.Add(time.Hour), ProfileName: "basic"}
+			mustNoErr(t, store.CreateCourse(c))
+
+			if err := store.CreateCourse(&models.Course{CourseID: uniq("c"), Title: "x", Department: dept, Semester: "2024-1",
+				StartAt: now, EndAt: now, ProfileName: uniq("missing")}); err == nil {
+				t.Error("course with unknown profile was accepted")
+			}
+
+			mustNoErr(t, store.UpdateCourse(c.CourseID, map[string]interface{}{"profile_name": "lab"}))
+			got, err := store.GetCourse(c.CourseID)
+			mustNoErr(t, err)
+			if got.ProfileName != "lab" {
+				t.Errorf("profile = %q, want lab", got.ProfileName)
+			}
+			courses, err := store.ListCourses(dept, "2024-1")
+			mustNoErr(t, err)
+			if len(courses) != 1 {
+				t.Errorf("ListCourses = %+v", courses)
+			}
+
+			e := &models.Enrollment{StudentID: s.StudentID, CourseID: c.CourseID, Status: "active", StartAt: c.StartAt, EndAt: c.EndAt}
+			mustNoErr(t, store.EnrollStudent(e))
+			if err := store.EnrollStudent(&models.Enrollment{StudentID: s.StudentID, CourseID: c.CourseID, Status: "bogus"}); err == nil {
+				t.Error("invalid enrollment status was accepted")
+			}
+			byStudent, err := store.GetStudentEnrollments(s.StudentID)
+			mustNoErr(t, err)
+			byCourse, err := store.ListCourseEnrollments(c.CourseID)
+			mustNoErr(t, err)
+			if len(byStudent) != 1 || len(byCourse) != 1 || byStudent[0].Status != "active" {
+				t.Errorf("enrollments by student %+v, by course %+v", byStudent, byCourse)
+			}
+
+			mustNoErr(t, store.UpdateEnrollment(s.StudentID, c.CourseID, map[string]interface{}{"status": "completed"}))
+			completed, err := store.ListEnrollmentsByStatus("completed")
+			mustNoErr(t, err)
+			found := false
+			for _, e := range completed {
+				found = found || (e.StudentID == s.StudentID && e.CourseID == c.CourseID)
+			}
+			if !found {
+				t.Error("completed enrollment not listed by status")
+			}
+
+			mustNoErr(t, store.UnenrollStudent(s.StudentID, c.CourseID))
+			if err := store.UnenrollStudent(s.StudentID, c.CourseID); err == nil {
+				t.Error("unenrolling twice succeeded")
+			}
+
+			// 과목 삭제는 수강 등록을 함께 지운다 (ON DELETE CASCADE)
+			mustNoErr(t, store.EnrollStudent(e))
+			mustNoErr(t, store.DeleteCourse(c.CourseID))
+			left, err := store.GetStudentEnrollments(s.StudentID)
+			mustNoErr(t, err)
+			if len(left) != 0 {
+				t.Errorf("enrollments after course delete = %+v", left)
+			}
+		})
+	}
+}
+
+func TestStoreProfiles(t *testing.T) {
+	for name, store := range storeImpls(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, err := store.GetProfile("basic"); err != nil {
+				t.Fatalf("builtin profile basic: %v", err)
+			}
+
+			p := &models.Profile{Name: uniq("p"), Limits: models.QuotaProfile{Cores: 4}}
+			mustNoErr(t, store.CreateProfile(p))
+			if p.Version != 1 {
+				t.Errorf("version = %d, want 1", p.Version)
+			}
+			if err := store.CreateProfile(&models.Profile{Name: p.Name}); !errors.Is(err, ErrProfileExists) {
+				t.Errorf("duplicate create: err = %v, want ErrProfileExists", err)
+			}
+
+			stale := *p
+			p.Limits.Cores = 8
+			mustNoErr(t, store.UpdateProfile(p))
+			if p.Version != 2 {
+				t.Errorf("version after update = %d, want 2", p.Version)
+			}
+			stale.Limits.Cores = 16
+			if err := store.UpdateProfile(&stale); !errors.Is(err, ErrProfileVersionConflict) {
+				t.Errorf("stale update: err = %v, want ErrProfileVersionConflict", err)
+			}
+
+			versions, err := store.ListProfileVersions(p.Name)
+			mustNoErr(t, err)
+			if len(versions) != 2 || versions[0].Version != 2 || versions[0].Limits.Cores != 8 {
+				t.Errorf("versions = %+v", versions)
+			}
+
+			mustNoErr(t, store.SetBaselineRule(&models.BaselineRule{Scope: models.BaselineScopeDepartment, Value: uniq("dept"), ProfileName: p.Name}))
+			if err := store.DeleteProfile(p.Name); !errors.Is(err, ErrProfileInUse) {
+				t.Errorf("delete in use: err = %v, want ErrProfileInUse", err)
+			}
+
+			if _, err := store.GetProfile(uniq("missing")); !errors.Is(err, ErrProfileNotFound) {
+				t.Errorf("missing profile: err = %v, want ErrProfileNotFound", err)
+			}
+		})
+	}
+}
+
+func TestStoreCredentialTokens(t *testing.T) {
+	for name, store := range storeImpls(t) {
+		t.Run(name, func(t *testing.T) {
+			s := &models.Student{StudentID: uniq("s"), Name: "Park", Department: "cs"}
+			mustNoErr(t, store.CreateStudent(s))
+
+			mustNoErr(t, store.SaveCredentialToken(s.StudentID, "hash-1", time.Now().Add(time.Hour)))
+			if err := store.ConsumeCredentialToken(s.StudentID, "wrong"); !errors.Is(err, ErrCredentialTokenInvalid) {
+				t.Errorf("wrong hash: err = %v", err)
+			}
+			mustNoErr(t, store.ConsumeCredentialToken(s.StudentID, "hash-1"))
+			if err := store.ConsumeCredentialToken(s.StudentID, "hash-1"); !errors.Is(err, ErrCredentialTokenInvalid) {
+				t.Errorf("second use: err = %v", err)
+			}
+
+			// 되살리면 다시 한 번 쓸 수 있다
+			mustNoErr(t, store.RearmCredentialToken(s.StudentID, "hash-1"))
+			mustNoErr(t, store.ConsumeCredentialToken(s.StudentID, "hash-1"))
+
+			// 그 사이 새 토큰이 발급됐으면 예전 토큰을 되살리지 않고 새 토큰도 그대로 둔다
+			mustNoErr(t, store.SaveCredentialToken(s.StudentID, "hash-2", time.Now().Add(time.Hour)))
+			mustNoErr(t, store.RearmCredentialToken(s.StudentID, "hash-1"))
+			if err := store.ConsumeCredentialToken(s.StudentID, "hash-1"); !errors.Is(err, ErrCredentialTokenInvalid) {
+				t.Errorf("stale re-arm: err = %v", err)
+			}
+			mustNoErr(t, store.ConsumeCredentialToken(s.StudentID, "hash-2"))
+
+			mustNoErr(t, store.SaveCredentialToken(s.StudentID, "hash-3", time.Now().Add(-time.Second)))
+			if err := store.ConsumeCredentialToken(s.StudentID, "hash-3"); !errors.Is(err, ErrCredentialTokenInvalid) {
+				t.Errorf("expired: err = %v", err)
+			}
+		})
+	}
+}
+
+func TestStoreReconcileJobs(t *testing.T) {
+	for name, store := range storeImpls(t) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			s := &models.Student{StudentID: uniq("s"), Name: "Choi", Department: "cs"}
+			mustNoErr(t, store.CreateStudent(s))
+			c := &models.Course{CourseID: uniq("c"), Title: "DB", Department: "cs", Semester: "2024-1", StartAt: now, EndAt: now.Add(time.Hour), ProfileName: "basic"}
+			mustNoErr(t, store.CreateCourse(c))
+
+			job := &models.ReconcileJob{StudentID: s.StudentID, CourseID: c.CourseID, Reason: "enroll"}
+			mustNoErr(t, store.EnrollStudentWithJob(&models.Enrollment{StudentID: s.StudentID, CourseID: c.CourseID, Status: "active", StartAt: now, EndAt: now.Add(time.Hour)}, job))
+			if job.ID == 0 {
+				t.Fatal("job ID not set")
+			}
+
+			// 공유 DB 에는 다른 작업이 남아 있을 수 있으므로 이 작업이 나올 때까지 가져간다
+			var claimed *models.ReconcileJob
+			for range 100 {
+				j, err := store.ClaimReconcileJob(time.Minute)
+				mustNoErr(t, err)
+				if j == nil || j.ID == job.ID {
+					claimed = j
+					break
+				}
+			}
+			if claimed == nil || claimed.Status != models.ReconcileJobRunning || claimed.Attempts != 1 {
+				t.Fatalf("claimed = %+v", claimed)
+			}
+			if again, err := store.ClaimReconcileJob(time.Minute); err != nil || (again != nil && again.ID == job.ID) {
+				t.Errorf("leased job claimed twice: %+v, %v", again, err)
+			}
+
+			finished := time.Now()
+			claimed.Status, claimed.FinishedAt = models.ReconcileJobSucceeded, &finished
+			mustNoErr(t, store.UpdateReconcileJob(claimed))
+			got, err := store.GetReconcileJob(job.ID)
+			mustNoErr(t, err)
+			if got.Status != models.ReconcileJobSucceeded {
+				t.Errorf("status = %q, want succeeded", got.Status)
+			}
+			if _, err := store.GetReconcileJob(-1); !errors.Is(err, ErrReconcileJobNotFound) {
+				t.Errorf("missing job: err = %v", err)
+			}
+
+			// 과목 프로파일 변경은 수강생마다 작업을 남기고, 실패하면 아무것도 남기지 않는다
+			jobs, err := store.UpdateCourseWithJobs(c.CourseID, map[string]interface{}{"profile_name": "lab"}, "course_profile")
+			mustNoErr(t, err)
+			if len(jobs) != 1 || jobs[0].StudentID != s.StudentID || jobs[0].ID == 0 || jobs[0].Status != models.ReconcileJobPending {
+				t.Fatalf("course jobs = %+v", jobs)
+			}
+			if got, _ := store.GetCourse(c.CourseID); got.ProfileName != "lab" {
+				t.Errorf("profile = %q, want lab", got.ProfileName)
+			}
+			if _, err := store.UpdateCourseWithJobs(c.CourseID, map[string]interface{}{"profile_name": uniq("p")}, "course_profile"); !errors.Is(err, ErrProfileNotFound) {
+				t.Errorf("unknown profile: err = %v", err)
+			}
+			if got, _ := store.GetReconcileJob(jobs[0].ID + 1); got != nil {
+				t.Errorf("failed course update enqueued %+v", got)
+			}
+		})
+	}
+}
+
+func TestStoreLeaderLease(t *testing.T) {
+	for name, store := range storeImpls(t) {
+		t.Run(name, func(t *testing.T) {
+			lease := uniq("lease")
+			got, err := store.AcquireLeaderLease(lease, "a", time.Minute)
+			mustNoErr(t, err)
+			if got.Holder != "a" {
+				t.Fatalf("holder = %q, want a", got.Holder)
+			}
+			if got, err = store.AcquireLeaderLease(lease, "b", time.Minute); err != nil || got.Holder != "a" {
+				t.Errorf("contender took a live lease: %+v, %v", got, err)
+			}
+			mustNoErr(t, store.ReleaseLeaderLease(lease, "a"))
+			if got, err = store.AcquireLeaderLease(lease, "b", time.Minute); err != nil || got.Holder != "b" {
+				t.Errorf("released lease not taken over: %+v, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestStoreFailOrphanedRuns(t *testing.T) {
+	for name, store := range storeImpls(t) {
+		t.Run(name, func(t *testing.T) {
+			lease := uniq("lease")
+			_, err := store.AcquireLeaderLease(lease, "live", time.Minute)
+			mustNoErr(t, err)
+			live := &models.ReconciliationRun{Trigger: "manual", Status: models.ReconciliationRunning, Holder: "live", StartedAt: time.Now()}
+			dead := &models.ReconciliationRun{Trigger: "manual", Status: models.ReconciliationRunning, Holder: "dead", StartedAt: time.Now()}
+			mustNoErr(t, store.CreateReconciliationRun(live))
+			mustNoErr(t, store.CreateReconciliationRun(dead))
+
+			n, err := store.FailOrphanedReconciliationRuns(lease, "interrupted")
+			mustNoErr(t, err)
+			if n < 1 {
+				t.Errorf("failed %d runs, want at least the orphaned one", n)
+			}
+			got, err := store.GetReconciliationRun(live.ID)
+			mustNoErr(t, err)
+			if got.Status != models.ReconciliationRunning || got.Holder != "live" {
+				t.Errorf("live run = %s (holder %q), want running", got.Status, got.Holder)
+			}
+			got, err = store.GetReconciliationRun(dead.ID)
+			mustNoErr(t, err)
+			if got.Status != models.ReconciliationFailed || got.Error != "interrupted" {
+				t.Errorf("orphaned run = %s (%q), want failed", got.Status, got.Error)
+			}
+		})
+	}
+}
@@ -1,21 +1,73 @@
 package database
 
 import (
+	"database/sql"
+	"encoding/json"
 	"fmt"
 
 	"example.com/quotaapi/internal/models"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
+// studentColumns is the SELECT list matched by scanStudent.
+const studentColumns = `student_id, name, email, department,
+	COALESCE(keystone_project_id, ''), COALESCE(keystone_user_id, ''), created_at,
+	bootstrap_state, COALESCE(bootstrap_error, ''), bootstrap_attempts, bootstrap_updated_at,
+	keystone_project_adopted, keystone_user_adopted, COALESCE(cohort, ''),
+	COALESCE(quota_state, ''), quota_blockers, COALESCE(bootstrap_saga_id, '')`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanStudent(row rowScanner, student *models.Student) error {
+	var updatedAt sql.NullTime
+	var blockersJSON []byte
+	err := row.Scan(
+		&student.StudentID,
+		&student.Name,
+		&student.Email,
+		&student.Department,
+		&student.KeystoneProjectID,
+		&student.KeystoneUserID,
+		&student.CreatedAt,
+		&student.BootstrapState,
+		&student.BootstrapError,
+		&student.BootstrapAttempts,
+		&updatedAt,
+		&student.ProjectAdopted,
+		&student.UserAdopted,
+		&student.Cohort,
+		&student.QuotaState,
+		&blockersJSON,
+		&student.BootstrapSagaID,
+	)
+	if err != nil {
+		return err
+	}
+	if updatedAt.Valid {
+		student.BootstrapUpdatedAt = &updatedAt.Time
+	}
+	if len(blockersJSON) > 0 {
+		if err := json.Unmarshal(blockersJSON, &student.QuotaBlockers); err != nil {
+			return fmt.Errorf("failed to unmarshal quota blockers: %w", err)
+		}
+	}
+	return nil
+}
+
 // CreateStudent creates a new student in the database
 func (db *Database) CreateStudent(student *models.Student) error {
+	if student.BootstrapState == "" {
+		student.BootstrapState = models.BootstrapPending
+	}
 	query := `
-		INSERT INTO students (student_id, name, email, department, keystone_user_id, keystone_project_id)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO students (student_id, name, email, department, keystone_user_id, keystone_project_id, bootstrap_state, cohort)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''))
 	`
 	_, err := db.db.Exec(query,
 		student.StudentID, student.Name, student.Email, student.Department,
-		student.KeystoneUserID, student.KeystoneProjectID)
+		student.KeystoneUserID, student.KeystoneProjectID, student.BootstrapState, student.Cohort)
 	if err != nil {
 		return fmt.Errorf("failed to create student: %w", err)
 	}
@@ -24,18 +76,10 @@ func (db *Database) CreateStudent(student *models.Student) error {
 
 // GetStudent retrieves a single student by ID
 func (db *Database) GetStudent(studentID string) (*models.Student, error) {
-	query := "SELECT student_id, name, email, department, keystone_project_id, keystone_user_id, created_at FROM students WHERE student_id = $1"
+	query := "SELECT " + studentColumns + " FROM students WHERE student_id = $1"
 
 	student := &models.Student{}
-	err := db.db.QueryRow(query, studentID).Scan(
-		&student.StudentID,
-		&student.Name,
-		&student.Email,
-		&student.Department,
-		&student.KeystoneProjectID,
-		&student.KeystoneUserID,
-		&student.CreatedAt,
-	)
+	err := scanStudent(db.db.QueryRow(query, studentID), student)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get student %s: %w", studentID, err)
@@ -98,12 +142,10 @@ func (db *Database) ListStudents(department string) ([]models.Student, error) {
 	var args []interface{}
 
 	if department != "" {
-		query = `SELECT student_id, name, email, department, keystone_user_id, keystone_project_id, created_at
-				 FROM students WHERE department = $1 ORDER BY student_id`
+		query = "SELECT " + studentColumns + " FROM students WHERE department = $1 ORDER BY student_id"
 		args = []interface{}{department}
 	} else {
-		query = `SELECT student_id, name, email, department, keystone_user_id, keystone_project_id, created_at
-				 FROM students ORDER BY student_id`
+		query = "SELECT " + studentColumns + " FROM students ORDER BY student_id"
 	}
 
 	rows, err := db.db.Query(query, args...)
@@ -115,9 +157,7 @@ func (db *Database) ListStudents(department string) ([]models.Student, error) {
 	var students []models.Student
 	for rows.Next() {
 		var student models.Student
-		err := rows.Scan(&student.StudentID, &student.Name, &student.Email, &student.Department,
-			&student.KeystoneUserID, &student.KeystoneProjectID, &student.CreatedAt)
-		if err != nil {
+		if err := scanStudent(rows, &student); err != nil {
 			return nil, fmt.Errorf("failed to scan student: %w", err)
 		}
 		students = append(students, student)
@@ -132,7 +172,7 @@ func (db *Database) ListStudents(department string) ([]models.Student, error) {
 
 // GetAllStudents retrieves all students from the database
 func (db *Database) GetAllStudents() ([]*models.Student, error) {
-	query := "SELECT student_id, name, email, department, keystone_project_id, keystone_user_id, created_at FROM students ORDER BY student_id"
+	query := "SELECT " + studentColumns + " FROM students ORDER BY student_id"
 
 	rows, err := db.db.Query(query)
 	if err != nil {
@@ -143,16 +183,7 @@ func (db *Database) GetAllStudents() ([]*models.Student, error) {
 	var students []*models.Student
 	for rows.Next() {
 		student := &models.Student{}
-		err := rows.Scan(
-			&student.StudentID,
-			&student.Name,
-			&student.Email,
-			&student.Department,
-			&student.KeystoneProjectID,
-			&student.KeystoneUserID,
-			&student.CreatedAt,
-		)
-		if err != nil {
+		if err := scanStudent(rows, student); err != nil {
 			return nil, fmt.Errorf("failed to scan student: %w", err)
 		}
 		students = append(students, student)
@@ -164,3 +195,32 @@ func (db *Database) GetAllStudents() ([]*models.Student, error) {
 
 	return students, nil
 }
+
+// ListStudentsByBootstrapState returns students whose bootstrap saga is in
+// one of the given states, oldest first.
+func (db *Database) ListStudentsByBootstrapState(states ...models.BootstrapState) ([]models.Student, error) {
+	names := make([]string, len(states))
+	for i, st := range states {
+		names[i] = string(st)
+	}
+	query := "SELECT " + studentColumns + " FROM students WHERE bootstrap_state = ANY($1) ORDER BY created_at, student_id"
+
+	rows, err := db.db.Query(query, pq.Array(names))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list students by bootstrap state: %w", err)
+	}
+	defer rows.Close()
+
+	var students []models.Student
+	for rows.Next() {
+		var student models.Student
+		if err := scanStudent(rows, &student); err != nil {
+			return nil, fmt.Errorf("failed to scan student: %w", err)
+		}
+		students = append(students, student)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating students: %w", err)
+	}
+	return students, nil
+}
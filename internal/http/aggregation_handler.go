@@ -0,0 +1,116 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/models"
+)
+
+// AggregationHandler manages aggregation policy rules
+// (/aggregation-policies/global, /aggregation-policies/department/{dept})
+type AggregationHandler struct {
+	db database.AggregationStore
+}
+
+func NewAggregationHandler(db database.AggregationStore) *AggregationHandler {
+	return &AggregationHandler{db: db}
+}
+
+func (h *AggregationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+
+	switch {
+	case r.Method == "GET" && path == "/aggregation-policies":
+		h.listRules(w, r)
+	case r.Method == "PUT" && strings.HasPrefix(path, "/aggregation-policies/"):
+		h.setRule(w, r)
+	case r.Method == "DELETE" && strings.HasPrefix(path, "/aggregation-policies/"):
+		h.deleteRule(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *AggregationHandler) listRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.db.ListAggregationRules()
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to list aggregation rules: " + err.Error()})
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, rules)
+}
+
+func (h *AggregationHandler) setRule(w http.ResponseWriter, r *http.Request) {
+	scope, value, ok := aggregationKeyFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.AggregationPolicy
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid json: " + err.Error()})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+
+	rule := &models.AggregationRule{Scope: scope, Value: value, AggregationPolicy: req}
+	if err := h.db.SetAggregationRule(rule); err != nil {
+		if errors.Is(err, database.ErrProfileNotFound) {
+			WriteJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to set aggregation rule: " + err.Error()})
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, rule)
+}
+
+func (h *AggregationHandler) deleteRule(w http.ResponseWriter, r *http.Request) {
+	scope, value, ok := aggregationKeyFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteAggregationRule(scope, value); err != nil {
+		if errors.Is(err, database.ErrAggregationRuleNotFound) {
+			WriteJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to delete aggregation rule: " + err.Error()})
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]any{"message": "aggregation rule deleted successfully"})
+}
+
+// aggregationKeyFromPath accepts /aggregation-policies/global and
+// /aggregation-policies/department/{dept}
+func aggregationKeyFromPath(w http.ResponseWriter, r *http.Request) (models.AggregationScope, string, bool) {
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 3 {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid aggregation scope"})
+		return "", "", false
+	}
+	scope, err := models.ParseAggregationScope(pathParts[2])
+	if err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return "", "", false
+	}
+	switch {
+	case scope == models.AggregationScopeGlobal && len(pathParts) == 3:
+		return scope, "", true
+	case scope == models.AggregationScopeDepartment && len(pathParts) == 4 && pathParts[3] != "":
+		return scope, pathParts[3], true
+	}
+	WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "use /aggregation-policies/global or /aggregation-policies/department/{department}"})
+	return "", "", false
+}
@@ -2,10 +2,12 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
-	"example.com/quotaapi/internal/models"
+	"example.com/quotaapi/internal/api"
+	"example.com/quotaapi/internal/database"
 	"example.com/quotaapi/internal/openstack"
 )
 
@@ -19,12 +21,13 @@ type ApplyProfileReq struct {
 
 // ApplyProfileServer handles profile-based quota application
 type ApplyProfileServer struct {
-	OS *openstack.Clients
+	Quotas   *openstack.QuotaService
+	Profiles database.ProfileStore
 }
 
 // NewApplyProfileHandler creates a new ApplyProfileHandler
-func NewApplyProfileHandler(os *openstack.Clients) http.HandlerFunc {
-	s := &ApplyProfileServer{OS: os}
+func NewApplyProfileHandler(quotas *openstack.QuotaService, profiles database.ProfileStore) http.HandlerFunc {
+	s := &ApplyProfileServer{Quotas: quotas, Profiles: profiles}
 	return s.Handle
 }
 
@@ -46,96 +49,54 @@ func (s *ApplyProfileServer) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// models.Profiles에서 프로파일 조회
-	profile, ok := models.Profiles[strings.ToLower(req.Profile)]
-	if !ok {
-		WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "unknown profile (use 'basic' or 'lab')"})
+	// 프로파일 카탈로그에서 조회
+	entry, err := s.Profiles.GetProfile(strings.ToLower(req.Profile))
+	if err != nil {
+		if errors.Is(err, database.ErrProfileNotFound) {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "unknown profile (see GET /profiles)"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load profile: " + err.Error()})
 		return
 	}
+	profile := entry.Limits
 
 	ctx := r.Context()
 
-	// 현재 쿼터 조회
-	novaCurr, nErr := s.OS.GetNovaQuotaDetail(ctx, req.ProjectID)
-	cinderCurr, cErr := s.OS.GetCinderQuotaDetail(ctx, req.ProjectID)
-	if nErr != nil || cErr != nil {
-		WriteJSON(w, http.StatusBadGateway, map[string]any{
-			"error":  "failed to read current quotas",
-			"nova":   errString(nErr),
-			"cinder": errString(cErr),
-		})
+	// 현재 쿼터 조회 및 계획과의 차이 계산
+	current, diff, err := s.Quotas.Diff(ctx, req.ProjectID, profile)
+	if err != nil {
+		WriteJSON(w, http.StatusBadGateway, map[string]string{"error": "failed to read current quotas: " + err.Error()})
 		return
 	}
 
-	// 기본 응답(계획/현재 상태)
-	resp := map[string]any{
-		"projectId": req.ProjectID,
-		"profile":   strings.ToLower(req.Profile),
-		"plan":      profile,
-		"applied":   false,
-		"dryRun":    req.DryRun,
-		"current": map[string]any{
-			"nova": map[string]any{
-				"cores":     novaCurr.Cores,
-				"ramMB":     novaCurr.RAMMB,
-				"instances": novaCurr.Instances,
-			},
-			"cinder": map[string]any{
-				"gigabytes": cinderCurr.Gigabytes,
-				"volumes":   cinderCurr.Volumes,
-				"snapshots": cinderCurr.Snapshots,
-			},
-		},
+	resp := api.ApplyProfileResponse{
+		ProjectID: req.ProjectID,
+		Profile:   entry.Name,
+		Version:   entry.Version,
+		Plan:      profile,
+		DryRun:    req.DryRun,
+		Current:   current,
 	}
-
-	// diff 포함 요청 시, 계획(limit)과 현재(limit)의 차이를 계산
 	if req.IncludeDiff {
-		resp["diff"] = map[string]any{
-			"nova": map[string]int{
-				"cores":     profile.Cores - novaCurr.Cores.Limit,
-				"ramMB":     profile.RAMMB - novaCurr.RAMMB.Limit,
-				"instances": profile.Instances - novaCurr.Instances.Limit,
-			},
-			"cinder": map[string]int{
-				"gigabytes": profile.Gigabytes - cinderCurr.Gigabytes.Limit,
-				"volumes":   profile.Volumes - cinderCurr.Volumes.Limit,
-				"snapshots": profile.Snapshots - cinderCurr.Snapshots.Limit,
-			},
-		}
+		resp.Diff = &diff
 	}
 
-	// 실제 적용(dryRun=false)
+	// 실제 적용(dryRun=false): Nova, Cinder(프로파일에 있는 볼륨 타입만), Neutron
 	if !req.DryRun {
-		// Nova 쿼터 적용
-		cores, ram, inst := profile.Cores, profile.RAMMB, profile.Instances
-		if err := s.OS.ApplyNovaQuota(ctx, req.ProjectID, &cores, &ram, &inst); err != nil {
-			WriteJSON(w, http.StatusBadGateway, map[string]string{"error": "nova apply failed: " + err.Error()})
+		// 실패하면 이미 바꾼 서비스는 위에서 읽은 current 로 되돌린다
+		results, err := s.Quotas.ApplyFrom(ctx, current, openstack.QuotaUpdateFromProfile(profile))
+		resp.Results = results
+		if err != nil {
+			resp.Error = "apply failed: " + err.Error()
+			WriteJSON(w, http.StatusBadGateway, resp)
 			return
 		}
-
-		// Cinder 쿼터 적용
-		vols, snaps, gigs := profile.Volumes, profile.Snapshots, profile.Gigabytes
-		if err := s.OS.ApplyCinderQuota(ctx, req.ProjectID, &vols, &snaps, &gigs); err != nil {
-			WriteJSON(w, http.StatusBadGateway, map[string]string{"error": "cinder apply failed: " + err.Error()})
-			return
-		}
-
-		resp["applied"] = true
+		resp.Applied = true
 
 		// 적용 후 최신 상태 재조회
-		novaCurr, _ = s.OS.GetNovaQuotaDetail(ctx, req.ProjectID)
-		cinderCurr, _ = s.OS.GetCinderQuotaDetail(ctx, req.ProjectID)
-		resp["current"] = map[string]any{
-			"nova": map[string]any{
-				"cores":     novaCurr.Cores,
-				"ramMB":     novaCurr.RAMMB,
-				"instances": novaCurr.Instances,
-			},
-			"cinder": map[string]any{
-				"gigabytes": cinderCurr.Gigabytes,
-				"volumes":   cinderCurr.Volumes,
-				"snapshots": cinderCurr.Snapshots,
-			},
+		if after, err := s.Quotas.Get(ctx, req.ProjectID); err == nil {
+			resp.Current = after
 		}
 	}
 
@@ -36,8 +36,15 @@ func NewAuthCheckHandler(cfg *config.Config) http.HandlerFunc {
 		roles, _ := token["roles"].([]any)
 		log.Println(raw["token"])
 
+		// 인증 미들웨어가 결정한 애플리케이션 역할 (AUTH_ENABLED=false 이면 없음)
+		var appRole Role
+		if p, ok := PrincipalFrom(r.Context()); ok {
+			appRole = p.Role
+		}
+
 		WriteJSON(w, http.StatusOK, map[string]any{
-			"auth": "ok",
+			"auth":     "ok",
+			"app_role": appRole,
 			"user": map[string]any{
 				"id":     safe(user, "id"),
 				"name":   safe(user, "name"),
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"example.com/quotaapi/internal/config"
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/openstack"
+)
+
+// TokenValidator resolves an X-Auth-Token to its Keystone identity.
+// *openstack.TokenValidator 가 구현한다.
+type TokenValidator interface {
+	Validate(ctx context.Context, token string) (*openstack.TokenInfo, error)
+}
+
+// Principal is the authenticated caller attached to the request context.
+type Principal struct {
+	*openstack.TokenInfo
+	Role Role `json:"role"`
+}
+
+type principalKey struct{}
+
+// PrincipalFrom returns the caller set by the auth middleware, if any.
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// Authenticator validates Keystone tokens and enforces the route policy.
+type Authenticator struct {
+	cfg       config.AuthConfig
+	validator TokenValidator
+	students  database.StudentStore
+	policy    []Rule
+}
+
+func NewAuthenticator(cfg config.AuthConfig, validator TokenValidator, students database.StudentStore) *Authenticator {
+	return &Authenticator{
+		cfg:       cfg,
+		validator: validator,
+		students:  students,
+		policy:    DefaultPolicy,
+	}
+}
+
+// Middleware wraps next with authentication and per-route authorization.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rule, params := matchRule(a.policy, r.Method, r.URL.Path)
+		if rule.Public {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := r.Header.Get("X-Auth-Token")
+		if token == "" {
+			w.Header().Set("WWW-Authenticate", "Keystone")
+			WriteJSON(w, http.StatusUnauthorized, map[string]any{"error": "missing X-Auth-Token"})
+			return
+		}
+		info, err := a.validator.Validate(r.Context(), token)
+		if err != nil {
+			if errors.Is(err, openstack.ErrInvalidToken) {
+				w.Header().Set("WWW-Authenticate", "Keystone")
+				WriteJSON(w, http.StatusUnauthorized, map[string]any{"error": err.Error()})
+				return
+			}
+			log.Printf("auth: token validation failed: %v", err)
+			WriteJSON(w, http.StatusServiceUnavailable, map[string]any{"error": "identity service unavailable"})
+			return
+		}
+
+		p := &Principal{TokenInfo: info, Role: a.roleOf(info)}
+		if p.Role == "" || !rule.allows(p.Role) {
+			WriteJSON(w, http.StatusForbidden, map[string]any{"error": "forbidden"})
+			return
+		}
+		if p.Role == RoleStudent && !a.owns(p, rule.Owner, r, params) {
+			WriteJSON(w, http.StatusForbidden, map[string]any{"error": "forbidden: not your resource"})
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, p)))
+	})
+}
+
+// roleOf maps Keystone roles to the highest application role. 토큰의 역할은
+// 토큰 스코프에 대한 것이므로 교직원 역할은 관리 프로젝트/도메인 스코프에서만
+// 인정한다 (다른 테넌트의 admin 이 앱 관리자가 되지 않도록). 학생 역할은
+// 프로젝트 스코프면 되고, owns 가 본인 자원으로 제한한다.
+func (a *Authenticator) roleOf(info *openstack.TokenInfo) Role {
+	if a.adminScoped(info) {
+		switch {
+		case info.HasRole(a.cfg.AdminRole):
+			return RoleAdmin
+		case info.HasRole(a.cfg.InstructorRole):
+			return RoleInstructor
+		case info.HasRole(a.cfg.TARole):
+			return RoleTA
+		}
+	}
+	if info.ProjectID != "" && info.HasRole(a.cfg.StudentRole) {
+		return RoleStudent
+	}
+	return ""
+}
+
+// adminScoped reports whether the token is scoped to the configured admin
+// project or domain.
+func (a *Authenticator) adminScoped(info *openstack.TokenInfo) bool {
+	return (a.cfg.AdminProjectID != "" && info.ProjectID == a.cfg.AdminProjectID) ||
+		(a.cfg.AdminDomainID != "" && info.DomainID == a.cfg.AdminDomainID)
+}
+
+// owns checks that a student caller is acting on their own student record
+// or project. 학생 레코드가 없으면 존재 여부를 드러내지 않도록 거부한다.
+func (a *Authenticator) owns(p *Principal, own ownership, r *http.Request, params []string) bool {
+	switch own {
+	case ownProjectIDQuery:
+		return p.ProjectID != "" && r.URL.Query().Get("projectId") == p.ProjectID
+	case ownStudentPath:
+		if len(params) == 0 || a.students == nil {
+			return false
+		}
+		student, err := a.students.GetStudent(params[0])
+		if err != nil {
+			return false
+		}
+		return (student.KeystoneProjectID != "" && student.KeystoneProjectID == p.ProjectID) ||
+			(student.KeystoneUserID != "" && student.KeystoneUserID == p.UserID)
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"testing"
+
+	"example.com/quotaapi/internal/config"
+	"example.com/quotaapi/internal/openstack"
+)
+
+func TestRoleOfHonoursOnlyAdminScope(t *testing.T) {
+	a := NewAuthenticator(config.AuthConfig{
+		AdminRole:      "admin",
+		InstructorRole: "instructor",
+		TARole:         "ta",
+		StudentRole:    "member",
+		AdminProjectID: "admin-project",
+		AdminDomainID:  "staff-domain",
+	}, nil, nil)
+
+	tests := []struct {
+		name string
+		info openstack.TokenInfo
+		want Role
+	}{
+		{name: "admin on admin project", info: openstack.TokenInfo{ProjectID: "admin-project", Roles: []string{"admin", "member"}}, want: RoleAdmin},
+		{name: "instructor on admin domain", info: openstack.TokenInfo{DomainID: "staff-domain", Roles: []string{"instructor"}}, want: RoleInstructor},
+		{name: "ta on admin project", info: openstack.TokenInfo{ProjectID: "admin-project", Roles: []string{"ta"}}, want: RoleTA},
+		{name: "admin on a tenant project", info: openstack.TokenInfo{ProjectID: "tenant", Roles: []string{"admin"}}, want: ""},
+		{name: "admin and member on a tenant project", info: openstack.TokenInfo{ProjectID: "tenant", Roles: []string{"admin", "member"}}, want: RoleStudent},
+		{name: "admin on another domain", info: openstack.TokenInfo{DomainID: "other", Roles: []string{"admin"}}, want: ""},
+		{name: "member on own project", info: openstack.TokenInfo{ProjectID: "student-project", Roles: []string{"member"}}, want: RoleStudent},
+		{name: "member on a domain", info: openstack.TokenInfo{DomainID: "staff-domain", Roles: []string{"member"}}, want: ""},
+		{name: "unscoped", info: openstack.TokenInfo{Roles: []string{"admin"}}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.roleOf(&tt.info); got != tt.want {
+				t.Errorf("roleOf = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/models"
+)
+
+// BaselineHandler manages baseline quota rules (/baselines/{scope}/{value})
+type BaselineHandler struct {
+	db database.BaselineStore
+}
+
+func NewBaselineHandler(db database.BaselineStore) *BaselineHandler {
+	return &BaselineHandler{db: db}
+}
+
+func (h *BaselineHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+
+	switch {
+	case r.Method == "GET" && path == "/baselines":
+		h.listRules(w, r)
+	case r.Method == "PUT" && strings.HasPrefix(path, "/baselines/"):
+		h.setRule(w, r)
+	case r.Method == "DELETE" && strings.HasPrefix(path, "/baselines/"):
+		h.deleteRule(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *BaselineHandler) listRules(w http.ResponseWriter, r *http.Request) {
+	var scope models.BaselineScope
+	if s := r.URL.Query().Get("scope"); s != "" {
+		var err error
+		if scope, err = models.ParseBaselineScope(s); err != nil {
+			WriteJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+	}
+
+	rules, err := h.db.ListBaselineRules(scope)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to list baseline rules: " + err.Error()})
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, rules)
+}
+
+func (h *BaselineHandler) setRule(w http.ResponseWriter, r *http.Request) {
+	scope, value, ok := baselineKeyFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.BaselineRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid json: " + err.Error()})
+		return
+	}
+	if req.Profile == "" {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "profile is required"})
+		return
+	}
+
+	rule := &models.BaselineRule{Scope: scope, Value: value, ProfileName: req.Profile}
+	if err := h.db.SetBaselineRule(rule); err != nil {
+		if errors.Is(err, database.ErrProfileNotFound) {
+			WriteJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to set baseline rule: " + err.Error()})
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, rule)
+}
+
+func (h *BaselineHandler) deleteRule(w http.ResponseWriter, r *http.Request) {
+	scope, value, ok := baselineKeyFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteBaselineRule(scope, value); err != nil {
+		if errors.Is(err, database.ErrBaselineRuleNotFound) {
+			WriteJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to delete baseline rule: " + err.Error()})
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]any{"message": "baseline rule deleted successfully"})
+}
+
+// baselineKeyFromPath extracts {scope}/{value} from /baselines/{scope}/{value}
+func baselineKeyFromPath(w http.ResponseWriter, r *http.Request) (models.BaselineScope, string, bool) {
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) != 4 || pathParts[3] == "" {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "use /baselines/{scope}/{value}"})
+		return "", "", false
+	}
+	scope, err := models.ParseBaselineScope(pathParts[2])
+	if err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return "", "", false
+	}
+	return scope, pathParts[3], true
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"log"
+	"net/http"
+
+	"example.com/quotaapi/internal/services"
+)
+
+// CapacityHandler reports committed quota against cluster capacity
+type CapacityHandler struct {
+	capacity *services.CapacityService
+}
+
+// NewCapacityHandler creates a new capacity handler
+func NewCapacityHandler(capacity *services.CapacityService) *CapacityHandler {
+	return &CapacityHandler{capacity: capacity}
+}
+
+// ServeHTTP handles GET /capacity
+func (h *CapacityHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet || r.URL.Path != "/capacity" {
+		http.NotFound(w, r)
+		return
+	}
+
+	report, err := h.capacity.Report(r.Context())
+	if err != nil {
+		WriteJSON(w, http.StatusBadGateway, map[string]any{"error": "failed to get capacity: " + err.Error()})
+		return
+	}
+	WriteJSON(w, http.StatusOK, report)
+}
+
+// capacityVerdict turns a capacity check into the handler's next step. 차단되면
+// 409 를 쓰고 false 를, warn 모드의 초과는 응답에 붙일 check 를 돌려준다.
+// 검사 자체가 실패하면 변경을 막지 않는다.
+func capacityVerdict(w http.ResponseWriter, check *services.CapacityCheck, err error) (*services.CapacityCheck, bool) {
+	if err != nil {
+		log.Printf("Warning: capacity check failed, allowing change: %v", err)
+		return nil, true
+	}
+	if !check.Allowed {
+		WriteJSON(w, http.StatusConflict, map[string]any{"error": check.Message(), "capacity": check})
+		return nil, false
+	}
+	if check.Warning() {
+		return check, true
+	}
+	return nil, true
+}
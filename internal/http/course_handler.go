@@ -2,20 +2,47 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
 
 	"example.com/quotaapi/internal/database"
 	"example.com/quotaapi/internal/models"
+	"example.com/quotaapi/internal/services"
 )
 
+// courseStore is what CourseHandler needs: courses, the profile catalog
+// they reference and the reconcile job queue.
+type courseStore interface {
+	database.CourseStore
+	database.ProfileStore
+	database.ReconcileJobStore
+}
+
 type CourseHandler struct {
-	db *database.Database
+	db            courseStore
+	reconcileJobs bool
+	capacity      *services.CapacityService
+}
+
+// NewCourseHandler creates a course handler. reconcileJobs 가 true 이면(작업
+// 큐 워커가 도는 경우) 과목 프로파일 변경과 함께 수강생마다 리콘실 작업을 남긴다.
+func NewCourseHandler(db courseStore, reconcileJobs bool) *CourseHandler {
+	return &CourseHandler{db: db, reconcileJobs: reconcileJobs}
 }
 
-func NewCourseHandler(db *database.Database) *CourseHandler {
-	return &CourseHandler{db: db}
+// WithCapacity checks new courses and profile changes against cluster
+// capacity (CAPACITY_*). nil 이면 검사하지 않는다.
+func (h *CourseHandler) WithCapacity(capacity *services.CapacityService) *CourseHandler {
+	h.capacity = capacity
+	return h
+}
+
+// courseCreateResponse adds a capacity warning to the created course.
+type courseCreateResponse struct {
+	*models.Course
+	CapacityWarning *services.CapacityCheck `json:"capacity_warning,omitempty"`
 }
 
 func (h *CourseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -49,6 +76,14 @@ func (h *CourseHandler) createCourse(w http.ResponseWriter, r *http.Request) {
 		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "missing required fields"})
 		return
 	}
+	if req.Profile == "" {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "profile is required (name of a profile in /profiles)"})
+		return
+	}
+	if _, err := h.db.GetProfile(req.Profile); err != nil {
+		writeProfileLookupError(w, err)
+		return
+	}
 
 	startAt, err := time.Parse("2006-01-02", req.StartAt)
 	if err != nil {
@@ -63,15 +98,25 @@ func (h *CourseHandler) createCourse(w http.ResponseWriter, r *http.Request) {
 	}
 
 	course := &models.Course{
-		CourseID:     req.CourseID,
-		Title:        req.Title,
-		Department:   req.Department,
-		Semester:     req.Semester,
-		StartAt:      startAt,
-		EndAt:        endAt,
-		QuotaProfile: req.QuotaProfile,
-		Defaults:     req.Defaults,
-		CreatedAt:    time.Now(),
+		CourseID:    req.CourseID,
+		Title:       req.Title,
+		Department:  req.Department,
+		Semester:    req.Semester,
+		StartAt:     startAt,
+		EndAt:       endAt,
+		ProfileName: req.Profile,
+		Defaults:    req.Defaults,
+		CreatedAt:   time.Now(),
+	}
+
+	// 예상 수강생 수만큼의 쿼타가 클러스터 용량을 넘는지 검사
+	resp := courseCreateResponse{Course: course}
+	if h.capacity != nil {
+		check, err := h.capacity.CheckNewCourse(r.Context(), course, req.ExpectedStudents)
+		var ok bool
+		if resp.CapacityWarning, ok = capacityVerdict(w, check, err); !ok {
+			return
+		}
 	}
 
 	if err := h.db.CreateCourse(course); err != nil {
@@ -79,7 +124,7 @@ func (h *CourseHandler) createCourse(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	WriteJSON(w, http.StatusCreated, course)
+	WriteJSON(w, http.StatusCreated, resp)
 }
 
 func (h *CourseHandler) getCourse(w http.ResponseWriter, r *http.Request) {
@@ -146,8 +191,31 @@ func (h *CourseHandler) updateCourse(w http.ResponseWriter, r *http.Request) {
 			updates["end_at"] = endAt
 		}
 	}
-	if req.QuotaProfile != nil {
-		updates["quota_profile"] = req.QuotaProfile
+	profileChanged := false
+	var capacityWarning *services.CapacityCheck
+	if req.Profile != nil {
+		if _, err := h.db.GetProfile(*req.Profile); err != nil {
+			writeProfileLookupError(w, err)
+			return
+		}
+		current, err := h.db.GetCourse(courseID)
+		if err != nil {
+			WriteJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+			return
+		}
+		profileChanged = current.ProfileName != *req.Profile
+		updates["profile_name"] = *req.Profile
+
+		// 수강생 쿼타가 새 프로파일로 바뀌었을 때의 용량 검사
+		if profileChanged && h.capacity != nil {
+			updated := *current
+			updated.ProfileName = *req.Profile
+			check, err := h.capacity.CheckCourseChange(r.Context(), &updated)
+			var ok bool
+			if capacityWarning, ok = capacityVerdict(w, check, err); !ok {
+				return
+			}
+		}
 	}
 	if req.Defaults != nil {
 		updates["defaults"] = req.Defaults
@@ -158,12 +226,29 @@ func (h *CourseHandler) updateCourse(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.db.UpdateCourse(courseID, updates); err != nil {
+	// 쿼타 프로파일이 바뀌면 이 과목 수강생마다 리콘실 작업을 같은 트랜잭션에 남긴다
+	resp := map[string]any{"message": "course updated successfully"}
+	var err error
+	if profileChanged && h.reconcileJobs {
+		var jobs []models.ReconcileJob
+		jobs, err = h.db.UpdateCourseWithJobs(courseID, updates, services.JobReasonCourseProfile)
+		jobIDs := make([]int64, 0, len(jobs))
+		for _, job := range jobs {
+			jobIDs = append(jobIDs, job.ID)
+		}
+		resp["reconcile_job_ids"] = jobIDs
+	} else {
+		err = h.db.UpdateCourse(courseID, updates)
+	}
+	if err != nil {
 		WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to update course: " + err.Error()})
 		return
 	}
 
-	WriteJSON(w, http.StatusOK, map[string]any{"message": "course updated successfully"})
+	if capacityWarning != nil {
+		resp["capacity_warning"] = capacityWarning
+	}
+	WriteJSON(w, http.StatusOK, resp)
 }
 
 func (h *CourseHandler) deleteCourse(w http.ResponseWriter, r *http.Request) {
@@ -181,3 +266,13 @@ func (h *CourseHandler) deleteCourse(w http.ResponseWriter, r *http.Request) {
 
 	WriteJSON(w, http.StatusOK, map[string]any{"message": "course deleted successfully"})
 }
+
+// writeProfileLookupError reports a course's profile reference that could
+// not be resolved.
+func writeProfileLookupError(w http.ResponseWriter, err error) {
+	if errors.Is(err, database.ErrProfileNotFound) {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+	WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to load profile: " + err.Error()})
+}
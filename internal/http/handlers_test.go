@@ -0,0 +1,179 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/models"
+)
+
+// newTestMux wires the catalog, course and student handlers to a fresh
+// MemoryStore without OpenStack (projectMgr/reconciler nil).
+func newTestMux(db database.Store) *http.ServeMux {
+	mux := http.NewServeMux()
+	students := NewStudentHandler(db, nil, nil)
+	mux.HandleFunc("/students", students.ServeHTTP)
+	mux.HandleFunc("/students/", students.ServeHTTP)
+	courses := NewCourseHandler(db, false)
+	mux.HandleFunc("/courses", courses.ServeHTTP)
+	mux.HandleFunc("/courses/", courses.ServeHTTP)
+	profiles := NewProfileHandler(db)
+	mux.HandleFunc("/profiles", profiles.ServeHTTP)
+	mux.HandleFunc("/profiles/", profiles.ServeHTTP)
+	baselines := NewBaselineHandler(db)
+	mux.HandleFunc("/baselines", baselines.ServeHTTP)
+	mux.HandleFunc("/baselines/", baselines.ServeHTTP)
+	return mux
+}
+
+type handlerStep struct {
+	name     string
+	method   string
+	path     string
+	body     string
+	wantCode int
+	wantBody string // 응답 본문에 포함되어야 하는 문자열
+}
+
+// runSteps executes steps in order against one store; 각 단계는 앞 단계의 결과에 의존한다.
+func runSteps(t *testing.T, steps []handlerStep) {
+	t.Helper()
+	mux := newTestMux(database.NewMemoryStore())
+	for _, st := range steps {
+		req := httptest.NewRequest(st.method, st.path, strings.NewReader(st.body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != st.wantCode {
+			t.Fatalf("%s: %s %s = %d, want %d (%s)", st.name, st.method, st.path, rec.Code, st.wantCode, rec.Body)
+		}
+		if st.wantBody != "" && !strings.Contains(rec.Body.String(), st.wantBody) {
+			t.Fatalf("%s: body %s does not contain %q", st.name, rec.Body, st.wantBody)
+		}
+	}
+}
+
+func TestStudentCourseEnrollmentHandlers(t *testing.T) {
+	runSteps(t, []handlerStep{
+		{name: "create student", method: "POST", path: "/students",
+			body:     `{"student_id":"20240001","name":"Kim","email":"kim@example.com","department":"cs"}`,
+			wantCode: http.StatusCreated, wantBody: `"bootstrap_state":"pending"`},
+		{name: "missing fields", method: "POST", path: "/students", body: `{"student_id":"x"}`, wantCode: http.StatusBadRequest},
+		{name: "duplicate student", method: "POST", path: "/students",
+			body:     `{"student_id":"20240001","name":"Kim","email":"kim@example.com","department":"cs"}`,
+			wantCode: http.StatusInternalServerError},
+		{name: "get student", method: "GET", path: "/students/20240001", wantCode: http.StatusOK, wantBody: `"name":"Kim"`},
+		{name: "unknown student", method: "GET", path: "/students/nope", wantCode: http.StatusNotFound},
+		{name: "list by department", method: "GET", path: "/students?department=cs", wantCode: http.StatusOK, wantBody: "20240001"},
+
+		{name: "course with unknown profile", method: "POST", path: "/courses",
+			body:     `{"course_id":"CS101","title":"OS","department":"cs","semester":"2024-1","start_at":"2024-03-01","end_at":"2024-06-30","profile":"nope"}`,
+			wantCode: http.StatusBadRequest},
+		{name: "course with bad date", method: "POST", path: "/courses",
+			body:     `{"course_id":"CS101","title":"OS","department":"cs","semester":"2024-1","start_at":"03/01","end_at":"2024-06-30","profile":"lab"}`,
+			wantCode: http.StatusBadRequest},
+		{name: "create course", method: "POST", path: "/courses",
+			body:     `{"course_id":"CS101","title":"OS","department":"cs","semester":"2024-1","start_at":"2024-03-01","end_at":"2024-06-30","profile":"lab"}`,
+			wantCode: http.StatusCreated, wantBody: `"profile":"lab"`},
+		{name: "change course profile", method: "PUT", path: "/courses/CS101", body: `{"profile":"basic"}`,
+			wantCode: http.StatusOK, wantBody: `"course updated successfully"`},
+		{name: "course profile saved", method: "GET", path: "/courses/CS101", wantCode: http.StatusOK, wantBody: `"profile":"basic"`},
+		{name: "empty course update", method: "PUT", path: "/courses/CS101", body: `{}`, wantCode: http.StatusBadRequest},
+
+		{name: "enroll unknown course", method: "POST", path: "/students/20240001/enroll", body: `{"course_id":"NOPE","status":"active"}`,
+			wantCode: http.StatusBadRequest},
+		{name: "enroll", method: "POST", path: "/students/20240001/enroll", body: `{"course_id":"CS101","status":"active"}`,
+			wantCode: http.StatusCreated, wantBody: `"course_id":"CS101"`},
+		{name: "list enrollments", method: "GET", path: "/students/20240001/enrollments", wantCode: http.StatusOK, wantBody: "CS101"},
+		{name: "unenroll", method: "DELETE", path: "/students/20240001/enroll/CS101", wantCode: http.StatusOK},
+		{name: "unenroll twice", method: "DELETE", path: "/students/20240001/enroll/CS101", wantCode: http.StatusInternalServerError},
+		{name: "delete course", method: "DELETE", path: "/courses/CS101", wantCode: http.StatusOK},
+		{name: "deleted course", method: "GET", path: "/courses/CS101", wantCode: http.StatusNotFound},
+	})
+}
+
+func TestProfileCatalogHandlers(t *testing.T) {
+	runSteps(t, []handlerStep{
+		{name: "builtin profiles", method: "GET", path: "/profiles", wantCode: http.StatusOK, wantBody: `"name":"basic"`},
+		{name: "invalid limits", method: "POST", path: "/profiles", body: `{"name":"gpu","limits":{"cores":-5}}`, wantCode: http.StatusBadRequest},
+		{name: "create", method: "POST", path: "/profiles", body: `{"name":"gpu","limits":{"cores":32,"ramMB":65536}}`,
+			wantCode: http.StatusCreated, wantBody: `"version":1`},
+		{name: "duplicate", method: "POST", path: "/profiles", body: `{"name":"gpu","limits":{"cores":1}}`, wantCode: http.StatusConflict},
+		{name: "update", method: "PUT", path: "/profiles/gpu", body: `{"version":1,"limits":{"cores":48,"ramMB":65536}}`,
+			wantCode: http.StatusOK, wantBody: `"version":2`},
+		{name: "stale update", method: "PUT", path: "/profiles/gpu", body: `{"version":1,"description":"x"}`,
+			wantCode: http.StatusConflict, wantBody: `"current_version":2`},
+		{name: "versions", method: "GET", path: "/profiles/gpu/versions", wantCode: http.StatusOK, wantBody: `"cores":48`},
+		{name: "baseline rule", method: "PUT", path: "/baselines/department/ee", body: `{"profile":"gpu"}`, wantCode: http.StatusOK},
+		{name: "baseline with unknown profile", method: "PUT", path: "/baselines/department/me", body: `{"profile":"nope"}`,
+			wantCode: http.StatusBadRequest},
+		{name: "delete in use", method: "DELETE", path: "/profiles/gpu", wantCode: http.StatusConflict},
+		{name: "drop rule", method: "DELETE", path: "/baselines/department/ee", wantCode: http.StatusOK},
+		{name: "delete", method: "DELETE", path: "/profiles/gpu", wantCode: http.StatusOK},
+		{name: "deleted", method: "GET", path: "/profiles/gpu", wantCode: http.StatusNotFound},
+		{name: "baseline profile is protected", method: "DELETE", path: "/profiles/basic", wantCode: http.StatusConflict},
+	})
+}
+
+// TestCourseProfileChangeEnqueuesJobs checks that a profile change leaves one
+// reconcile job per enrolled student, and an unchanged profile none.
+func TestCourseProfileChangeEnqueuesJobs(t *testing.T) {
+	db := database.NewMemoryStore()
+	start := time.Now().AddDate(0, -1, 0)
+	course := &models.Course{CourseID: "CS101", Title: "OS", Department: "cs", Semester: "2024-1",
+		StartAt: start, EndAt: start.AddDate(0, 4, 0), ProfileName: "lab"}
+	if err := db.CreateCourse(course); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"20240002", "20240001"} {
+		if err := db.CreateStudent(&models.Student{StudentID: id, Name: id, Email: id + "@example.com", Department: "cs"}); err != nil {
+			t.Fatal(err)
+		}
+		enrollment := &models.Enrollment{StudentID: id, CourseID: "CS101", Status: "active", StartAt: course.StartAt, EndAt: course.EndAt}
+		if err := db.EnrollStudent(enrollment); err != nil {
+			t.Fatal(err)
+		}
+	}
+	courses := NewCourseHandler(db, true)
+
+	put := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		courses.ServeHTTP(rec, httptest.NewRequest("PUT", "/courses/CS101", strings.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("PUT %s = %d (%s)", body, rec.Code, rec.Body)
+		}
+		return rec
+	}
+
+	rec := put(`{"profile":"basic"}`)
+	if !strings.Contains(rec.Body.String(), `"reconcile_job_ids":[1,2]`) {
+		t.Fatalf("body %s does not list the enqueued jobs", rec.Body)
+	}
+	jobs, err := db.ListReconcileJobs("", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("got %d jobs, want 2", len(jobs))
+	}
+	for _, job := range jobs {
+		if job.CourseID != "CS101" || job.Reason != "course_profile" || job.Status != models.ReconcileJobPending {
+			t.Fatalf("unexpected job %+v", job)
+		}
+	}
+	if got, _ := db.GetCourse("CS101"); got.ProfileName != "basic" {
+		t.Fatalf("profile = %s, want basic", got.ProfileName)
+	}
+
+	// 같은 프로파일로 다시 저장하면 쿼타가 바뀌지 않으므로 작업도 없다
+	rec = put(`{"profile":"basic","title":"Operating Systems"}`)
+	if strings.Contains(rec.Body.String(), "reconcile_job_ids") {
+		t.Fatalf("unchanged profile enqueued jobs: %s", rec.Body)
+	}
+	if jobs, _ := db.ListReconcileJobs("", 10); len(jobs) != 2 {
+		t.Fatalf("got %d jobs after unchanged profile, want 2", len(jobs))
+	}
+}
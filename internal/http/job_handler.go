@@ -0,0 +1,113 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/models"
+	"example.com/quotaapi/internal/services"
+)
+
+// defaultJobListLimit is how many jobs GET /jobs returns unless ?limit= is given.
+const defaultJobListLimit = 50
+
+// JobHandler exposes the reconcile job queue
+type JobHandler struct {
+	jobs   database.ReconcileJobStore
+	worker *services.ReconcileJobWorker
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(jobs database.ReconcileJobStore, worker *services.ReconcileJobWorker) *JobHandler {
+	return &JobHandler{jobs: jobs, worker: worker}
+}
+
+// ServeHTTP handles job requests
+func (h *JobHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/jobs":
+		h.listJobs(w, r)
+	case r.Method == "POST" && strings.HasPrefix(r.URL.Path, "/jobs/") && strings.HasSuffix(r.URL.Path, "/retry"):
+		h.retryJob(w, r)
+	case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/jobs/"):
+		h.getJob(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// listJobs lists recent jobs, newest first (?status=pending|running|succeeded|dead)
+func (h *JobHandler) listJobs(w http.ResponseWriter, r *http.Request) {
+	status := models.ReconcileJobStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", models.ReconcileJobPending, models.ReconcileJobRunning, models.ReconcileJobSucceeded, models.ReconcileJobDead:
+	default:
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "status must be pending, running, succeeded or dead"})
+		return
+	}
+	limit := defaultJobListLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 500 {
+			WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "limit must be between 1 and 500"})
+			return
+		}
+		limit = n
+	}
+
+	jobs, err := h.jobs.ListReconcileJobs(status, limit)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to list jobs: " + err.Error()})
+		return
+	}
+	if jobs == nil {
+		jobs = []models.ReconcileJob{}
+	}
+	WriteJSON(w, http.StatusOK, jobs)
+}
+
+// getJob returns one job
+func (h *JobHandler) getJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/jobs/"), 10, 64)
+	if err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid job id"})
+		return
+	}
+
+	job, err := h.jobs.GetReconcileJob(id)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, job)
+}
+
+// retryJob requeues a dead job
+func (h *JobHandler) retryJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/jobs/"), "/retry"), 10, 64)
+	if err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid job id"})
+		return
+	}
+
+	job, err := h.worker.RetryDeadJob(id)
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, job)
+}
+
+func writeJobError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, database.ErrReconcileJobNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrJobNotDead):
+		status = http.StatusConflict
+	}
+	WriteJSON(w, status, map[string]any{"error": err.Error()})
+}
@@ -0,0 +1,32 @@
+package http
+
+import (
+	"net/http"
+
+	"example.com/quotaapi/internal/services"
+)
+
+// LeaderHandler reports which replica runs the background tasks
+type LeaderHandler struct {
+	elector *services.LeaderElector
+}
+
+// NewLeaderHandler creates a new leader handler
+func NewLeaderHandler(elector *services.LeaderElector) *LeaderHandler {
+	return &LeaderHandler{elector: elector}
+}
+
+// ServeHTTP handles GET /leader
+func (h *LeaderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet || r.URL.Path != "/leader" {
+		http.NotFound(w, r)
+		return
+	}
+
+	status, err := h.elector.Status()
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to get leader status: " + err.Error()})
+		return
+	}
+	WriteJSON(w, http.StatusOK, status)
+}
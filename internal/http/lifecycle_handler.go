@@ -0,0 +1,42 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"example.com/quotaapi/internal/services"
+)
+
+// LifecycleHandler runs the end-of-course lifecycle on demand
+type LifecycleHandler struct {
+	lifecycleService *services.LifecycleService
+}
+
+// NewLifecycleHandler creates a new lifecycle handler
+func NewLifecycleHandler(lifecycleService *services.LifecycleService) *LifecycleHandler {
+	return &LifecycleHandler{lifecycleService: lifecycleService}
+}
+
+// ServeHTTP handles POST /lifecycle/run
+func (h *LifecycleHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/lifecycle/run" {
+		http.NotFound(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	defer cancel()
+
+	result, err := h.lifecycleService.RunOnce(ctx)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrLifecycleRunning) {
+			status = http.StatusConflict
+		}
+		WriteJSON(w, status, map[string]any{"error": "failed to run lifecycle: " + err.Error()})
+		return
+	}
+	WriteJSON(w, http.StatusOK, result)
+}
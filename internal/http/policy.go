@@ -0,0 +1,168 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Role is the application-level role derived from a caller's Keystone roles.
+type Role string
+
+const (
+	RoleAdmin      Role = "admin"
+	RoleInstructor Role = "instructor"
+	RoleTA         Role = "ta"
+	RoleStudent    Role = "student"
+)
+
+// ownership restricts a student-role caller to their own resources.
+type ownership int
+
+const (
+	ownNone           ownership = iota
+	ownStudentPath              // {id} 경로 세그먼트가 호출자 본인의 student_id 여야 함
+	ownProjectIDQuery           // ?projectId= 가 호출자 토큰의 프로젝트여야 함
+)
+
+// Rule grants the listed roles access to Method+Path. Path 세그먼트의
+// "{id}" 는 임의의 한 세그먼트, 마지막 "*" 는 나머지 전체와 매칭된다.
+type Rule struct {
+	Method string
+	Path   string
+	Public bool // 인증 없이 허용
+	Roles  []Role
+	Owner  ownership // RoleStudent 에만 적용
+}
+
+var (
+	allRoles = []Role{RoleAdmin, RoleInstructor, RoleTA, RoleStudent}
+	staff    = []Role{RoleAdmin, RoleInstructor, RoleTA}
+	managers = []Role{RoleAdmin, RoleInstructor}
+	admins   = []Role{RoleAdmin}
+)
+
+// DefaultPolicy is the route → role table. 먼저 매칭되는 규칙이 적용되고,
+// 어떤 규칙에도 없는 경로는 admin 만 호출할 수 있다.
+var DefaultPolicy = []Rule{
+	{Method: http.MethodGet, Path: "/healthz", Public: true},
+	{Method: http.MethodGet, Path: "/auth/check", Roles: allRoles},
+	{Method: http.MethodGet, Path: "/leader", Roles: staff},
+	{Method: http.MethodGet, Path: "/capacity", Roles: staff},
+
+	// 쿼타/프로비저닝
+	{Method: http.MethodGet, Path: "/quota/current", Roles: allRoles, Owner: ownProjectIDQuery},
+	{Method: http.MethodPost, Path: "/quota/apply", Roles: admins},
+	{Method: http.MethodPost, Path: "/quota/applyProfile", Roles: managers},
+	{Method: http.MethodPost, Path: "/provision/server", Roles: managers},
+
+	// 학생/수강
+	{Method: http.MethodGet, Path: "/students", Roles: staff},
+	{Method: http.MethodPost, Path: "/students", Roles: managers},
+	{Method: http.MethodGet, Path: "/students/{id}", Roles: allRoles, Owner: ownStudentPath},
+	{Method: http.MethodGet, Path: "/students/{id}/enrollments", Roles: allRoles, Owner: ownStudentPath},
+	{Method: http.MethodPost, Path: "/students/{id}/enroll", Roles: managers},
+	{Method: http.MethodPost, Path: "/students/{id}/bootstrap", Roles: managers},
+	{Method: http.MethodPost, Path: "/students/{id}/credentials", Public: true}, // 일회용 조회 토큰으로 인증
+	{Method: http.MethodPost, Path: "/students/{id}/credentials/reset", Roles: managers},
+	{Method: http.MethodDelete, Path: "/students/{id}/enroll/{id}", Roles: managers},
+	{Method: http.MethodGet, Path: "/openstack/projects", Roles: staff},
+	{Method: http.MethodGet, Path: "/openstack/projects/{id}", Roles: allRoles, Owner: ownStudentPath},
+
+	// 과목
+	{Method: http.MethodGet, Path: "/courses", Roles: allRoles},
+	{Method: http.MethodGet, Path: "/courses/{id}", Roles: allRoles},
+	{Method: http.MethodPost, Path: "/courses", Roles: managers},
+	{Method: http.MethodPut, Path: "/courses/{id}", Roles: managers},
+	{Method: http.MethodDelete, Path: "/courses/{id}", Roles: admins},
+
+	// 쿼타 프로파일 카탈로그
+	{Method: http.MethodGet, Path: "/profiles", Roles: allRoles},
+	{Method: http.MethodGet, Path: "/profiles/{id}", Roles: allRoles},
+	{Method: http.MethodGet, Path: "/profiles/{id}/versions", Roles: staff},
+	{Method: http.MethodPost, Path: "/profiles", Roles: managers},
+	{Method: http.MethodPut, Path: "/profiles/{id}", Roles: managers},
+	{Method: http.MethodDelete, Path: "/profiles/{id}", Roles: admins},
+
+	// 기본 쿼타 규칙 (학과/cohort/학생별)
+	{Method: http.MethodGet, Path: "/baselines", Roles: staff},
+	{Method: http.MethodPut, Path: "/baselines/{id}/{id}", Roles: managers},
+	{Method: http.MethodDelete, Path: "/baselines/{id}/{id}", Roles: managers},
+
+	// 과목 프로파일 합산 정책 (전역/학과별)
+	{Method: http.MethodGet, Path: "/aggregation-policies", Roles: staff},
+	{Method: http.MethodPut, Path: "/aggregation-policies/global", Roles: admins},
+	{Method: http.MethodDelete, Path: "/aggregation-policies/global", Roles: admins},
+	{Method: http.MethodPut, Path: "/aggregation-policies/department/{id}", Roles: managers},
+	{Method: http.MethodDelete, Path: "/aggregation-policies/department/{id}", Roles: managers},
+
+	// 리콘실
+	{Method: http.MethodPost, Path: "/reconciliation/bulk", Roles: admins},
+	{Method: http.MethodPost, Path: "/reconciliation/students/{id}", Roles: admins},
+	{Method: http.MethodGet, Path: "/reconciliation/status", Roles: staff},
+	{Method: http.MethodGet, Path: "/reconciliation/drift", Roles: staff},
+	{Method: http.MethodPost, Path: "/reconciliation/drift", Roles: admins},
+	{Method: http.MethodGet, Path: "/reconciliation/runs", Roles: staff},
+	{Method: http.MethodGet, Path: "/reconciliation/runs/{id}", Roles: staff},
+
+	// 수강 변경으로 생긴 리콘실 작업 큐
+	{Method: http.MethodGet, Path: "/jobs", Roles: staff},
+	{Method: http.MethodGet, Path: "/jobs/{id}", Roles: staff},
+	{Method: http.MethodPost, Path: "/jobs/{id}/retry", Roles: admins},
+
+	// 과목 종료 후 쿼타 회수 (스케줄러 외 수동 실행)
+	{Method: http.MethodPost, Path: "/lifecycle/run", Roles: admins},
+}
+
+// defaultRule applies to routes missing from the policy table.
+var defaultRule = Rule{Roles: admins}
+
+// matchRule returns the first rule for the request and the captured {id}
+// segments in order.
+func matchRule(rules []Rule, method, path string) (Rule, []string) {
+	segs := splitSegments(path)
+	for _, rule := range rules {
+		if rule.Method != "" && rule.Method != method {
+			continue
+		}
+		if params, ok := matchPath(splitSegments(rule.Path), segs); ok {
+			return rule, params
+		}
+	}
+	return defaultRule, nil
+}
+
+func matchPath(pattern, segs []string) ([]string, bool) {
+	var params []string
+	for i, p := range pattern {
+		if p == "*" {
+			return params, true
+		}
+		if i >= len(segs) {
+			return nil, false
+		}
+		switch {
+		case p == "{id}":
+			params = append(params, segs[i])
+		case p != segs[i]:
+			return nil, false
+		}
+	}
+	return params, len(pattern) == len(segs)
+}
+
+func splitSegments(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func (r Rule) allows(role Role) bool {
+	for _, allowed := range r.Roles {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/models"
+	"example.com/quotaapi/internal/services"
+)
+
+// ProfileHandler serves the quota profile catalog (/profiles)
+type ProfileHandler struct {
+	db       database.ProfileStore
+	capacity *services.CapacityService
+}
+
+func NewProfileHandler(db database.ProfileStore) *ProfileHandler {
+	return &ProfileHandler{db: db}
+}
+
+// WithCapacity checks limit changes against cluster capacity (CAPACITY_*).
+// nil 이면 검사하지 않는다.
+func (h *ProfileHandler) WithCapacity(capacity *services.CapacityService) *ProfileHandler {
+	h.capacity = capacity
+	return h
+}
+
+// profileUpdateResponse adds a capacity warning to the updated profile.
+type profileUpdateResponse struct {
+	*models.Profile
+	CapacityWarning *services.CapacityCheck `json:"capacity_warning,omitempty"`
+}
+
+func (h *ProfileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+
+	switch {
+	case r.Method == "POST" && path == "/profiles":
+		h.createProfile(w, r)
+	case r.Method == "GET" && path == "/profiles":
+		h.listProfiles(w, r)
+	case r.Method == "GET" && strings.HasPrefix(path, "/profiles/") && strings.HasSuffix(path, "/versions"):
+		h.listProfileVersions(w, r)
+	case r.Method == "GET" && strings.HasPrefix(path, "/profiles/"):
+		h.getProfile(w, r)
+	case r.Method == "PUT" && strings.HasPrefix(path, "/profiles/"):
+		h.updateProfile(w, r)
+	case r.Method == "DELETE" && strings.HasPrefix(path, "/profiles/"):
+		h.deleteProfile(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *ProfileHandler) createProfile(w http.ResponseWriter, r *http.Request) {
+	var req models.ProfileCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid json: " + err.Error()})
+		return
+	}
+
+	if err := models.ValidateProfileName(req.Name); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+	if err := req.Limits.Validate(); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid limits: " + err.Error()})
+		return
+	}
+
+	profile := &models.Profile{
+		Name:        req.Name,
+		Description: req.Description,
+		Limits:      req.Limits,
+	}
+	if err := h.db.CreateProfile(profile); err != nil {
+		writeProfileError(w, "failed to create profile", err)
+		return
+	}
+
+	WriteJSON(w, http.StatusCreated, profile)
+}
+
+func (h *ProfileHandler) listProfiles(w http.ResponseWriter, r *http.Request) {
+	profiles, err := h.db.ListProfiles()
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to list profiles: " + err.Error()})
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, profiles)
+}
+
+func (h *ProfileHandler) getProfile(w http.ResponseWriter, r *http.Request) {
+	name, ok := profileNameFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := h.db.GetProfile(name)
+	if err != nil {
+		writeProfileError(w, "failed to get profile", err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, profile)
+}
+
+func (h *ProfileHandler) listProfileVersions(w http.ResponseWriter, r *http.Request) {
+	name, ok := profileNameFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	versions, err := h.db.ListProfileVersions(name)
+	if err != nil {
+		writeProfileError(w, "failed to list profile versions", err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, versions)
+}
+
+func (h *ProfileHandler) updateProfile(w http.ResponseWriter, r *http.Request) {
+	name, ok := profileNameFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ProfileUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid json: " + err.Error()})
+		return
+	}
+	if req.Description == nil && req.Limits == nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "no fields to update"})
+		return
+	}
+
+	profile, err := h.db.GetProfile(name)
+	if err != nil {
+		writeProfileError(w, "failed to get profile", err)
+		return
+	}
+	// version 을 생략하면 방금 읽은 버전을 기준으로 덮어쓴다
+	if req.Version != 0 && req.Version != profile.Version {
+		WriteJSON(w, http.StatusConflict, map[string]any{
+			"error":           database.ErrProfileVersionConflict.Error(),
+			"current_version": profile.Version,
+		})
+		return
+	}
+	if req.Description != nil {
+		profile.Description = *req.Description
+	}
+	if req.Limits != nil {
+		if err := req.Limits.Validate(); err != nil {
+			WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid limits: " + err.Error()})
+			return
+		}
+		profile.Limits = *req.Limits
+	}
+
+	// 한도 변경이 이 프로파일을 쓰는 모든 학생 쿼타에 미치는 영향을 검사
+	resp := profileUpdateResponse{Profile: profile}
+	if req.Limits != nil && h.capacity != nil {
+		check, err := h.capacity.CheckProfile(r.Context(), profile)
+		var ok bool
+		if resp.CapacityWarning, ok = capacityVerdict(w, check, err); !ok {
+			return
+		}
+	}
+
+	if err := h.db.UpdateProfile(profile); err != nil {
+		writeProfileError(w, "failed to update profile", err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *ProfileHandler) deleteProfile(w http.ResponseWriter, r *http.Request) {
+	name, ok := profileNameFromPath(w, r)
+	if !ok {
+		return
+	}
+	if name == models.BaselineProfileName {
+		WriteJSON(w, http.StatusConflict, map[string]any{"error": "the baseline profile cannot be deleted"})
+		return
+	}
+
+	if err := h.db.DeleteProfile(name); err != nil {
+		writeProfileError(w, "failed to delete profile", err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]any{"message": "profile deleted successfully"})
+}
+
+// profileNameFromPath extracts {name} from /profiles/{name}[/versions]
+func profileNameFromPath(w http.ResponseWriter, r *http.Request) (string, bool) {
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) < 3 || pathParts[2] == "" {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid profile name"})
+		return "", false
+	}
+	return pathParts[2], true
+}
+
+// writeProfileError maps catalog errors to HTTP status codes
+func writeProfileError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, database.ErrProfileNotFound):
+		WriteJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+	case errors.Is(err, database.ErrProfileExists),
+		errors.Is(err, database.ErrProfileVersionConflict),
+		errors.Is(err, database.ErrProfileInUse):
+		WriteJSON(w, http.StatusConflict, map[string]any{"error": err.Error()})
+	default:
+		WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": msg + ": " + err.Error()})
+	}
+}
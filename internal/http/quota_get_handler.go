@@ -1,13 +1,14 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
 	osapi "example.com/quotaapi/internal/openstack"
 )
 
 type QuotaGetServer struct {
-	OS *osapi.Clients
+	Quotas *osapi.QuotaService
 }
 
 // GET /quota/current?projectId=xxxx
@@ -23,23 +24,16 @@ func (s *QuotaGetServer) Current(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	nova, nErr := s.OS.GetNovaQuotaDetail(r.Context(), projectID)
-	cind, cErr := s.OS.GetCinderQuotaDetail(r.Context(), projectID)
-	neutron, nErr2 := s.OS.GetNeutronQuotaDetail(r.Context(), projectID)
-	if nErr != nil || cErr != nil || nErr2 != nil {
-		WriteJSON(w, http.StatusBadGateway, map[string]any{
-			"error":   "quota read failed",
-			"nova":    errString(nErr),
-			"cinder":  errString(cErr),
-			"neutron": errString(nErr2),
-		})
+	current, err := s.Quotas.Get(r.Context(), projectID)
+	if err != nil {
+		body := map[string]any{"error": "quota read failed"}
+		var svcErr *osapi.QuotaServiceError
+		if errors.As(err, &svcErr) {
+			body[string(svcErr.Service)] = err.Error()
+		}
+		WriteJSON(w, http.StatusBadGateway, body)
 		return
 	}
 
-	WriteJSON(w, http.StatusOK, map[string]any{
-		"projectId": projectID,
-		"nova":      nova,
-		"cinder":    cind,
-		"neutron":   neutron,
-	})
+	WriteJSON(w, http.StatusOK, current)
 }
@@ -9,7 +9,7 @@ import (
 )
 
 type QuotaApplyServer struct {
-	OS *openstack.Clients
+	Quotas *openstack.QuotaService
 }
 
 func (s *QuotaApplyServer) QuotaApply(w http.ResponseWriter, r *http.Request) {
@@ -17,47 +17,36 @@ func (s *QuotaApplyServer) QuotaApply(w http.ResponseWriter, r *http.Request) {
 
 	var req api.ApplyQuotaRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
 		return
 	}
 	if req.ProjectID == "" {
-		http.Error(w, "missing projectId", http.StatusBadRequest)
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "missing projectId"})
 		return
 	}
 
-	// 1) Nova 적용 (존재하는 항목만)
+	// 준 서비스의 준 항목만 적용 (아무 필드도 없는 서비스는 skipped)
+	var update openstack.QuotaUpdate
 	if req.Nova != nil {
-		if req.Nova.Cores == nil && req.Nova.RAMMB == nil && req.Nova.Instances == nil {
-			// 아무 필드도 없으면 스킵
-		} else {
-			if err := s.OS.ApplyNovaQuota(ctx, req.ProjectID, req.Nova.Cores, req.Nova.RAMMB, req.Nova.Instances); err != nil {
-				http.Error(w, err.Error(), http.StatusBadGateway)
-				return
-			}
-		}
+		update.Nova = openstack.NovaQuotaUpdate(*req.Nova)
 	}
-
-	// 2) Cinder 적용
 	if req.Cinder != nil {
-		if req.Cinder.Volumes == nil && req.Cinder.Snapshots == nil && req.Cinder.Gigabytes == nil {
-			// 아무것도 없으면 스킵
-		} else {
-			if err := s.OS.ApplyCinderQuota(ctx, req.ProjectID, req.Cinder.Volumes, req.Cinder.Snapshots, req.Cinder.Gigabytes); err != nil {
-				http.Error(w, err.Error(), http.StatusBadGateway)
-				return
-			}
-		}
+		update.Cinder = openstack.CinderQuotaUpdate(*req.Cinder)
+	}
+	if req.Neutron != nil {
+		update.Neutron = openstack.NeutronQuotaUpdate(*req.Neutron)
+	}
+
+	resp := api.ApplyQuotaResponse{ProjectID: req.ProjectID, Status: "applied"}
+	results, err := s.Quotas.Apply(ctx, req.ProjectID, update)
+	resp.Results = results
+	if err != nil {
+		resp.Status, resp.Error = "failed", err.Error()
+		WriteJSON(w, http.StatusBadGateway, resp)
+		return
 	}
 
-	// 3) 적용 후 최신 상태를 응답으로 돌려주면 UX가 좋음
-	nova, _ := s.OS.GetNovaQuotaDetail(ctx, req.ProjectID)
-	cinder, _ := s.OS.GetCinderQuotaDetail(ctx, req.ProjectID)
-
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"projectId": req.ProjectID,
-		"nova":      nova,
-		"cinder":    cinder,
-		"status":    "applied",
-	})
+	// 적용 후 최신 상태를 응답으로 돌려주면 UX가 좋음
+	resp.Current, _ = s.Quotas.Get(ctx, req.ProjectID)
+	WriteJSON(w, http.StatusOK, resp)
 }
@@ -2,21 +2,52 @@ package http
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/models"
 	"example.com/quotaapi/internal/services"
 )
 
+// defaultRunListLimit is how many runs GET /reconciliation/runs returns
+// unless ?limit= is given.
+const defaultRunListLimit = 20
+
+// reconcileReq is the optional body of the reconcile endpoints. scope
+// 필드(course_id, department, semester, student_ids)는 bulk 에만 쓰인다.
+type reconcileReq struct {
+	DryRun bool `json:"dryRun"`
+	models.ReconciliationScope
+}
+
+// decodeReconcileReq reads reconcileReq; an empty body means a real run.
+func decodeReconcileReq(r *http.Request) (reconcileReq, error) {
+	var req reconcileReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		return req, err
+	}
+	return req, nil
+}
+
 // ReconciliationHandler handles reconciliation-related HTTP requests
 type ReconciliationHandler struct {
 	reconciliationService *services.QuotaReconciliationService
+	scheduler             *services.ReconciliationScheduler
+	runs                  database.ReconciliationRunStore
 }
 
 // NewReconciliationHandler creates a new reconciliation handler
-func NewReconciliationHandler(reconciliationService *services.QuotaReconciliationService) *ReconciliationHandler {
+func NewReconciliationHandler(reconciliationService *services.QuotaReconciliationService, scheduler *services.ReconciliationScheduler, runs database.ReconciliationRunStore) *ReconciliationHandler {
 	return &ReconciliationHandler{
 		reconciliationService: reconciliationService,
+		scheduler:             scheduler,
+		runs:                  runs,
 	}
 }
 
@@ -25,21 +56,46 @@ func (h *ReconciliationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	switch {
 	case r.Method == "POST" && r.URL.Path == "/reconciliation/bulk":
 		h.runBulkReconciliation(w, r)
+	case r.Method == "POST" && strings.HasPrefix(r.URL.Path, "/reconciliation/students/"):
+		h.reconcileStudent(w, r)
+	case r.Method == "GET" && r.URL.Path == "/reconciliation/drift":
+		h.getDrift(w, r)
+	case r.Method == "POST" && r.URL.Path == "/reconciliation/drift":
+		h.reconcileDrift(w, r)
 	case r.Method == "GET" && r.URL.Path == "/reconciliation/status":
 		h.getReconciliationStatus(w, r)
+	case r.Method == "GET" && r.URL.Path == "/reconciliation/runs":
+		h.listRuns(w, r)
+	case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/reconciliation/runs/"):
+		h.getRun(w, r)
 	default:
 		http.NotFound(w, r)
 	}
 }
 
 // runBulkReconciliation runs bulk quota reconciliation
+// (body {"dryRun": true} 이면 적용 없이 학생별 diff 만 돌려준다)
 func (h *ReconciliationHandler) runBulkReconciliation(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeReconcileReq(r)
+	if err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "bad json: " + err.Error()})
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute) // 5분 타임아웃
 	defer cancel()
 
-	result, err := h.reconciliationService.RunBulkReconciliation(ctx)
+	opts := services.ReconcileOptions{DryRun: req.DryRun, Scope: req.ReconciliationScope}
+	result, err := h.reconciliationService.RunBulkReconciliation(ctx, "manual", opts)
 	if err != nil {
-		WriteJSON(w, http.StatusInternalServerError, map[string]any{
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrReconciliationRunning):
+			status = http.StatusConflict
+		case errors.Is(err, services.ErrInvalidScope):
+			status = http.StatusBadRequest
+		}
+		WriteJSON(w, status, map[string]any{
 			"error": "failed to run bulk reconciliation: " + err.Error(),
 		})
 		return
@@ -48,12 +104,132 @@ func (h *ReconciliationHandler) runBulkReconciliation(w http.ResponseWriter, r *
 	WriteJSON(w, http.StatusOK, result)
 }
 
-// getReconciliationStatus gets the current reconciliation status
+// reconcileStudent reconciles (or with dryRun, plans) one student's quota
+func (h *ReconciliationHandler) reconcileStudent(w http.ResponseWriter, r *http.Request) {
+	studentID := strings.TrimPrefix(r.URL.Path, "/reconciliation/students/")
+	if studentID == "" || strings.Contains(studentID, "/") {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid student id"})
+		return
+	}
+	req, err := decodeReconcileReq(r)
+	if err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "bad json: " + err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	// 학생별 실패는 summary.status 로 보고한다
+	summary, _ := h.reconciliationService.ReconcileQuota(ctx, studentID, "", services.ReconcileOptions{DryRun: req.DryRun})
+	if summary == nil {
+		WriteJSON(w, http.StatusNotFound, map[string]any{"error": "student not found"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, summary)
+}
+
+// getDrift reports projects whose OpenStack quota differs from the desired
+// quota without changing anything
+func (h *ReconciliationHandler) getDrift(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	defer cancel()
+
+	report, err := h.reconciliationService.DetectDrift(ctx)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to detect quota drift: " + err.Error()})
+		return
+	}
+	WriteJSON(w, http.StatusOK, report)
+}
+
+// reconcileDrift reconciles only the drifted projects
+func (h *ReconciliationHandler) reconcileDrift(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	defer cancel()
+
+	report, err := h.reconciliationService.ReconcileDrift(ctx)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrReconciliationRunning) {
+			status = http.StatusConflict
+		}
+		WriteJSON(w, status, map[string]any{"error": "failed to reconcile quota drift: " + err.Error()})
+		return
+	}
+	WriteJSON(w, http.StatusOK, report)
+}
+
+// getReconciliationStatus reports the running run (if any), the last
+// finished run and the next scheduled run
 func (h *ReconciliationHandler) getReconciliationStatus(w http.ResponseWriter, r *http.Request) {
-	// 간단한 상태 정보 반환
-	WriteJSON(w, http.StatusOK, map[string]any{
-		"status":    "ready",
-		"message":   "Reconciliation service is ready",
+	runs, err := h.runs.ListReconciliationRuns(defaultRunListLimit)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to get reconciliation status: " + err.Error()})
+		return
+	}
+
+	body := map[string]any{
+		"status":    "idle",
+		"schedule":  h.scheduler.Schedule().String(),
 		"timestamp": time.Now().UTC(),
-	})
+	}
+	if next := h.scheduler.NextRun(); next != nil {
+		body["next_run_at"] = next.UTC()
+	}
+	for i := range runs {
+		if runs[i].Status == models.ReconciliationRunning {
+			if _, ok := body["current_run"]; !ok {
+				body["status"] = "running"
+				body["current_run"] = runs[i]
+			}
+			continue
+		}
+		body["last_run"] = runs[i]
+		break
+	}
+	WriteJSON(w, http.StatusOK, body)
+}
+
+// listRuns lists recent runs, newest first
+func (h *ReconciliationHandler) listRuns(w http.ResponseWriter, r *http.Request) {
+	limit := defaultRunListLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 500 {
+			WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "limit must be between 1 and 500"})
+			return
+		}
+		limit = n
+	}
+
+	runs, err := h.runs.ListReconciliationRuns(limit)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to list reconciliation runs: " + err.Error()})
+		return
+	}
+	if runs == nil {
+		runs = []models.ReconciliationRun{}
+	}
+	WriteJSON(w, http.StatusOK, runs)
+}
+
+// getRun returns a run with its per-student results
+func (h *ReconciliationHandler) getRun(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/reconciliation/runs/"), 10, 64)
+	if err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid run id"})
+		return
+	}
+
+	run, err := h.runs.GetReconciliationRun(id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrReconciliationRunNotFound) {
+			status = http.StatusNotFound
+		}
+		WriteJSON(w, status, map[string]any{"error": err.Error()})
+		return
+	}
+	WriteJSON(w, http.StatusOK, run)
 }
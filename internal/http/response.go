@@ -10,12 +10,3 @@ func WriteJSON(w http.ResponseWriter, code int, v any) {
     w.WriteHeader(code)
     _ = json.NewEncoder(w).Encode(v)
 }
-
-func errString(err error) any {
-    if err == nil {
-        return nil
-    }
-    return err.Error()
-}
-
-
@@ -3,6 +3,7 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -15,31 +16,65 @@ import (
 )
 
 type StudentHandler struct {
-	db                    *database.Database
-	projectMgr            *openstack.ProjectManager
-	reconciliationService *services.QuotaReconciliationService
+	db                database.Store
+	projectMgr        *openstack.ProjectManager
+	credentialService *services.CredentialService
+	bootstrapService  *services.BootstrapService
+	capacity          *services.CapacityService
 }
 
-func NewStudentHandler(db *database.Database, projectMgr *openstack.ProjectManager) *StudentHandler {
-	reconciliationService := services.NewQuotaReconciliationService(db, projectMgr)
+// NewStudentHandler creates the student handler; reconciler 는 bootstrap 의
+// 쿼타 단계가 쓴다 (OpenStack 이 없으면 nil).
+func NewStudentHandler(db database.Store, projectMgr *openstack.ProjectManager, reconciler *services.QuotaReconciliationService) *StudentHandler {
 	return &StudentHandler{
-		db:                    db,
-		projectMgr:            projectMgr,
-		reconciliationService: reconciliationService,
+		db:                db,
+		projectMgr:        projectMgr,
+		credentialService: services.NewCredentialService(db, projectMgr),
+		bootstrapService:  services.NewBootstrapService(db, projectMgr, reconciler),
 	}
 }
 
+// WithCapacity checks enrollments against cluster capacity (CAPACITY_*).
+func (h *StudentHandler) WithCapacity(capacity *services.CapacityService) *StudentHandler {
+	h.capacity = capacity
+	return h
+}
+
+// enrollmentResponse adds the queued reconcile job to an enrollment change.
+// 작업 상태는 GET /jobs/{id} 로 확인한다.
+type enrollmentResponse struct {
+	*models.Enrollment
+	ReconcileJobID  int64                   `json:"reconcile_job_id,omitempty"`
+	CapacityWarning *services.CapacityCheck `json:"capacity_warning,omitempty"`
+}
+
+// studentCreateResponse adds the one-time credential retrieval token to
+// the created student. 토큰은 이 응답에서만 볼 수 있다.
+type studentCreateResponse struct {
+	*models.Student
+	*services.RetrievalToken
+}
+
 func (h *StudentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	path := r.URL.Path
 	fmt.Printf("DEBUG: StudentHandler received request: %s %s\n", r.Method, path)
 
 	switch {
+	case r.Method == "POST" && strings.HasSuffix(path, "/bootstrap"):
+		h.retryBootstrap(w, r)
+	case r.Method == "POST" && strings.HasSuffix(path, "/credentials/reset"):
+		h.resetCredentials(w, r)
+	case r.Method == "POST" && strings.HasSuffix(path, "/credentials"):
+		h.redeemCredentials(w, r)
 	case r.Method == "POST" && path == "/students":
 		fmt.Printf("DEBUG: Routing to createStudent\n")
 		h.createStudent(w, r)
 	case r.Method == "GET" && path == "/students":
 		fmt.Printf("DEBUG: Routing to listStudents\n")
 		h.listStudents(w, r)
+	case r.Method == "GET" && strings.Contains(path, "/enrollments"):
+		fmt.Printf("DEBUG: Routing to getStudentEnrollments\n")
+		h.getStudentEnrollments(w, r)
 	case r.Method == "GET" && strings.HasPrefix(path, "/students/"):
 		fmt.Printf("DEBUG: Routing to getStudent\n")
 		h.getStudent(w, r)
@@ -49,9 +84,6 @@ func (h *StudentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case r.Method == "DELETE" && strings.Contains(path, "/enroll"):
 		fmt.Printf("DEBUG: Routing to unenrollStudent\n")
 		h.unenrollStudent(w, r)
-	case r.Method == "GET" && strings.Contains(path, "/enrollments"):
-		fmt.Printf("DEBUG: Routing to getStudentEnrollments\n")
-		h.getStudentEnrollments(w, r)
 	default:
 		fmt.Printf("DEBUG: No route matched, returning 404\n")
 		http.NotFound(w, r)
@@ -76,6 +108,7 @@ func (h *StudentHandler) createStudent(w http.ResponseWriter, r *http.Request) {
 		Name:              req.Name,
 		Email:             req.Email,
 		Department:        req.Department,
+		Cohort:            req.Cohort,
 		KeystoneProjectID: "", // OpenStack에서 생성될 예정
 		KeystoneUserID:    "", // OpenStack에서 생성될 예정
 		CreatedAt:         time.Now(),
@@ -87,28 +120,22 @@ func (h *StudentHandler) createStudent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 2. OpenStack에 프로젝트 생성 (백그라운드에서 처리)
+	// 2. 학생에게 전달할 일회용 자격 증명 조회 토큰 발급
+	resp := studentCreateResponse{Student: student}
+	if h.projectMgr != nil {
+		token, err := h.credentialService.IssueToken(student.StudentID)
+		if err != nil {
+			fmt.Printf("Warning: Failed to issue credential token for student %s: %v\n", student.StudentID, err)
+		}
+		resp.RetrievalToken = token
+	}
+
+	// 3. OpenStack 프로젝트/사용자/역할/쿼타 생성 (백그라운드 saga, 진행 상태는 GET /students/{id})
 	if h.projectMgr != nil {
-		go func() {
-			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-			defer cancel()
-
-			if err := h.projectMgr.CreateStudentProject(ctx, student); err != nil {
-				fmt.Printf("Warning: Failed to create OpenStack project for student %s: %v\n", student.StudentID, err)
-				return
-			}
-
-			// 프로젝트 ID 업데이트
-			if err := h.db.UpdateStudent(student.StudentID, map[string]interface{}{
-				"keystone_project_id": student.KeystoneProjectID,
-				"keystone_user_id":    student.KeystoneUserID,
-			}); err != nil {
-				fmt.Printf("Warning: Failed to update student with project IDs: %v\n", err)
-			}
-		}()
-	}
-
-	WriteJSON(w, http.StatusCreated, student)
+		go h.runBootstrap(student.StudentID)
+	}
+
+	WriteJSON(w, http.StatusCreated, resp)
 }
 
 func (h *StudentHandler) getStudent(w http.ResponseWriter, r *http.Request) {
@@ -169,23 +196,30 @@ func (h *StudentHandler) enrollStudent(w http.ResponseWriter, r *http.Request) {
 		EndAt:     course.EndAt,   // 과목의 종료일 사용
 	}
 
-	if err := h.db.EnrollStudent(enrollment); err != nil {
-		WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to enroll: " + err.Error()})
-		return
+	// 등록으로 늘어나는 쿼타가 클러스터 용량을 넘는지 먼저 검사
+	resp := enrollmentResponse{Enrollment: enrollment}
+	if h.capacity != nil {
+		check, err := h.capacity.CheckEnrollment(r.Context(), enrollment)
+		var ok bool
+		if resp.CapacityWarning, ok = capacityVerdict(w, check, err); !ok {
+			return
+		}
 	}
 
-	// 학생 프로젝트 크기 조정 및 할당량 재조정
-	if h.reconciliationService != nil {
-		go func() {
-			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-			defer cancel()
-			if err := h.reconciliationService.ReconcileQuota(ctx, studentID, req.CourseID); err != nil {
-				fmt.Printf("Warning: Failed to reconcile quota for student %s after enrollment: %v\n", studentID, err)
-			}
-		}()
+	// 학생 프로젝트 쿼타 재조정은 수강 등록과 같은 트랜잭션에 작업으로 남긴다
+	if h.projectMgr != nil {
+		job := &models.ReconcileJob{StudentID: studentID, CourseID: req.CourseID, Reason: services.JobReasonEnroll}
+		err = h.db.EnrollStudentWithJob(enrollment, job)
+		resp.ReconcileJobID = job.ID
+	} else {
+		err = h.db.EnrollStudent(enrollment)
+	}
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to enroll: " + err.Error()})
+		return
 	}
 
-	WriteJSON(w, http.StatusCreated, enrollment)
+	WriteJSON(w, http.StatusCreated, resp)
 }
 
 func (h *StudentHandler) unenrollStudent(w http.ResponseWriter, r *http.Request) {
@@ -198,23 +232,22 @@ func (h *StudentHandler) unenrollStudent(w http.ResponseWriter, r *http.Request)
 	studentID := pathParts[2]
 	courseID := pathParts[4]
 
-	if err := h.db.UnenrollStudent(studentID, courseID); err != nil {
+	// 수강 해제 후 쿼타 재조정 작업도 같은 트랜잭션에 등록
+	resp := map[string]any{"message": "unenrolled successfully"}
+	var err error
+	if h.projectMgr != nil {
+		job := &models.ReconcileJob{StudentID: studentID, CourseID: courseID, Reason: services.JobReasonUnenroll}
+		err = h.db.UnenrollStudentWithJob(studentID, courseID, job)
+		resp["reconcile_job_id"] = job.ID
+	} else {
+		err = h.db.UnenrollStudent(studentID, courseID)
+	}
+	if err != nil {
 		WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to unenroll: " + err.Error()})
 		return
 	}
 
-	// 수강 해제 후 쿼타 재조정
-	if h.reconciliationService != nil {
-		go func() {
-			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-			defer cancel()
-			if err := h.reconciliationService.ReconcileQuota(ctx, studentID, courseID); err != nil {
-				fmt.Printf("Warning: Failed to reconcile quota for student %s after unenrollment from course %s: %v\n", studentID, courseID, err)
-			}
-		}()
-	}
-
-	WriteJSON(w, http.StatusOK, map[string]any{"message": "unenrolled successfully"})
+	WriteJSON(w, http.StatusOK, resp)
 }
 
 func (h *StudentHandler) getStudentEnrollments(w http.ResponseWriter, r *http.Request) {
@@ -234,6 +267,106 @@ func (h *StudentHandler) getStudentEnrollments(w http.ResponseWriter, r *http.Re
 	WriteJSON(w, http.StatusOK, enrollments)
 }
 
+// bootstrapTimeout bounds one background bootstrap run including retries.
+const bootstrapTimeout = 5 * time.Minute
+
+func (h *StudentHandler) runBootstrap(studentID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), bootstrapTimeout)
+	defer cancel()
+	if _, err := h.bootstrapService.Run(ctx, studentID); err != nil {
+		fmt.Printf("Warning: Bootstrap for student %s did not complete: %v\n", studentID, err)
+	}
+}
+
+// retryBootstrap resumes (or restarts after failure) the student's
+// bootstrap saga: POST /students/{id}/bootstrap.
+func (h *StudentHandler) retryBootstrap(w http.ResponseWriter, r *http.Request) {
+	if h.projectMgr == nil {
+		WriteJSON(w, http.StatusServiceUnavailable, map[string]any{"error": "OpenStack not available"})
+		return
+	}
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) != 4 {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid path"})
+		return
+	}
+
+	student, err := h.db.GetStudent(pathParts[2])
+	if err != nil {
+		WriteJSON(w, http.StatusNotFound, map[string]any{"error": "student not found"})
+		return
+	}
+	if student.BootstrapState == models.BootstrapReady {
+		WriteJSON(w, http.StatusOK, student)
+		return
+	}
+
+	go h.runBootstrap(student.StudentID)
+	WriteJSON(w, http.StatusAccepted, student)
+}
+
+// redeemCredentials exchanges a one-time retrieval token for the student's
+// Keystone password: POST /students/{id}/credentials {"token": "..."}.
+// 학생은 아직 Keystone 토큰이 없으므로 조회 토큰 자체로 인증한다.
+func (h *StudentHandler) redeemCredentials(w http.ResponseWriter, r *http.Request) {
+	if h.projectMgr == nil {
+		WriteJSON(w, http.StatusServiceUnavailable, map[string]any{"error": "OpenStack not available"})
+		return
+	}
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) != 4 {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid path"})
+		return
+	}
+
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "missing token"})
+		return
+	}
+
+	cred, err := h.credentialService.Redeem(r.Context(), pathParts[2], req.Token)
+	switch {
+	case errors.Is(err, database.ErrCredentialTokenInvalid):
+		WriteJSON(w, http.StatusForbidden, map[string]any{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrAccountNotReady):
+		WriteJSON(w, http.StatusConflict, map[string]any{"error": err.Error()})
+		return
+	case err != nil:
+		WriteJSON(w, http.StatusBadGateway, map[string]any{"error": "failed to issue credentials: " + err.Error()})
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	WriteJSON(w, http.StatusOK, cred)
+}
+
+// resetCredentials invalidates the student's password and issues a new
+// retrieval token: POST /students/{id}/credentials/reset.
+func (h *StudentHandler) resetCredentials(w http.ResponseWriter, r *http.Request) {
+	if h.projectMgr == nil {
+		WriteJSON(w, http.StatusServiceUnavailable, map[string]any{"error": "OpenStack not available"})
+		return
+	}
+	pathParts := strings.Split(r.URL.Path, "/")
+	if len(pathParts) != 5 {
+		WriteJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid path"})
+		return
+	}
+
+	token, err := h.credentialService.Reset(r.Context(), pathParts[2])
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to reset credentials: " + err.Error()})
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	WriteJSON(w, http.StatusOK, token)
+}
+
 // listOpenStackProjects lists all OpenStack projects
 func (h *StudentHandler) ListOpenStackProjects(w http.ResponseWriter, r *http.Request) {
 	if h.projectMgr == nil {
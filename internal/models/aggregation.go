@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// AggregationPolicyName selects how course profiles are combined on top
+// of a student's baseline.
+type AggregationPolicyName string
+
+const (
+	AggregationSum        AggregationPolicyName = "sum"          // baseline + Σ(과목)
+	AggregationMax        AggregationPolicyName = "max"          // baseline + 항목별 max(과목)
+	AggregationSumWithCap AggregationPolicyName = "sum_with_cap" // min(baseline + Σ(과목), cap 프로파일)
+	AggregationWeighted   AggregationPolicyName = "weighted"     // 항목별 큰 과목부터 weights 를 곱해 합산
+)
+
+// AggregationScope says where an AggregationRule applies.
+type AggregationScope string
+
+const (
+	AggregationScopeGlobal     AggregationScope = "global"
+	AggregationScopeDepartment AggregationScope = "department"
+	// AggregationScopeDefault is reported when no rule exists and the
+	// built-in sum policy was used.
+	AggregationScopeDefault AggregationScope = "default"
+)
+
+// ParseAggregationScope validates a scope name usable in a stored rule.
+func ParseAggregationScope(s string) (AggregationScope, error) {
+	switch scope := AggregationScope(s); scope {
+	case AggregationScopeGlobal, AggregationScopeDepartment:
+		return scope, nil
+	}
+	return "", fmt.Errorf("invalid aggregation scope %q (use global or department)", s)
+}
+
+// AggregationPolicy is a policy together with its parameters.
+type AggregationPolicy struct {
+	Policy     AggregationPolicyName `json:"policy"`
+	CapProfile string                `json:"cap_profile,omitempty"` // sum_with_cap: 상한으로 쓸 카탈로그 프로파일
+	Weights    []float64             `json:"weights,omitempty"`     // weighted: 1번째, 2번째… 과목 가중치 (마지막 값 반복)
+}
+
+// DefaultAggregationPolicy is used when no rule applies.
+var DefaultAggregationPolicy = AggregationPolicy{Policy: AggregationSum}
+
+// Validate checks that the parameters required by the policy are present.
+func (p AggregationPolicy) Validate() error {
+	switch p.Policy {
+	case AggregationSum, AggregationMax:
+		if p.CapProfile != "" || len(p.Weights) > 0 {
+			return fmt.Errorf("policy %s takes no cap_profile or weights", p.Policy)
+		}
+	case AggregationSumWithCap:
+		if p.CapProfile == "" {
+			return fmt.Errorf("policy %s requires cap_profile", p.Policy)
+		}
+		if len(p.Weights) > 0 {
+			return fmt.Errorf("policy %s takes no weights", p.Policy)
+		}
+	case AggregationWeighted:
+		if len(p.Weights) == 0 {
+			return fmt.Errorf("policy %s requires weights", p.Policy)
+		}
+		if p.CapProfile != "" {
+			return fmt.Errorf("policy %s takes no cap_profile", p.Policy)
+		}
+		for _, w := range p.Weights {
+			if w < 0 || w > 1 {
+				return fmt.Errorf("weights must be between 0 and 1, got %v", w)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown aggregation policy %q (use sum, max, sum_with_cap or weighted)", p.Policy)
+	}
+	return nil
+}
+
+// AggregationRule selects the policy globally or for one department.
+// 학과 규칙이 전역 규칙보다 우선한다.
+type AggregationRule struct {
+	Scope AggregationScope `json:"scope"`
+	Value string           `json:"value,omitempty"` // global 이면 빈 문자열
+	AggregationPolicy
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AggregationSource reports which rule chose a student's policy.
+type AggregationSource struct {
+	Scope AggregationScope `json:"scope"`
+	Value string           `json:"value,omitempty"`
+	AggregationPolicy
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// BaselineScope says what a BaselineRule matches on.
+type BaselineScope string
+
+const (
+	BaselineScopeDepartment BaselineScope = "department"
+	BaselineScopeCohort     BaselineScope = "cohort"
+	BaselineScopeStudent    BaselineScope = "student"
+	// BaselineScopeDefault is reported when no rule matched and the
+	// catalog's BaselineProfileName was used. 규칙으로 저장할 수는 없다.
+	BaselineScopeDefault BaselineScope = "default"
+)
+
+// Specificity orders scopes from least (0) to most specific; a student
+// rule beats a cohort rule, which beats a department rule.
+func (s BaselineScope) Specificity() int {
+	switch s {
+	case BaselineScopeDepartment:
+		return 1
+	case BaselineScopeCohort:
+		return 2
+	case BaselineScopeStudent:
+		return 3
+	}
+	return 0
+}
+
+// ParseBaselineScope validates a scope name usable in a stored rule.
+func ParseBaselineScope(s string) (BaselineScope, error) {
+	switch scope := BaselineScope(s); scope {
+	case BaselineScopeDepartment, BaselineScopeCohort, BaselineScopeStudent:
+		return scope, nil
+	}
+	return "", fmt.Errorf("invalid baseline scope %q (use department, cohort or student)", s)
+}
+
+// BaselineRule assigns a catalog profile as the baseline quota for every
+// student matching Scope=Value (학과, 학생 구분, 또는 학생 개인).
+type BaselineRule struct {
+	Scope       BaselineScope `json:"scope"`
+	Value       string        `json:"value"`
+	ProfileName string        `json:"profile"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// BaselineRuleRequest represents the request to set a baseline rule
+type BaselineRuleRequest struct {
+	Profile string `json:"profile" validate:"required"`
+}
+
+// BaselineSource reports which rule produced a student's baseline.
+type BaselineSource struct {
+	Scope          BaselineScope `json:"scope"`
+	Value          string        `json:"value,omitempty"`
+	Profile        string        `json:"profile"`
+	ProfileVersion int           `json:"profile_version"`
+}
+
+// Matches reports whether the rule applies to the student.
+func (r BaselineRule) Matches(student *Student) bool {
+	switch r.Scope {
+	case BaselineScopeDepartment:
+		return r.Value == student.Department
+	case BaselineScopeCohort:
+		return student.Cohort != "" && r.Value == student.Cohort
+	case BaselineScopeStudent:
+		return r.Value == student.StudentID
+	}
+	return false
+}
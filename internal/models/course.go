@@ -6,18 +6,18 @@ import (
 
 // Course represents a course in the system
 type Course struct {
-	CourseID     string          `json:"course_id"`
-	Title        string          `json:"title"`
-	Department   string          `json:"department"`
-	Semester     string          `json:"semester"`
-	StartAt      time.Time       `json:"start_at"`
-	EndAt        time.Time       `json:"end_at"`
-	QuotaProfile QuotaProfile    `json:"quota_profile"`
-	Defaults     *CourseDefaults `json:"defaults,omitempty"`
-	CreatedAt    time.Time       `json:"created_at"`
+	CourseID    string          `json:"course_id"`
+	Title       string          `json:"title"`
+	Department  string          `json:"department"`
+	Semester    string          `json:"semester"`
+	StartAt     time.Time       `json:"start_at"`
+	EndAt       time.Time       `json:"end_at"`
+	ProfileName string          `json:"profile"` // quota_profiles 카탈로그의 이름
+	Defaults    *CourseDefaults `json:"defaults,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
 }
 
-// QuotaProfile represents the quota settings for a course
+// QuotaProfile is the set of limits carried by a catalog Profile
 type QuotaProfile struct {
 	Instances   int `json:"instances"`
 	Cores       int `json:"cores"`
@@ -27,24 +27,42 @@ type QuotaProfile struct {
 	Ports       int `json:"ports"`
 	FloatingIPs int `json:"floatingIPs"`
 	Snapshots   int `json:"snapshots"`
-}
 
-// Profiles: 사전에 정의된 프로파일 목록
-// - 사용 예: profile=basic | lab
-// - 필요한 경우 향후 설정 파일/DB로 분리 가능
-var Profiles = map[string]QuotaProfile{
-	"basic": {Cores: 8, RAMMB: 16384, Instances: 10, Gigabytes: 100, Volumes: 10, Snapshots: 10, Ports: 10, FloatingIPs: 5},
-	"lab":   {Cores: 16, RAMMB: 32768, Instances: 20, Gigabytes: 200, Volumes: 20, Snapshots: 20, Ports: 20, FloatingIPs: 10},
+	// Neutron 네트워크 자원
+	Networks           int `json:"networks"`
+	Subnets            int `json:"subnets"`
+	Routers            int `json:"routers"`
+	SecurityGroups     int `json:"securityGroups"`
+	SecurityGroupRules int `json:"securityGroupRules"`
+
+	// Nova 부가 자원
+	KeyPairs           int `json:"keyPairs"`
+	ServerGroups       int `json:"serverGroups"`
+	ServerGroupMembers int `json:"serverGroupMembers"`
+	MetadataItems      int `json:"metadataItems"`
+
+	// Cinder 백업과 볼륨 타입별 한도 (예: {"ssd": {"volumes": 5, "gigabytes": 200}}).
+	// 어떤 프로파일에도 없는 타입은 타입별 제한 없이 전체 volumes/gigabytes 만 적용된다.
+	Backups         int                        `json:"backups"`
+	BackupGigabytes int                        `json:"backupGigabytes"`
+	VolumeTypes     map[string]VolumeTypeQuota `json:"volumeTypes,omitempty"`
 }
 
-// GetBasicProfile returns the basic quota profile
-func GetBasicProfile() QuotaProfile {
-	return Profiles["basic"]
+// VolumeTypeQuota limits the volumes of one Cinder volume type.
+type VolumeTypeQuota struct {
+	Volumes   int `json:"volumes"`
+	Gigabytes int `json:"gigabytes"`
 }
 
-// GetLabProfile returns the lab quota profile
-func GetLabProfile() QuotaProfile {
-	return Profiles["lab"]
+// BuiltinProfiles: 카탈로그 초기값 (마이그레이션 6 과 MemoryStore 가 시드로 사용)
+// 실제 조회는 항상 database.ProfileStore 를 통해 한다.
+var BuiltinProfiles = map[string]QuotaProfile{
+	"basic": {Cores: 8, RAMMB: 16384, Instances: 10, Gigabytes: 100, Volumes: 10, Snapshots: 10, Ports: 10, FloatingIPs: 5,
+		Networks: 2, Subnets: 2, Routers: 1, SecurityGroups: 10, SecurityGroupRules: 100,
+		KeyPairs: 10, ServerGroups: 5, ServerGroupMembers: 10, MetadataItems: 128, Backups: 10, BackupGigabytes: 100},
+	"lab": {Cores: 16, RAMMB: 32768, Instances: 20, Gigabytes: 200, Volumes: 20, Snapshots: 20, Ports: 20, FloatingIPs: 10,
+		Networks: 4, Subnets: 4, Routers: 2, SecurityGroups: 20, SecurityGroupRules: 200,
+		KeyPairs: 20, ServerGroups: 10, ServerGroupMembers: 20, MetadataItems: 128, Backups: 20, BackupGigabytes: 200},
 }
 
 // CourseDefaults - 최소 필드만 유지
@@ -60,23 +78,26 @@ type CourseDefaults struct {
 
 // CourseCreateRequest represents the request to create a new course
 type CourseCreateRequest struct {
-	CourseID     string          `json:"course_id" validate:"required"`
-	Title        string          `json:"title" validate:"required"`
-	Department   string          `json:"department" validate:"required"`
-	Semester     string          `json:"semester" validate:"required"`
-	StartAt      string          `json:"start_at" validate:"required"`
-	EndAt        string          `json:"end_at" validate:"required"`
-	QuotaProfile QuotaProfile    `json:"quota_profile" validate:"required"`
-	Defaults     *CourseDefaults `json:"defaults,omitempty"`
+	CourseID   string          `json:"course_id" validate:"required"`
+	Title      string          `json:"title" validate:"required"`
+	Department string          `json:"department" validate:"required"`
+	Semester   string          `json:"semester" validate:"required"`
+	StartAt    string          `json:"start_at" validate:"required"`
+	EndAt      string          `json:"end_at" validate:"required"`
+	Profile    string          `json:"profile" validate:"required"`
+	Defaults   *CourseDefaults `json:"defaults,omitempty"`
+
+	// ExpectedStudents 는 용량 검사에만 쓰인다 (0 이면 생성 시 검사하지 않음)
+	ExpectedStudents int `json:"expected_students,omitempty"`
 }
 
 // CourseUpdateRequest represents the request to update a course
 type CourseUpdateRequest struct {
-	Title        *string         `json:"title,omitempty"`
-	Department   *string         `json:"department,omitempty"`
-	Semester     *string         `json:"semester,omitempty"`
-	StartAt      *string         `json:"start_at,omitempty"`
-	EndAt        *string         `json:"end_at,omitempty"`
-	QuotaProfile *QuotaProfile   `json:"quota_profile,omitempty"`
-	Defaults     *CourseDefaults `json:"defaults,omitempty"`
+	Title      *string         `json:"title,omitempty"`
+	Department *string         `json:"department,omitempty"`
+	Semester   *string         `json:"semester,omitempty"`
+	StartAt    *string         `json:"start_at,omitempty"`
+	EndAt      *string         `json:"end_at,omitempty"`
+	Profile    *string         `json:"profile,omitempty"`
+	Defaults   *CourseDefaults `json:"defaults,omitempty"`
 }
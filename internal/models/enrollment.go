@@ -11,6 +11,23 @@ type Enrollment struct {
 	Status    string    `json:"status"` // active, completed, dropped
 	StartAt   time.Time `json:"start_at"`
 	EndAt     time.Time `json:"end_at"`
+
+	// 과목 종료 후 수명주기 (services.LifecycleService 가 갱신)
+	GraceUntil  *time.Time `json:"grace_until,omitempty"`  // 이 시각까지 completed 여도 과목 쿼타 유지
+	WarnedAt    *time.Time `json:"warned_at,omitempty"`    // 회수 예정 경고를 보낸 시각
+	ReclaimedAt *time.Time `json:"reclaimed_at,omitempty"` // 과목 쿼타를 회수한 시각
+}
+
+// ContributesQuota reports whether the course still adds to the student's
+// quota at now: active 이거나, completed 이지만 유예 기간이 남은 경우.
+func (e Enrollment) ContributesQuota(now time.Time) bool {
+	switch e.Status {
+	case "active":
+		return true
+	case "completed":
+		return e.GraceUntil != nil && now.Before(*e.GraceUntil)
+	}
+	return false
 }
 
 // EnrollmentCreateRequest represents the request to enroll a student in a course
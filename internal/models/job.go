@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// ReconcileJobStatus is the state of a queued reconciliation job.
+type ReconcileJobStatus string
+
+const (
+	// ReconcileJobPending jobs run once RunAfter has passed (재시도 대기 포함).
+	ReconcileJobPending ReconcileJobStatus = "pending"
+	// ReconcileJobRunning jobs are leased by a worker until LockedUntil;
+	// 워커가 죽으면 lease 가 끝난 뒤 다른 워커가 다시 가져간다.
+	ReconcileJobRunning   ReconcileJobStatus = "running"
+	ReconcileJobSucceeded ReconcileJobStatus = "succeeded"
+	// ReconcileJobDead jobs used up their attempts and wait for a manual retry.
+	ReconcileJobDead ReconcileJobStatus = "dead"
+)
+
+// ReconcileJob is one durable request to reconcile a student's quota,
+// written together with the enrollment change that caused it.
+type ReconcileJob struct {
+	ID          int64              `json:"id"`
+	StudentID   string             `json:"student_id"`
+	CourseID    string             `json:"course_id,omitempty"`
+	Reason      string             `json:"reason"` // enroll, unenroll
+	Status      ReconcileJobStatus `json:"status"`
+	Attempts    int                `json:"attempts"`
+	LastError   string             `json:"last_error,omitempty"`
+	RunAfter    time.Time          `json:"run_after"`
+	LockedUntil *time.Time         `json:"locked_until,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	FinishedAt  *time.Time         `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// LeaderLease records which server replica currently holds a named
+// leadership role. 만료 시각이 지나면 다른 레플리카가 가져갈 수 있다.
+type LeaderLease struct {
+	Name       string    `json:"name"`
+	Holder     string    `json:"holder"`
+	AcquiredAt time.Time `json:"acquired_at"` // 현재 holder 가 처음 가져간 시각
+	RenewedAt  time.Time `json:"renewed_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
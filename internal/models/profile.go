@@ -0,0 +1,103 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// BaselineProfileName is the catalog profile every student project gets
+// before course profiles are added on top.
+const BaselineProfileName = "basic"
+
+// maxProfileValue bounds each limit so that summing several course
+// profiles cannot overflow an int32 quota field on the OpenStack side.
+const maxProfileValue = 1 << 24
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// Profile is a named, versioned entry in the quota profile catalog.
+// Version 은 1에서 시작해 수정할 때마다 1씩 증가한다.
+type Profile struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Version     int          `json:"version"`
+	Limits      QuotaProfile `json:"limits"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// ProfileVersion is an immutable snapshot of a profile at one version.
+type ProfileVersion struct {
+	Name        string       `json:"name"`
+	Version     int          `json:"version"`
+	Description string       `json:"description,omitempty"`
+	Limits      QuotaProfile `json:"limits"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// ProfileCreateRequest represents the request to create a new profile
+type ProfileCreateRequest struct {
+	Name        string       `json:"name" validate:"required"`
+	Description string       `json:"description,omitempty"`
+	Limits      QuotaProfile `json:"limits" validate:"required"`
+}
+
+// ProfileUpdateRequest represents the request to update a profile.
+// Version 을 주면 현재 버전과 다를 때 충돌(409)로 거부한다.
+type ProfileUpdateRequest struct {
+	Description *string       `json:"description,omitempty"`
+	Limits      *QuotaProfile `json:"limits,omitempty"`
+	Version     int           `json:"version,omitempty"`
+}
+
+// ValidateProfileName checks that name is usable as a catalog key.
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q (lowercase letters, digits, '-' or '_', max 63 chars)", name)
+	}
+	return nil
+}
+
+// Validate checks every limit is within [0, maxProfileValue].
+func (q QuotaProfile) Validate() error {
+	type field struct {
+		name  string
+		value int
+	}
+	fields := []field{
+		{"instances", q.Instances},
+		{"cores", q.Cores},
+		{"ramMB", q.RAMMB},
+		{"volumes", q.Volumes},
+		{"gigabytes", q.Gigabytes},
+		{"ports", q.Ports},
+		{"floatingIPs", q.FloatingIPs},
+		{"snapshots", q.Snapshots},
+		{"networks", q.Networks},
+		{"subnets", q.Subnets},
+		{"routers", q.Routers},
+		{"securityGroups", q.SecurityGroups},
+		{"securityGroupRules", q.SecurityGroupRules},
+		{"keyPairs", q.KeyPairs},
+		{"serverGroups", q.ServerGroups},
+		{"serverGroupMembers", q.ServerGroupMembers},
+		{"metadataItems", q.MetadataItems},
+		{"backups", q.Backups},
+		{"backupGigabytes", q.BackupGigabytes},
+	}
+	for name, t := range q.VolumeTypes {
+		if name == "" || len(name) > 255 {
+			return fmt.Errorf("invalid volume type name %q", name)
+		}
+		fields = append(fields,
+			field{"volumeTypes." + name + ".volumes", t.Volumes},
+			field{"volumeTypes." + name + ".gigabytes", t.Gigabytes})
+	}
+	for _, f := range fields {
+		if f.value < 0 || f.value > maxProfileValue {
+			return fmt.Errorf("%s must be between 0 and %d, got %d", f.name, maxProfileValue, f.value)
+		}
+	}
+	return nil
+}
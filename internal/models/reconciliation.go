@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ReconciliationRunStatus is the state of a bulk reconciliation run.
+type ReconciliationRunStatus string
+
+const (
+	ReconciliationRunning   ReconciliationRunStatus = "running"
+	ReconciliationCompleted ReconciliationRunStatus = "completed"
+	// ReconciliationFailed means the run stopped before every student was
+	// processed (취소, 프로세스 재시작 등). 학생별 실패는 run 을 실패시키지 않는다.
+	ReconciliationFailed ReconciliationRunStatus = "failed"
+)
+
+// ReconciliationRun is one persisted bulk reconciliation.
+type ReconciliationRun struct {
+	ID             int64                   `json:"id"`
+	Trigger        string                  `json:"trigger"`         // manual, scheduled, drift
+	Scope          *ReconciliationScope    `json:"scope,omitempty"` // nil 이면 전체 학생
+	Status         ReconciliationRunStatus `json:"status"`
+	Holder         string                  `json:"holder,omitempty"` // 실행 중인 프로세스의 bulk lease holder
+	TotalStudents  int                     `json:"total_students"`
+	Processed      int                     `json:"processed"`
+	SuccessCount   int                     `json:"success_count"`
+	OverQuotaCount int                     `json:"over_quota_pending_count"`
+	FailedCount    int                     `json:"failed_count"`
+	PendingCount   int                     `json:"pending_count"`
+	Summary        string                  `json:"summary,omitempty"`
+	Error          string                  `json:"error,omitempty"`
+	StartedAt      time.Time               `json:"started_at"`
+	FinishedAt     *time.Time              `json:"finished_at,omitempty"`
+
+	Results []ReconciliationResult `json:"results,omitempty"`
+}
+
+// ReconciliationScope limits a bulk run to a subset of students. 여러
+// 조건을 주면 모두 만족하는 학생만 대상이 된다.
+type ReconciliationScope struct {
+	CourseID   string   `json:"course_id,omitempty"`  // 이 과목을 수강(했던) 학생
+	Department string   `json:"department,omitempty"` // 학생의 학과
+	Semester   string   `json:"semester,omitempty"`   // 이 학기 과목을 수강(했던) 학생
+	StudentIDs []string `json:"student_ids,omitempty"`
+}
+
+// IsZero reports whether the scope selects every student.
+func (s ReconciliationScope) IsZero() bool {
+	return s.CourseID == "" && s.Department == "" && s.Semester == "" && len(s.StudentIDs) == 0
+}
+
+// ReconciliationResult is the persisted outcome for one student of a run.
+type ReconciliationResult struct {
+	StudentID    string          `json:"student_id"`
+	Status       string          `json:"status"`
+	ErrorMessage string          `json:"error_message,omitempty"`
+	Detail       json.RawMessage `json:"detail"` // services.StudentQuotaSummary
+	CreatedAt    time.Time       `json:"created_at"`
+}
@@ -8,9 +8,65 @@ type Student struct {
 	Name              string    `json:"name"`
 	Email             string    `json:"email"`
 	Department        string    `json:"department"`
+	Cohort            string    `json:"cohort,omitempty"` // 예: graduate, undergraduate (기본 쿼타 규칙에 사용)
 	KeystoneUserID    string    `json:"keystone_user_id,omitempty"`
 	KeystoneProjectID string    `json:"keystone_project_id,omitempty"`
 	CreatedAt         time.Time `json:"created_at"`
+
+	// 기존 Keystone 자원을 이름으로 찾아 연결했는지(true) 새로 만들었는지(false)
+	ProjectAdopted bool `json:"keystone_project_adopted"`
+	UserAdopted    bool `json:"keystone_user_adopted"`
+
+	// Keystone 자원 생성 진행 상태 (services.BootstrapService 가 갱신)
+	BootstrapState     BootstrapState `json:"bootstrap_state"`
+	BootstrapError     string         `json:"bootstrap_error,omitempty"`
+	BootstrapAttempts  int            `json:"bootstrap_attempts"`
+	BootstrapUpdatedAt *time.Time     `json:"bootstrap_updated_at,omitempty"`
+	// Keystone 생성 전에 기록하는 saga 식별자; 같은 값의 표식이 붙은 객체는 이 saga 가 만든 것
+	BootstrapSagaID string `json:"-"`
+
+	// 마지막 쿼타 리콘실 결과 (services.QuotaReconciliationService 가 갱신)
+	QuotaState    QuotaState     `json:"quota_state,omitempty"`
+	QuotaBlockers []QuotaBlocker `json:"quota_blockers,omitempty"`
+}
+
+// QuotaState is the outcome of the last quota reconciliation of a student.
+type QuotaState string
+
+const (
+	QuotaStateInSync QuotaState = "in_sync"
+	// QuotaStateOverQuotaPending means the target quota is below current
+	// usage; 사용량 이하로는 줄이지 않고 사용량에 맞춰 둔 채 반납을 기다린다.
+	QuotaStateOverQuotaPending QuotaState = "over_quota_pending"
+)
+
+// QuotaBlocker is a resource whose usage keeps the applied limit above the
+// target. MustFree 만큼 반납해야 목표 쿼타로 줄일 수 있다.
+type QuotaBlocker struct {
+	Resource string `json:"resource"` // QuotaProfile 의 JSON 이름 (예: cores, ramMB)
+	Target   int    `json:"target"`
+	InUse    int    `json:"in_use"`
+	Applied  int    `json:"applied"`
+	MustFree int    `json:"must_free"`
+}
+
+// BootstrapState is a step of the per-student Keystone bootstrap saga.
+// 각 값은 "마지막으로 완료된 단계"를 뜻하며 재시작 시 다음 단계부터 이어간다.
+type BootstrapState string
+
+const (
+	BootstrapPending        BootstrapState = "pending"
+	BootstrapProjectCreated BootstrapState = "project_created"
+	BootstrapUserCreated    BootstrapState = "user_created"
+	BootstrapRoleAssigned   BootstrapState = "role_assigned"
+	BootstrapQuotaApplied   BootstrapState = "quota_applied"
+	BootstrapReady          BootstrapState = "ready"
+	BootstrapFailed         BootstrapState = "failed"
+)
+
+// Terminal reports whether no further bootstrap work is scheduled.
+func (s BootstrapState) Terminal() bool {
+	return s == BootstrapReady || s == BootstrapFailed
 }
 
 // StudentCreateRequest represents the request to create a new student
@@ -19,6 +75,7 @@ type StudentCreateRequest struct {
 	Name       string `json:"name" validate:"required"`
 	Email      string `json:"email" validate:"required,email"`
 	Department string `json:"department" validate:"required"`
+	Cohort     string `json:"cohort,omitempty"`
 }
 
 // StudentUpdateRequest represents the request to update a student
@@ -26,4 +83,5 @@ type StudentUpdateRequest struct {
 	Name       string `json:"name,omitempty"`
 	Email      string `json:"email,omitempty" validate:"omitempty,email"`
 	Department string `json:"department,omitempty"`
+	Cohort     string `json:"cohort,omitempty"`
 }
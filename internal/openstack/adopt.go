@@ -0,0 +1,138 @@
+package openstack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"example.com/quotaapi/internal/models"
+	"github.com/gophercloud/gophercloud/v2/openstack/identity/v3/projects"
+	"github.com/gophercloud/gophercloud/v2/openstack/identity/v3/users"
+)
+
+// ManagedByTag marks Keystone projects created by this service.
+const ManagedByTag = "managed-by=quotaapi"
+
+// ErrNotOwned is returned when a Keystone object with the student's
+// expected name exists but carries no ownership marker for that student.
+// 남의 자원일 수 있으므로 채택하지도, 보상 단계에서 지우지도 않는다.
+var ErrNotOwned = errors.New("existing keystone object is not owned by this student")
+
+// ErrNotFound is wrapped by name lookups that found nothing.
+var ErrNotFound = errors.New("not found")
+
+// studentTag is the per-student project tag.
+func studentTag(studentID string) string {
+	return "student=" + studentID
+}
+
+// userOwnershipMarker is appended to user descriptions (users have no tags).
+func userOwnershipMarker(studentID string) string {
+	return fmt.Sprintf("[%s %s]", ManagedByTag, studentTag(studentID))
+}
+
+// sagaTag identifies the bootstrap saga that created an object. 생성 전에
+// DB 에 먼저 기록되므로, 생성 직후 죽었다가 재개해도 채택이 아닌 생성으로 판단할 수 있다.
+func sagaTag(sagaID string) string {
+	return "bootstrap=" + sagaID
+}
+
+// sagaUserMarker is appended to the descriptions of users a saga creates.
+func sagaUserMarker(sagaID string) string {
+	return "[" + sagaTag(sagaID) + "]"
+}
+
+// ownedProject reports whether a project carries this student's markers.
+// 태그 도입 이전에 만든 프로젝트는 "... (<studentID>)" 설명으로 인정한다.
+func ownedProject(p *projects.Project, studentID string) bool {
+	var managed, student bool
+	for _, t := range p.Tags {
+		managed = managed || t == ManagedByTag
+		student = student || t == studentTag(studentID)
+	}
+	return (managed && student) || strings.HasSuffix(p.Description, "("+studentID+")")
+}
+
+func ownedUser(u *users.User, studentID string) bool {
+	return strings.Contains(u.Description, userOwnershipMarker(studentID)) ||
+		strings.HasSuffix(u.Description, "("+studentID+")")
+}
+
+// projectCreatedBySaga reports whether the student's current saga made the project.
+func projectCreatedBySaga(p *projects.Project, sagaID string) bool {
+	return sagaID != "" && slices.Contains(p.Tags, sagaTag(sagaID))
+}
+
+func userCreatedBySaga(u *users.User, sagaID string) bool {
+	return sagaID != "" && strings.Contains(u.Description, sagaUserMarker(sagaID))
+}
+
+// FindStudentUser looks up the student's Keystone user by name.
+func (pm *ProjectManager) FindStudentUser(ctx context.Context, studentID string) (*users.User, error) {
+	did, err := pm.ensureDomainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pages, err := users.List(pm.clients.Identity, users.ListOpts{
+		Name:     StudentUserName(studentID),
+		DomainID: did,
+	}).AllPages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	list, err := users.ExtractUsers(pages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract users: %w", err)
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("user %w for student %s", ErrNotFound, studentID)
+	}
+	return &list[0], nil
+}
+
+// EnsureStudentProject adopts the student's existing project when it
+// carries ownership markers, and otherwise creates it. adopted 는 기존
+// 프로젝트를 연결했는지 여부이며, student.BootstrapSagaID 표식이 붙은
+// 프로젝트는 이번 saga 가 만든 것이므로 채택이 아니다.
+func (pm *ProjectManager) EnsureStudentProject(ctx context.Context, student *models.Student) (project *projects.Project, adopted bool, err error) {
+	existing, err := pm.FindStudentProject(ctx, student.StudentID)
+	switch {
+	case err == nil:
+		if !ownedProject(existing, student.StudentID) {
+			return nil, false, fmt.Errorf("%w: project %s (%s)", ErrNotOwned, existing.Name, existing.ID)
+		}
+		if projectCreatedBySaga(existing, student.BootstrapSagaID) {
+			fmt.Printf("Found project created by this bootstrap: %s (ID: %s)\n", existing.Name, existing.ID)
+			return existing, false, nil
+		}
+		fmt.Printf("Adopted existing project: %s (ID: %s)\n", existing.Name, existing.ID)
+		return existing, true, nil
+	case !errors.Is(err, ErrNotFound):
+		return nil, false, err
+	}
+	project, err = pm.CreateProject(ctx, student)
+	return project, false, err
+}
+
+// EnsureStudentUser adopts or creates the student's Keystone user.
+func (pm *ProjectManager) EnsureStudentUser(ctx context.Context, student *models.Student) (user *users.User, adopted bool, err error) {
+	existing, err := pm.FindStudentUser(ctx, student.StudentID)
+	switch {
+	case err == nil:
+		if !ownedUser(existing, student.StudentID) {
+			return nil, false, fmt.Errorf("%w: user %s (%s)", ErrNotOwned, existing.Name, existing.ID)
+		}
+		if userCreatedBySaga(existing, student.BootstrapSagaID) {
+			fmt.Printf("Found user created by this bootstrap: %s (ID: %s)\n", existing.Name, existing.ID)
+			return existing, false, nil
+		}
+		fmt.Printf("Adopted existing user: %s (ID: %s)\n", existing.Name, existing.ID)
+		return existing, true, nil
+	case !errors.Is(err, ErrNotFound):
+		return nil, false, err
+	}
+	user, err = pm.CreateUser(ctx, student)
+	return user, false, err
+}
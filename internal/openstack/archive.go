@@ -0,0 +1,38 @@
+package openstack
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gophercloud/gophercloud/v2/openstack/compute/v2/servers"
+)
+
+// ShelveProjectServers shelves every server of the project so the cores and
+// RAM it holds are released when its course quota is reclaimed. 이미 shelve
+// 된 서버는 건너뛰며, shelve 한 서버 ID 를 돌려준다.
+func (c *Clients) ShelveProjectServers(ctx context.Context, projectID string) ([]string, error) {
+	pages, err := servers.List(c.ComputeV2, servers.ListOpts{AllTenants: true, TenantID: projectID}).AllPages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list servers of project %s: %w", projectID, err)
+	}
+	list, err := servers.ExtractServers(pages)
+	if err != nil {
+		return nil, fmt.Errorf("extract servers: %w", err)
+	}
+
+	var shelved []string
+	for _, sv := range list {
+		if sv.TenantID != projectID {
+			continue
+		}
+		switch sv.Status {
+		case "SHELVED", "SHELVED_OFFLOADED":
+			continue
+		}
+		if err := servers.Shelve(ctx, c.ComputeV2, sv.ID).ExtractErr(); err != nil {
+			return shelved, fmt.Errorf("shelve server %s: %w", sv.ID, err)
+		}
+		shelved = append(shelved, sv.ID)
+	}
+	return shelved, nil
+}
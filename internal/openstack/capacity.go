@@ -0,0 +1,63 @@
+package openstack
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/schedulerstats"
+	"github.com/gophercloud/gophercloud/v2/openstack/compute/v2/hypervisors"
+)
+
+// ClusterCapacity is the physical capacity of the cloud before any
+// over-commit: up 상태이고 enabled 인 하이퍼바이저와 Cinder 백엔드 풀의 합.
+type ClusterCapacity struct {
+	Hypervisors  int `json:"hypervisors"`
+	VCPUs        int `json:"vcpus"`
+	MemoryMB     int `json:"memoryMB"`
+	StoragePools int `json:"storagePools"`
+	StorageGB    int `json:"storageGB"` // -1 = 용량을 "infinite" 로 보고하는 풀이 있음 (검사하지 않음)
+}
+
+// GetClusterCapacity reads hypervisor and storage pool capacity. 둘 다
+// 관리자 API 이므로 admin 자격으로 만든 Clients 가 필요하다.
+func (c *Clients) GetClusterCapacity(ctx context.Context) (*ClusterCapacity, error) {
+	pages, err := hypervisors.List(c.ComputeV2, nil).AllPages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list hypervisors: %w", err)
+	}
+	hvs, err := hypervisors.ExtractHypervisors(pages)
+	if err != nil {
+		return nil, fmt.Errorf("extract hypervisors: %w", err)
+	}
+
+	capacity := &ClusterCapacity{}
+	for _, hv := range hvs {
+		if hv.State != "up" || hv.Status != "enabled" {
+			continue
+		}
+		capacity.Hypervisors++
+		capacity.VCPUs += hv.VCPUs
+		capacity.MemoryMB += hv.MemoryMB
+	}
+
+	pages, err = schedulerstats.List(c.BlockStorageV3, schedulerstats.ListOpts{Detail: true}).AllPages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list storage pools: %w", err)
+	}
+	pools, err := schedulerstats.ExtractStoragePools(pages)
+	if err != nil {
+		return nil, fmt.Errorf("extract storage pools: %w", err)
+	}
+	var storage float64
+	for _, pool := range pools {
+		capacity.StoragePools++
+		storage += pool.Capabilities.TotalCapacityGB
+	}
+	if math.IsInf(storage, 1) {
+		capacity.StorageGB = -1
+	} else {
+		capacity.StorageGB = int(storage)
+	}
+	return capacity, nil
+}
@@ -0,0 +1,53 @@
+package openstack
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/gophercloud/gophercloud/v2/openstack/identity/v3/users"
+)
+
+// StudentUserName is the Keystone user name for a student.
+func StudentUserName(studentID string) string {
+	return fmt.Sprintf("student-%s-user", studentID)
+}
+
+// StudentProjectName is the Keystone project name for a student.
+func StudentProjectName(studentID string) string {
+	return fmt.Sprintf("student-%s-project", studentID)
+}
+
+// GeneratePassword returns a random password with 192 bits of entropy.
+func GeneratePassword() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// forcePasswordChangeOptions makes Keystone expire admin-set passwords so
+// the user must change them at first login. security_compliance 의
+// change_password_upon_first_use 가 켜진 Keystone 에서만 효과가 있다.
+var forcePasswordChangeOptions = map[users.Option]any{
+	users.IgnoreChangePasswordUponFirstUse: false,
+}
+
+// ResetUserPassword sets a fresh random password on a Keystone user and
+// returns it. 호출자는 반환값을 한 번만 전달하고 저장하지 않아야 한다.
+func (pm *ProjectManager) ResetUserPassword(ctx context.Context, userID string) (string, error) {
+	password, err := GeneratePassword()
+	if err != nil {
+		return "", err
+	}
+	_, err = users.Update(ctx, pm.clients.Identity, userID, users.UpdateOpts{
+		Password: password,
+		Options:  forcePasswordChangeOptions,
+	}).Extract()
+	if err != nil {
+		return "", fmt.Errorf("failed to reset password for user %s: %w", userID, err)
+	}
+	return password, nil
+}
@@ -0,0 +1,56 @@
+package openstacktest
+
+import "net/http"
+
+func (s *Server) handleBlockStorage(w http.ResponseWriter, r *http.Request) {
+	parts := splitPath(r.URL.Path, "/volume/v3")
+	if len(parts) == 0 {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"versions": []map[string]any{{"id": "v3.0", "status": "CURRENT", "version": "3.70", "min_version": "3.0"}},
+		})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case parts[0] == "os-quota-sets" && len(parts) == 2:
+		s.cinderQuota(w, r, parts[1])
+	case parts[0] == "scheduler-stats" && len(parts) == 2 && parts[1] == "get_pools" && r.Method == http.MethodGet:
+		s.cinderPools(w)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) cinderQuota(w http.ResponseWriter, r *http.Request, projectID string) {
+	qs := s.quotaSet(BlockStorage, projectID)
+	switch r.Method {
+	case http.MethodGet:
+		if r.URL.Query().Get("usage") == "true" {
+			body := map[string]any{"id": projectID}
+			for k, v := range qs {
+				body[k] = map[string]any{"limit": v.Limit, "in_use": v.InUse, "reserved": 0, "allocated": 0}
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"quota_set": body})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"quota_set": flatQuota(projectID, qs)})
+	case http.MethodPut:
+		var body struct {
+			QuotaSet map[string]any `json:"quota_set"`
+		}
+		if err := decodeBody(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid quota_set")
+			return
+		}
+		if err := s.updateQuota(BlockStorage, projectID, body.QuotaSet, true); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"quota_set": flatQuota("", qs)})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
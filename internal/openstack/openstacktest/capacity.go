@@ -0,0 +1,102 @@
+package openstacktest
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Hypervisor is a fake Nova compute node reported by os-hypervisors.
+type Hypervisor struct {
+	Name     string
+	VCPUs    int
+	MemoryMB int
+	LocalGB  int
+	Down     bool // state=down, 용량에서 빠져야 함
+}
+
+// StoragePool is a fake Cinder backend pool reported by scheduler-stats.
+// TotalGB 가 음수면 "infinite" 로 보고한다.
+type StoragePool struct {
+	Name    string
+	TotalGB float64
+	FreeGB  float64
+}
+
+// seedCapacity gives the fake two compute nodes and one LVM pool.
+func (s *Server) seedCapacity() {
+	s.hypervisors = []Hypervisor{
+		{Name: "compute-1", VCPUs: 32, MemoryMB: 131072, LocalGB: 500},
+		{Name: "compute-2", VCPUs: 32, MemoryMB: 131072, LocalGB: 500},
+	}
+	s.storagePools = []StoragePool{{Name: "cinder@lvmdriver-1#lvmdriver-1", TotalGB: 2000, FreeGB: 2000}}
+}
+
+// SetHypervisors replaces the compute nodes of the fake.
+func (s *Server) SetHypervisors(hs ...Hypervisor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hypervisors = append([]Hypervisor(nil), hs...)
+}
+
+// SetStoragePools replaces the Cinder pools of the fake.
+func (s *Server) SetStoragePools(ps ...StoragePool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.storagePools = append([]StoragePool(nil), ps...)
+}
+
+// novaHypervisors serves GET /os-hypervisors/detail (microversion < 2.88 형식).
+func (s *Server) novaHypervisors(w http.ResponseWriter) {
+	out := []map[string]any{}
+	for i, h := range s.hypervisors {
+		state := "up"
+		if h.Down {
+			state = "down"
+		}
+		out = append(out, map[string]any{
+			"id":                  i + 1,
+			"hypervisor_hostname": h.Name,
+			"hypervisor_type":     "QEMU",
+			"hypervisor_version":  8002000,
+			"host_ip":             fmt.Sprintf("192.168.0.%d", i+11),
+			"status":              "enabled",
+			"state":               state,
+			"vcpus":               h.VCPUs,
+			"vcpus_used":          0,
+			"memory_mb":           h.MemoryMB,
+			"memory_mb_used":      0,
+			"free_ram_mb":         h.MemoryMB,
+			"local_gb":            h.LocalGB,
+			"local_gb_used":       0,
+			"free_disk_gb":        h.LocalGB,
+			"running_vms":         0,
+			"current_workload":    0,
+			"service":             map[string]any{"host": h.Name, "id": i + 1},
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"hypervisors": out})
+}
+
+// cinderPools serves GET /scheduler-stats/get_pools?detail=true.
+func (s *Server) cinderPools(w http.ResponseWriter) {
+	out := []map[string]any{}
+	for _, p := range s.storagePools {
+		var total, free any = p.TotalGB, p.FreeGB
+		if p.TotalGB < 0 {
+			total, free = "infinite", "infinite"
+		}
+		out = append(out, map[string]any{
+			"name": p.Name,
+			"capabilities": map[string]any{
+				"volume_backend_name": p.Name,
+				"vendor_name":         "Open Source",
+				"driver_version":      "3.0.0",
+				"storage_protocol":    "iSCSI",
+				"total_capacity_gb":   total,
+				"free_capacity_gb":    free,
+				"reserved_percentage": 0,
+			},
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"pools": out})
+}
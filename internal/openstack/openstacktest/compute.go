@@ -0,0 +1,335 @@
+package openstacktest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type flavor struct {
+	ID    string
+	Name  string
+	VCPUs int
+	RAM   int
+	Disk  int
+}
+
+type keypair struct {
+	Name        string
+	Fingerprint string
+	PublicKey   string
+}
+
+type server struct {
+	ID        string
+	Name      string
+	ProjectID string
+	FlavorID  string
+	ImageID   string
+	Status    string
+	NetworkID string
+	FixedIP   string
+	Created   time.Time
+}
+
+// ServerInfo is a snapshot of a fake Nova server.
+type ServerInfo struct {
+	ID        string
+	Name      string
+	ProjectID string
+	Status    string
+	FixedIP   string
+}
+
+// Servers returns every server the fake has booted.
+func (s *Server) Servers() []ServerInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []ServerInfo
+	for _, sv := range s.servers {
+		out = append(out, ServerInfo{ID: sv.ID, Name: sv.Name, ProjectID: sv.ProjectID, Status: sv.Status, FixedIP: sv.FixedIP})
+	}
+	return out
+}
+
+func (s *Server) seedCatalogResources() {
+	s.flavors = []flavor{
+		{ID: "1", Name: "m1.tiny", VCPUs: 1, RAM: 512, Disk: 1},
+		{ID: "2", Name: "m1.small", VCPUs: 1, RAM: 2048, Disk: 20},
+		{ID: "3", Name: "m1.medium", VCPUs: 2, RAM: 4096, Disk: 40},
+	}
+	s.images = []image{{ID: newID(), Name: "cirros-0.6.2-x86_64-disk", Status: "active"}}
+	s.keypairs = []keypair{{Name: "default", Fingerprint: "ab:cd:ef:01:23:45:67:89:ab:cd:ef:01:23:45:67:89", PublicKey: "ssh-ed25519 AAAA fake"}}
+	s.secgroups = []secgroup{{ID: newID(), Name: "default", Description: "Default security group", ProjectID: s.adminProjectID}}
+
+	s.externalNetID = newID()
+	s.networks[s.externalNetID] = &network{ID: s.externalNetID, Name: "public", External: true, ProjectID: s.adminProjectID, CIDR: "172.24.4.0/24"}
+	s.privateNetID = newID()
+	s.networks[s.privateNetID] = &network{ID: s.privateNetID, Name: "private", ProjectID: s.adminProjectID, CIDR: "10.0.0.0/26"}
+
+	s.seedCapacity()
+}
+
+func (s *Server) handleCompute(w http.ResponseWriter, r *http.Request) {
+	parts := splitPath(r.URL.Path, "/compute/v2.1")
+	if len(parts) == 0 {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"version": map[string]any{"id": "v2.1", "status": "CURRENT", "version": "2.96", "min_version": "2.1"},
+		})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tok, _ := s.validToken(r.Header.Get("X-Auth-Token"))
+
+	switch {
+	case parts[0] == "os-quota-sets" && len(parts) >= 2:
+		s.novaQuota(w, r, parts[1:])
+	case parts[0] == "flavors":
+		s.novaFlavors(w, parts[1:])
+	case parts[0] == "os-keypairs" && r.Method == http.MethodGet:
+		out := []map[string]any{}
+		for _, k := range s.keypairs {
+			out = append(out, map[string]any{"keypair": map[string]any{
+				"name": k.Name, "fingerprint": k.Fingerprint, "public_key": k.PublicKey,
+			}})
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"keypairs": out})
+	case parts[0] == "servers":
+		s.novaServers(w, r, tok, parts[1:])
+	case parts[0] == "os-hypervisors" && len(parts) == 2 && parts[1] == "detail" && r.Method == http.MethodGet:
+		s.novaHypervisors(w)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) novaQuota(w http.ResponseWriter, r *http.Request, rest []string) {
+	projectID := rest[0]
+	qs := s.quotaSet(Compute, projectID)
+	switch {
+	case r.Method == http.MethodGet && len(rest) == 2 && rest[1] == "detail":
+		body := map[string]any{"id": projectID}
+		for k, v := range qs {
+			body[k] = map[string]any{"limit": v.Limit, "in_use": v.InUse, "reserved": 0}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"quota_set": body})
+	case r.Method == http.MethodGet && len(rest) == 1:
+		writeJSON(w, http.StatusOK, map[string]any{"quota_set": flatQuota(projectID, qs)})
+	case r.Method == http.MethodPut && len(rest) == 1:
+		var body struct {
+			QuotaSet map[string]any `json:"quota_set"`
+		}
+		if err := decodeBody(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid quota_set")
+			return
+		}
+		if err := s.updateQuota(Compute, projectID, body.QuotaSet, true); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"quota_set": flatQuota("", qs)})
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func flatQuota(projectID string, qs map[string]*QuotaItem) map[string]any {
+	body := map[string]any{}
+	if projectID != "" {
+		body["id"] = projectID
+	}
+	for k, v := range qs {
+		body[k] = v.Limit
+	}
+	return body
+}
+
+func flavorJSON(f flavor) map[string]any {
+	return map[string]any{
+		"id": f.ID, "name": f.Name, "vcpus": f.VCPUs, "ram": f.RAM, "disk": f.Disk,
+		"swap": 0, "rxtx_factor": 1.0, "os-flavor-access:is_public": true,
+		"OS-FLV-EXT-DATA:ephemeral": 0,
+	}
+}
+
+func (s *Server) novaFlavors(w http.ResponseWriter, rest []string) {
+	if len(rest) == 0 || rest[0] == "detail" {
+		out := []map[string]any{}
+		for _, f := range s.flavors {
+			out = append(out, flavorJSON(f))
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"flavors": out})
+		return
+	}
+	if f, ok := s.flavorLocked(rest[0]); ok {
+		writeJSON(w, http.StatusOK, map[string]any{"flavor": flavorJSON(f)})
+		return
+	}
+	writeError(w, http.StatusNotFound, "Flavor "+rest[0]+" could not be found.")
+}
+
+func (s *Server) flavorLocked(id string) (flavor, bool) {
+	for _, f := range s.flavors {
+		if f.ID == id || f.Name == id {
+			return f, true
+		}
+	}
+	return flavor{}, false
+}
+
+func (s *Server) serverJSON(sv *server) map[string]any {
+	netName := "private"
+	if n, ok := s.networks[sv.NetworkID]; ok {
+		netName = n.Name
+	}
+	addrs := []map[string]any{{"addr": sv.FixedIP, "version": 4, "OS-EXT-IPS:type": "fixed"}}
+	for _, f := range s.fips {
+		if p, ok := s.ports[f.PortID]; ok && p.DeviceID == sv.ID {
+			addrs = append(addrs, map[string]any{"addr": f.Address, "version": 4, "OS-EXT-IPS:type": "floating"})
+		}
+	}
+	return map[string]any{
+		"id":        sv.ID,
+		"name":      sv.Name,
+		"status":    sv.Status,
+		"tenant_id": sv.ProjectID,
+		"created":   sv.Created.UTC().Format(time.RFC3339),
+		"updated":   sv.Created.UTC().Format(time.RFC3339),
+		"flavor":    map[string]any{"id": sv.FlavorID},
+		"image":     map[string]any{"id": sv.ImageID},
+		"addresses": map[string]any{netName: addrs},
+		"metadata":  map[string]any{},
+		"links":     []any{},
+	}
+}
+
+func (s *Server) novaServers(w http.ResponseWriter, r *http.Request, tok *token, rest []string) {
+	if len(rest) == 0 && r.Method == http.MethodPost {
+		s.createServer(w, r, tok)
+		return
+	}
+	if len(rest) == 0 || rest[0] == "detail" {
+		tenant := r.URL.Query().Get("tenant_id")
+		out := []map[string]any{}
+		for _, sv := range s.servers {
+			if tenant != "" && sv.ProjectID != tenant {
+				continue
+			}
+			out = append(out, s.serverJSON(sv))
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"servers": out})
+		return
+	}
+
+	sv, ok := s.servers[rest[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Instance "+rest[0]+" could not be found.")
+		return
+	}
+	if len(rest) == 2 && rest[1] == "action" && r.Method == http.MethodPost {
+		s.serverAction(w, r, sv)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"server": s.serverJSON(sv)})
+	case http.MethodDelete:
+		if f, ok := s.flavorLocked(sv.FlavorID); ok {
+			deltas := map[string]int{"instances": -1, "cores": -f.VCPUs, "ram": -f.RAM}
+			if sv.Status == "SHELVED_OFFLOADED" {
+				deltas = map[string]int{"instances": -1}
+			}
+			_ = s.addUsage(Compute, sv.ProjectID, deltas)
+		}
+		for id, p := range s.ports {
+			if p.DeviceID == sv.ID {
+				delete(s.ports, id)
+				_ = s.addUsage(Network, p.ProjectID, map[string]int{"port": -1})
+			}
+		}
+		delete(s.servers, sv.ID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// serverAction supports "shelve", which (like shelve_offload_time=0)
+// immediately offloads the server and releases its cores and RAM.
+func (s *Server) serverAction(w http.ResponseWriter, r *http.Request, sv *server) {
+	var body map[string]any
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid action")
+		return
+	}
+	if _, ok := body["shelve"]; !ok {
+		writeError(w, http.StatusBadRequest, "unsupported action")
+		return
+	}
+	if sv.Status == "SHELVED_OFFLOADED" {
+		writeError(w, http.StatusConflict, "Cannot 'shelve' instance "+sv.ID+" while it is in vm_state shelved_offloaded")
+		return
+	}
+	if f, ok := s.flavorLocked(sv.FlavorID); ok {
+		_ = s.addUsage(Compute, sv.ProjectID, map[string]int{"cores": -f.VCPUs, "ram": -f.RAM})
+	}
+	sv.Status = "SHELVED_OFFLOADED"
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) createServer(w http.ResponseWriter, r *http.Request, tok *token) {
+	var body struct {
+		Server struct {
+			Name      string `json:"name"`
+			ImageRef  string `json:"imageRef"`
+			FlavorRef string `json:"flavorRef"`
+			Networks  []struct {
+				UUID string `json:"uuid"`
+			} `json:"networks"`
+		} `json:"server"`
+	}
+	if err := decodeBody(r, &body); err != nil || body.Server.Name == "" {
+		writeError(w, http.StatusBadRequest, "invalid server")
+		return
+	}
+	f, ok := s.flavorLocked(body.Server.FlavorRef)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Flavor "+body.Server.FlavorRef+" could not be found.")
+		return
+	}
+	netID := s.privateNetID
+	if len(body.Server.Networks) > 0 && body.Server.Networks[0].UUID != "" {
+		netID = body.Server.Networks[0].UUID
+	}
+	if _, ok := s.networks[netID]; !ok {
+		writeError(w, http.StatusBadRequest, "Network "+netID+" could not be found.")
+		return
+	}
+
+	projectID := tok.ProjectID
+	if err := s.addUsage(Compute, projectID, map[string]int{"instances": 1, "cores": f.VCPUs, "ram": f.RAM}); err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err := s.addUsage(Network, projectID, map[string]int{"port": 1}); err != nil {
+		_ = s.addUsage(Compute, projectID, map[string]int{"instances": -1, "cores": -f.VCPUs, "ram": -f.RAM})
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	s.ipSeq++
+	sv := &server{
+		ID: newID(), Name: body.Server.Name, ProjectID: projectID, FlavorID: f.ID,
+		ImageID: body.Server.ImageRef, Status: "ACTIVE", NetworkID: netID,
+		FixedIP: fmt.Sprintf("10.0.0.%d", 10+s.ipSeq), Created: time.Now(),
+	}
+	s.servers[sv.ID] = sv
+	p := &port{ID: newID(), NetworkID: netID, DeviceID: sv.ID, DeviceOwner: "compute:nova", ProjectID: projectID, FixedIP: sv.FixedIP}
+	s.ports[p.ID] = p
+
+	writeJSON(w, http.StatusAccepted, map[string]any{"server": map[string]any{
+		"id": sv.ID, "links": []any{}, "adminPass": "fake-admin-pass", "OS-DCF:diskConfig": "MANUAL",
+	}})
+}
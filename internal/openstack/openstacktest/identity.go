@@ -0,0 +1,766 @@
+package openstacktest
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+type domain struct {
+	ID   string
+	Name string
+}
+
+type project struct {
+	ID          string
+	Name        string
+	Description string
+	DomainID    string
+	Enabled     bool
+	Tags        []string
+}
+
+type user struct {
+	ID          string
+	Name        string
+	Description string
+	DomainID    string
+	Password    string
+	Enabled     bool
+	Options     map[string]any
+}
+
+type role struct {
+	ID   string
+	Name string
+}
+
+type token struct {
+	ID        string
+	UserID    string
+	ProjectID string // 빈 값이면 unscoped
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// Project is a snapshot of a fake Keystone project.
+type Project struct {
+	ID          string
+	Name        string
+	Description string
+	Tags        []string
+}
+
+// User is a snapshot of a fake Keystone user.
+type User struct {
+	ID          string
+	Name        string
+	Description string
+	Password    string
+	Enabled     bool
+	Options     map[string]any
+}
+
+func (s *Server) seed() {
+	s.domains[DefaultDomainID] = &domain{ID: DefaultDomainID, Name: "Default"}
+	for _, name := range []string{"admin", "member", "reader"} {
+		id := newID()
+		s.roles[id] = &role{ID: id, Name: name}
+	}
+
+	s.adminProjectID = newID()
+	s.projects[s.adminProjectID] = &project{
+		ID: s.adminProjectID, Name: AdminProjectName, Description: "Bootstrap project",
+		DomainID: DefaultDomainID, Enabled: true,
+	}
+	s.adminUserID = newID()
+	s.users[s.adminUserID] = &user{
+		ID: s.adminUserID, Name: AdminUsername, DomainID: DefaultDomainID,
+		Password: AdminPassword, Enabled: true,
+	}
+	s.grantLocked(s.adminProjectID, s.adminUserID, "admin")
+
+	s.seedCatalogResources()
+}
+
+// AddUser creates a Keystone user directly and returns its ID.
+func (s *Server) AddUser(name, password string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := newID()
+	s.users[id] = &user{ID: id, Name: name, DomainID: DefaultDomainID, Password: password, Enabled: true}
+	return id
+}
+
+// AddProject creates a Keystone project directly and returns its ID.
+func (s *Server) AddProject(name, description string, tags ...string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := newID()
+	s.projects[id] = &project{ID: id, Name: name, Description: description, DomainID: DefaultDomainID, Enabled: true, Tags: tags}
+	return id
+}
+
+// AddRole creates a Keystone role (e.g. "instructor") and returns its ID.
+// 이미 있으면 기존 ID 를 돌려준다.
+func (s *Server) AddRole(name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.roles {
+		if r.Name == name {
+			return r.ID
+		}
+	}
+	id := newID()
+	s.roles[id] = &role{ID: id, Name: name}
+	return id
+}
+
+// Grant assigns the named role to a user on a project.
+func (s *Server) Grant(projectID, userID, roleName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.grantLocked(projectID, userID, roleName)
+}
+
+func (s *Server) grantLocked(projectID, userID, roleName string) {
+	for _, r := range s.roles {
+		if r.Name == roleName {
+			if s.assignments[projectID] == nil {
+				s.assignments[projectID] = map[string]map[string]bool{}
+			}
+			if s.assignments[projectID][userID] == nil {
+				s.assignments[projectID][userID] = map[string]bool{}
+			}
+			s.assignments[projectID][userID][r.ID] = true
+		}
+	}
+}
+
+// IssueToken mints a token for a user scoped to a project (empty = unscoped)
+// without going through password authentication.
+func (s *Server) IssueToken(userID, projectID string, ttl time.Duration) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issueTokenLocked(userID, projectID, ttl).ID
+}
+
+func (s *Server) issueTokenLocked(userID, projectID string, ttl time.Duration) *token {
+	now := time.Now()
+	t := &token{ID: newID(), UserID: userID, ProjectID: projectID, IssuedAt: now, ExpiresAt: now.Add(ttl)}
+	s.tokens[t.ID] = t
+	return t
+}
+
+// RevokeToken invalidates a token.
+func (s *Server) RevokeToken(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, id)
+}
+
+// Projects returns every project, sorted by name.
+func (s *Server) Projects() []Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Project
+	for _, p := range s.projects {
+		out = append(out, Project{ID: p.ID, Name: p.Name, Description: p.Description, Tags: append([]string(nil), p.Tags...)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Users returns every user, sorted by name.
+func (s *Server) Users() []User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []User
+	for _, u := range s.users {
+		out = append(out, User{ID: u.ID, Name: u.Name, Description: u.Description, Password: u.Password, Enabled: u.Enabled, Options: u.Options})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// RoleNames returns the role names a user holds on a project.
+func (s *Server) RoleNames(projectID, userID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.roleNamesLocked(projectID, userID)
+}
+
+func (s *Server) roleNamesLocked(projectID, userID string) []string {
+	var out []string
+	for rid := range s.assignments[projectID][userID] {
+		if r, ok := s.roles[rid]; ok {
+			out = append(out, r.Name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// validToken must be called with s.mu held.
+func (s *Server) validToken(id string) (*token, bool) {
+	t, ok := s.tokens[id]
+	if !ok || time.Now().After(t.ExpiresAt) {
+		return nil, false
+	}
+	return t, true
+}
+
+func (s *Server) handleIdentity(w http.ResponseWriter, r *http.Request) {
+	parts := splitPath(r.URL.Path, "/identity/v3")
+	if len(parts) == 0 {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"version": map[string]any{"id": "v3.14", "status": "stable", "links": []any{}},
+		})
+		return
+	}
+
+	switch {
+	case parts[0] == "auth" && len(parts) == 2 && parts[1] == "tokens":
+		s.identityTokens(w, r)
+	case parts[0] == "domains":
+		s.identityDomains(w, r)
+	case parts[0] == "roles" && len(parts) == 1:
+		s.identityRoles(w, r)
+	case parts[0] == "role_assignments":
+		s.identityRoleAssignments(w, r)
+	case parts[0] == "projects" && len(parts) == 6 && parts[2] == "users" && parts[4] == "roles":
+		s.identityAssign(w, r, parts[1], parts[3], parts[5])
+	case parts[0] == "projects":
+		s.identityProjects(w, r, parts[1:])
+	case parts[0] == "users":
+		s.identityUsers(w, r, parts[1:])
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) identityTokens(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.createToken(w, r)
+	case http.MethodGet, http.MethodHead:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		t, ok := s.validToken(r.Header.Get("X-Subject-Token"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "Could not recognize Fernet token")
+			return
+		}
+		w.Header().Set("X-Subject-Token", t.ID)
+		writeJSON(w, http.StatusOK, map[string]any{"token": s.tokenBodyLocked(t, !r.URL.Query().Has("nocatalog"))})
+	case http.MethodDelete:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.tokens, r.Header.Get("X-Subject-Token"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+type scopeRef struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Domain struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"domain"`
+}
+
+func (s *Server) createToken(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Auth struct {
+			Identity struct {
+				Methods  []string `json:"methods"`
+				Password struct {
+					User struct {
+						scopeRef
+						Password string `json:"password"`
+					} `json:"user"`
+				} `json:"password"`
+				Token struct {
+					ID string `json:"id"`
+				} `json:"token"`
+			} `json:"identity"`
+			Scope *struct {
+				Project *scopeRef `json:"project"`
+			} `json:"scope"`
+		} `json:"auth"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, "malformed auth request")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var u *user
+	ident := body.Auth.Identity
+	switch {
+	case contains(ident.Methods, "password"):
+		pu := ident.Password.User
+		for _, cand := range s.users {
+			if (pu.ID != "" && cand.ID == pu.ID) || (pu.ID == "" && cand.Name == pu.Name) {
+				u = cand
+				break
+			}
+		}
+		if u == nil || u.Password != pu.Password {
+			u = nil
+		}
+	case contains(ident.Methods, "token"):
+		if t, ok := s.validToken(ident.Token.ID); ok {
+			u = s.users[t.UserID]
+		}
+	}
+	if u == nil || !u.Enabled {
+		writeError(w, http.StatusUnauthorized, "The request you have made requires authentication.")
+		return
+	}
+
+	projectID := ""
+	if body.Auth.Scope != nil && body.Auth.Scope.Project != nil {
+		ref := body.Auth.Scope.Project
+		for _, p := range s.projects {
+			if (ref.ID != "" && p.ID == ref.ID) || (ref.ID == "" && p.Name == ref.Name) {
+				projectID = p.ID
+				break
+			}
+		}
+		if projectID == "" || len(s.assignments[projectID][u.ID]) == 0 {
+			writeError(w, http.StatusUnauthorized, "User has no access to project")
+			return
+		}
+	}
+
+	t := s.issueTokenLocked(u.ID, projectID, time.Hour)
+	w.Header().Set("X-Subject-Token", t.ID)
+	writeJSON(w, http.StatusCreated, map[string]any{"token": s.tokenBodyLocked(t, true)})
+}
+
+func contains(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) tokenBodyLocked(t *token, withCatalog bool) map[string]any {
+	u := s.users[t.UserID]
+	body := map[string]any{
+		"methods":    []string{"password"},
+		"issued_at":  timestamp(t.IssuedAt),
+		"expires_at": timestamp(t.ExpiresAt),
+		"user": map[string]any{
+			"id":     u.ID,
+			"name":   u.Name,
+			"domain": map[string]any{"id": DefaultDomainID, "name": "Default"},
+		},
+	}
+	if t.ProjectID != "" {
+		p := s.projects[t.ProjectID]
+		var roles []map[string]any
+		for rid := range s.assignments[t.ProjectID][t.UserID] {
+			roles = append(roles, map[string]any{"id": rid, "name": s.roles[rid].Name})
+		}
+		body["project"] = map[string]any{
+			"id":     p.ID,
+			"name":   p.Name,
+			"domain": map[string]any{"id": DefaultDomainID, "name": "Default"},
+		}
+		body["roles"] = roles
+	}
+	if withCatalog {
+		body["catalog"] = s.catalog()
+	}
+	return body
+}
+
+func (s *Server) catalog() []map[string]any {
+	entry := func(typ, name, path string) map[string]any {
+		return map[string]any{
+			"id":   newID(),
+			"type": typ,
+			"name": name,
+			"endpoints": []map[string]any{{
+				"id":        newID(),
+				"interface": "public",
+				"region":    Region,
+				"region_id": Region,
+				"url":       s.srv.URL + path,
+			}},
+		}
+	}
+	return []map[string]any{
+		entry("identity", "keystone", "/identity"),
+		entry("compute", "nova", "/compute/v2.1"),
+		entry("block-storage", "cinder", "/volume/v3"),
+		entry("network", "neutron", "/networking"),
+		entry("image", "glance", "/image"),
+	}
+}
+
+func (s *Server) identityDomains(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name := r.URL.Query().Get("name")
+	var out []map[string]any
+	for _, d := range s.domains {
+		if name != "" && d.Name != name {
+			continue
+		}
+		out = append(out, map[string]any{"id": d.ID, "name": d.Name, "enabled": true})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"domains": out, "links": map[string]any{}})
+}
+
+func (s *Server) identityRoles(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name := r.URL.Query().Get("name")
+	var out []map[string]any
+	for _, rl := range s.roles {
+		if name != "" && rl.Name != name {
+			continue
+		}
+		out = append(out, map[string]any{"id": rl.ID, "name": rl.Name})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"roles": out, "links": map[string]any{}})
+}
+
+func (s *Server) identityRoleAssignments(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := r.URL.Query()
+	projectFilter, userFilter := q.Get("scope.project.id"), q.Get("user.id")
+	var out []map[string]any
+	for pid, byUser := range s.assignments {
+		if projectFilter != "" && pid != projectFilter {
+			continue
+		}
+		for uid, roles := range byUser {
+			if userFilter != "" && uid != userFilter {
+				continue
+			}
+			for rid := range roles {
+				out = append(out, map[string]any{
+					"role":  map[string]any{"id": rid},
+					"user":  map[string]any{"id": uid},
+					"scope": map[string]any{"project": map[string]any{"id": pid}},
+				})
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"role_assignments": out, "links": map[string]any{}})
+}
+
+func (s *Server) identityAssign(w http.ResponseWriter, r *http.Request, projectID, userID, roleID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.projects[projectID] == nil || s.users[userID] == nil || s.roles[roleID] == nil {
+		writeError(w, http.StatusNotFound, "Could not find project, user or role")
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		s.grantLocked(projectID, userID, s.roles[roleID].Name)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodHead, http.MethodGet:
+		if !s.assignments[projectID][userID][roleID] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		delete(s.assignments[projectID][userID], roleID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func projectJSON(p *project) map[string]any {
+	tags := p.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return map[string]any{
+		"id":          p.ID,
+		"name":        p.Name,
+		"description": p.Description,
+		"domain_id":   p.DomainID,
+		"enabled":     p.Enabled,
+		"is_domain":   false,
+		"parent_id":   p.DomainID,
+		"tags":        tags,
+		"options":     map[string]any{},
+	}
+}
+
+func (s *Server) identityProjects(w http.ResponseWriter, r *http.Request, rest []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			s.listProjects(w, r.URL.Query())
+		case http.MethodPost:
+			var body struct {
+				Project struct {
+					Name        string   `json:"name"`
+					Description string   `json:"description"`
+					DomainID    string   `json:"domain_id"`
+					Enabled     *bool    `json:"enabled"`
+					Tags        []string `json:"tags"`
+				} `json:"project"`
+			}
+			if err := decodeBody(r, &body); err != nil || body.Project.Name == "" {
+				writeError(w, http.StatusBadRequest, "invalid project")
+				return
+			}
+			domainID := body.Project.DomainID
+			if domainID == "" {
+				domainID = DefaultDomainID
+			}
+			for _, p := range s.projects {
+				if p.Name == body.Project.Name && p.DomainID == domainID {
+					writeError(w, http.StatusConflict, "Conflict occurred attempting to store project - it is not permitted to have two projects with the same name in the same domain : "+p.Name+".")
+					return
+				}
+			}
+			p := &project{
+				ID: newID(), Name: body.Project.Name, Description: body.Project.Description,
+				DomainID: domainID, Enabled: body.Project.Enabled == nil || *body.Project.Enabled,
+				Tags: body.Project.Tags,
+			}
+			s.projects[p.ID] = p
+			writeJSON(w, http.StatusCreated, map[string]any{"project": projectJSON(p)})
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	p, ok := s.projects[rest[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Could not find project: "+rest[0]+".")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"project": projectJSON(p)})
+	case http.MethodPatch:
+		var body struct {
+			Project struct {
+				Name        *string   `json:"name"`
+				Description *string   `json:"description"`
+				Enabled     *bool     `json:"enabled"`
+				Tags        *[]string `json:"tags"`
+			} `json:"project"`
+		}
+		if err := decodeBody(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid project")
+			return
+		}
+		if body.Project.Name != nil {
+			p.Name = *body.Project.Name
+		}
+		if body.Project.Description != nil {
+			p.Description = *body.Project.Description
+		}
+		if body.Project.Enabled != nil {
+			p.Enabled = *body.Project.Enabled
+		}
+		if body.Project.Tags != nil {
+			p.Tags = *body.Project.Tags
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"project": projectJSON(p)})
+	case http.MethodDelete:
+		delete(s.projects, p.ID)
+		delete(s.assignments, p.ID)
+		for _, svc := range []Service{Compute, BlockStorage, Network} {
+			delete(s.quotas[svc], p.ID)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) listProjects(w http.ResponseWriter, q url.Values) {
+	name, domainID := q.Get("name"), q.Get("domain_id")
+	var tags []string
+	if t := q.Get("tags"); t != "" {
+		tags = strings.Split(t, ",")
+	}
+	out := []map[string]any{}
+	for _, p := range s.projects {
+		if name != "" && p.Name != name {
+			continue
+		}
+		if domainID != "" && p.DomainID != domainID {
+			continue
+		}
+		matched := true
+		for _, t := range tags {
+			if !contains(p.Tags, t) {
+				matched = false
+			}
+		}
+		if !matched {
+			continue
+		}
+		out = append(out, projectJSON(p))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"projects": out, "links": map[string]any{}})
+}
+
+func userJSON(u *user) map[string]any {
+	opts := u.Options
+	if opts == nil {
+		opts = map[string]any{}
+	}
+	return map[string]any{
+		"id":                  u.ID,
+		"name":                u.Name,
+		"description":         u.Description,
+		"domain_id":           u.DomainID,
+		"enabled":             u.Enabled,
+		"options":             opts,
+		"password_expires_at": nil,
+	}
+}
+
+func (s *Server) identityUsers(w http.ResponseWriter, r *http.Request, rest []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			name := r.URL.Query().Get("name")
+			out := []map[string]any{}
+			for _, u := range s.users {
+				if name != "" && u.Name != name {
+					continue
+				}
+				out = append(out, userJSON(u))
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"users": out, "links": map[string]any{}})
+		case http.MethodPost:
+			var body struct {
+				User struct {
+					Name        string         `json:"name"`
+					Description string         `json:"description"`
+					DomainID    string         `json:"domain_id"`
+					Password    string         `json:"password"`
+					Enabled     *bool          `json:"enabled"`
+					Options     map[string]any `json:"options"`
+				} `json:"user"`
+			}
+			if err := decodeBody(r, &body); err != nil || body.User.Name == "" {
+				writeError(w, http.StatusBadRequest, "invalid user")
+				return
+			}
+			for _, u := range s.users {
+				if u.Name == body.User.Name {
+					writeError(w, http.StatusConflict, "Conflict occurred attempting to store user - Duplicate entry found with name "+u.Name+".")
+					return
+				}
+			}
+			domainID := body.User.DomainID
+			if domainID == "" {
+				domainID = DefaultDomainID
+			}
+			u := &user{
+				ID: newID(), Name: body.User.Name, Description: body.User.Description, DomainID: domainID,
+				Password: body.User.Password, Enabled: body.User.Enabled == nil || *body.User.Enabled,
+				Options: body.User.Options,
+			}
+			s.users[u.ID] = u
+			writeJSON(w, http.StatusCreated, map[string]any{"user": userJSON(u)})
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	u, ok := s.users[rest[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Could not find user: "+rest[0]+".")
+		return
+	}
+	if len(rest) == 2 && rest[1] == "password" && r.Method == http.MethodPost {
+		var body struct {
+			User struct {
+				OriginalPassword string `json:"original_password"`
+				Password         string `json:"password"`
+			} `json:"user"`
+		}
+		if err := decodeBody(r, &body); err != nil || body.User.OriginalPassword != u.Password {
+			writeError(w, http.StatusUnauthorized, "The request you have made requires authentication.")
+			return
+		}
+		u.Password = body.User.Password
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"user": userJSON(u)})
+	case http.MethodPatch:
+		var body struct {
+			User struct {
+				Name        *string        `json:"name"`
+				Description *string        `json:"description"`
+				Password    *string        `json:"password"`
+				Enabled     *bool          `json:"enabled"`
+				Options     map[string]any `json:"options"`
+			} `json:"user"`
+		}
+		if err := decodeBody(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid user")
+			return
+		}
+		if body.User.Name != nil {
+			u.Name = *body.User.Name
+		}
+		if body.User.Description != nil {
+			u.Description = *body.User.Description
+		}
+		if body.User.Password != nil {
+			u.Password = *body.User.Password
+		}
+		if body.User.Enabled != nil {
+			u.Enabled = *body.User.Enabled
+		}
+		if body.User.Options != nil {
+			if u.Options == nil {
+				u.Options = map[string]any{}
+			}
+			for k, v := range body.User.Options {
+				u.Options[k] = v
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"user": userJSON(u)})
+	case http.MethodDelete:
+		delete(s.users, u.ID)
+		for _, byUser := range s.assignments {
+			delete(byUser, u.ID)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
@@ -0,0 +1,35 @@
+package openstacktest
+
+import "net/http"
+
+type image struct {
+	ID     string
+	Name   string
+	Status string
+}
+
+func (s *Server) handleImage(w http.ResponseWriter, r *http.Request) {
+	parts := splitPath(r.URL.Path, "/image")
+	if len(parts) == 0 {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"versions": []map[string]any{{"id": "v2.16", "status": "CURRENT"}},
+		})
+		return
+	}
+	if len(parts) != 2 || parts[0] != "v2" || parts[1] != "images" || r.Method != http.MethodGet {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []map[string]any{}
+	for _, img := range s.images {
+		out = append(out, map[string]any{
+			"id": img.ID, "name": img.Name, "status": img.Status,
+			"visibility": "public", "disk_format": "qcow2", "container_format": "bare",
+			"tags": []string{}, "protected": false,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"images": out})
+}
@@ -0,0 +1,243 @@
+package openstacktest
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type network struct {
+	ID        string
+	Name      string
+	ProjectID string
+	External  bool
+	CIDR      string
+}
+
+type port struct {
+	ID          string
+	NetworkID   string
+	DeviceID    string
+	DeviceOwner string
+	ProjectID   string
+	FixedIP     string
+}
+
+type floatingIP struct {
+	ID        string
+	Address   string
+	NetworkID string
+	PortID    string
+	ProjectID string
+}
+
+type secgroup struct {
+	ID          string
+	Name        string
+	Description string
+	ProjectID   string
+}
+
+func (s *Server) handleNetwork(w http.ResponseWriter, r *http.Request) {
+	parts := splitPath(r.URL.Path, "/networking")
+	if len(parts) == 0 {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"versions": []map[string]any{{"id": "v2.0", "status": "CURRENT"}},
+		})
+		return
+	}
+	if parts[0] != "v2.0" || len(parts) < 2 {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	parts = parts[1:]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tok, _ := s.validToken(r.Header.Get("X-Auth-Token"))
+
+	switch parts[0] {
+	case "quotas":
+		s.neutronQuota(w, r, parts[1:])
+	case "security-groups":
+		out := []map[string]any{}
+		for _, g := range s.secgroups {
+			if pid := r.URL.Query().Get("project_id"); pid != "" && g.ProjectID != pid {
+				continue
+			}
+			out = append(out, map[string]any{
+				"id": g.ID, "name": g.Name, "description": g.Description,
+				"project_id": g.ProjectID, "tenant_id": g.ProjectID,
+				"security_group_rules": []any{}, "stateful": true,
+			})
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"security_groups": out})
+	case "networks":
+		out := []map[string]any{}
+		for _, n := range s.networks {
+			if pid := r.URL.Query().Get("project_id"); pid != "" && n.ProjectID != pid {
+				continue
+			}
+			out = append(out, map[string]any{
+				"id": n.ID, "name": n.Name, "project_id": n.ProjectID, "tenant_id": n.ProjectID,
+				"router:external": n.External, "status": "ACTIVE", "admin_state_up": true,
+			})
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"networks": out})
+	case "ports":
+		q := r.URL.Query()
+		out := []map[string]any{}
+		for _, p := range s.ports {
+			if d := q.Get("device_id"); d != "" && p.DeviceID != d {
+				continue
+			}
+			if pid := q.Get("project_id"); pid != "" && p.ProjectID != pid {
+				continue
+			}
+			out = append(out, portJSON(p))
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ports": out})
+	case "floatingips":
+		s.neutronFloatingIPs(w, r, tok, parts[1:])
+	case "subnets", "routers", "security-group-rules":
+		// 서브넷, 라우터, 보안 그룹 규칙은 모델링하지 않는다 (항상 비어 있음)
+		key := map[string]string{"subnets": "subnets", "routers": "routers", "security-group-rules": "security_group_rules"}[parts[0]]
+		writeJSON(w, http.StatusOK, map[string]any{key: []any{}})
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) neutronQuota(w http.ResponseWriter, r *http.Request, rest []string) {
+	if len(rest) == 0 {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	projectID := rest[0]
+	qs := s.quotaSet(Network, projectID)
+	switch {
+	case r.Method == http.MethodGet && len(rest) == 2 && rest[1] == "details.json":
+		body := map[string]any{}
+		for k, v := range qs {
+			body[k] = map[string]any{"limit": v.Limit, "used": v.InUse, "reserved": 0}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"quota": body})
+	case r.Method == http.MethodGet && len(rest) == 1:
+		writeJSON(w, http.StatusOK, map[string]any{"quota": flatQuota("", qs)})
+	case r.Method == http.MethodPut && len(rest) == 1:
+		var body struct {
+			Quota map[string]any `json:"quota"`
+		}
+		if err := decodeBody(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid quota")
+			return
+		}
+		// Neutron 은 사용량보다 낮은 한도도 허용한다
+		if err := s.updateQuota(Network, projectID, body.Quota, false); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"quota": flatQuota("", qs)})
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func portJSON(p *port) map[string]any {
+	return map[string]any{
+		"id": p.ID, "network_id": p.NetworkID, "device_id": p.DeviceID, "device_owner": p.DeviceOwner,
+		"project_id": p.ProjectID, "tenant_id": p.ProjectID, "status": "ACTIVE", "admin_state_up": true,
+		"fixed_ips": []map[string]any{{"ip_address": p.FixedIP, "subnet_id": ""}},
+	}
+}
+
+func fipJSON(f *floatingIP) map[string]any {
+	var portID any
+	if f.PortID != "" {
+		portID = f.PortID
+	}
+	return map[string]any{
+		"id": f.ID, "floating_ip_address": f.Address, "floating_network_id": f.NetworkID,
+		"port_id": portID, "project_id": f.ProjectID, "tenant_id": f.ProjectID, "status": "ACTIVE",
+	}
+}
+
+func (s *Server) neutronFloatingIPs(w http.ResponseWriter, r *http.Request, tok *token, rest []string) {
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			out := []map[string]any{}
+			for _, f := range s.fips {
+				if pid := r.URL.Query().Get("project_id"); pid != "" && f.ProjectID != pid {
+					continue
+				}
+				out = append(out, fipJSON(f))
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"floatingips": out})
+		case http.MethodPost:
+			var body struct {
+				FloatingIP struct {
+					FloatingNetworkID string `json:"floating_network_id"`
+					PortID            string `json:"port_id"`
+				} `json:"floatingip"`
+			}
+			if err := decodeBody(r, &body); err != nil {
+				writeError(w, http.StatusBadRequest, "invalid floatingip")
+				return
+			}
+			if n, ok := s.networks[body.FloatingIP.FloatingNetworkID]; !ok || !n.External {
+				writeError(w, http.StatusNotFound, "External network "+body.FloatingIP.FloatingNetworkID+" could not be found.")
+				return
+			}
+			if err := s.addUsage(Network, tok.ProjectID, map[string]int{"floatingip": 1}); err != nil {
+				writeError(w, http.StatusConflict, err.Error())
+				return
+			}
+			s.ipSeq++
+			f := &floatingIP{
+				ID: newID(), Address: fmt.Sprintf("172.24.4.%d", 10+s.ipSeq), NetworkID: body.FloatingIP.FloatingNetworkID,
+				PortID: body.FloatingIP.PortID, ProjectID: tok.ProjectID,
+			}
+			s.fips[f.ID] = f
+			writeJSON(w, http.StatusCreated, map[string]any{"floatingip": fipJSON(f)})
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	f, ok := s.fips[rest[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Floating IP "+rest[0]+" could not be found.")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"floatingip": fipJSON(f)})
+	case http.MethodPut:
+		var body struct {
+			FloatingIP struct {
+				PortID *string `json:"port_id"`
+			} `json:"floatingip"`
+		}
+		if err := decodeBody(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid floatingip")
+			return
+		}
+		if body.FloatingIP.PortID != nil {
+			if *body.FloatingIP.PortID != "" {
+				if _, ok := s.ports[*body.FloatingIP.PortID]; !ok {
+					writeError(w, http.StatusNotFound, "Port "+*body.FloatingIP.PortID+" could not be found.")
+					return
+				}
+			}
+			f.PortID = *body.FloatingIP.PortID
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"floatingip": fipJSON(f)})
+	case http.MethodDelete:
+		delete(s.fips, f.ID)
+		_ = s.addUsage(Network, f.ProjectID, map[string]int{"floatingip": -1})
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
@@ -0,0 +1,422 @@
+// Package openstacktest provides an in-process stand-in for the OpenStack
+// control plane (Keystone, Nova, Cinder, Neutron, Glance) so the code in
+// internal/openstack and the HTTP server can be exercised without DevStack.
+//
+// 사용 예:
+//
+//	fake := openstacktest.NewServer()
+//	defer fake.Close()
+//	osc, err := openstack.NewServiceClients(fake.Config())
+//
+// 모든 상태는 메모리에만 유지되며, Inject 로 특정 요청에 실패를 주입할 수 있다.
+package openstacktest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/quotaapi/internal/config"
+)
+
+// 기본 관리자 계정/스코프 (Config 가 이 값을 사용)
+const (
+	AdminUsername    = "admin"
+	AdminPassword    = "secret"
+	AdminProjectName = "admin"
+	DefaultDomainID  = "default"
+	Region           = "RegionOne"
+)
+
+// Service identifies one of the quota-bearing services of the fake.
+type Service string
+
+const (
+	Compute      Service = "compute"
+	BlockStorage Service = "volume"
+	Network      Service = "network"
+)
+
+// QuotaItem is a single quota resource (limit + usage) of a project.
+type QuotaItem struct {
+	Limit int `json:"limit"`
+	InUse int `json:"in_use"`
+}
+
+// Fault describes a failure to inject into matching requests.
+// Path 는 서비스 prefix 를 포함한 경로의 접두사 (예: "/volume/v3/os-quota-sets").
+type Fault struct {
+	Method string // 빈 값이면 모든 메서드
+	Path   string // 요청 경로 접두사
+	Status int    // 응답 코드 (기본 500)
+	Body   string // 응답 본문 (기본: 에러 메시지 JSON)
+	Times  int    // 남은 횟수, 0 이면 ClearFaults 전까지 계속
+}
+
+// Request is a record of a request served by the fake.
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+}
+
+// Server is an httptest-backed fake OpenStack cloud.
+type Server struct {
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	faults   []*Fault
+	requests []Request
+
+	domains     map[string]*domain
+	projects    map[string]*project
+	users       map[string]*user
+	roles       map[string]*role
+	assignments map[string]map[string]map[string]bool // projectID → userID → roleID
+	tokens      map[string]*token
+
+	quotas map[Service]map[string]map[string]*QuotaItem // service → projectID → resource
+
+	flavors   []flavor
+	images    []image
+	keypairs  []keypair
+	secgroups []secgroup
+	networks  map[string]*network
+	servers   map[string]*server
+	ports     map[string]*port
+	fips      map[string]*floatingIP
+
+	hypervisors  []Hypervisor
+	storagePools []StoragePool
+
+	adminProjectID string
+	adminUserID    string
+	externalNetID  string
+	privateNetID   string
+	ipSeq          int
+}
+
+// NewServer starts a fake cloud seeded with an admin project/user, the
+// member/admin/reader roles, a couple of flavors, an image and networks.
+func NewServer() *Server {
+	s := &Server{
+		domains:     map[string]*domain{},
+		projects:    map[string]*project{},
+		users:       map[string]*user{},
+		roles:       map[string]*role{},
+		assignments: map[string]map[string]map[string]bool{},
+		tokens:      map[string]*token{},
+		quotas: map[Service]map[string]map[string]*QuotaItem{
+			Compute:      {},
+			BlockStorage: {},
+			Network:      {},
+		},
+		networks: map[string]*network{},
+		servers:  map[string]*server{},
+		ports:    map[string]*port{},
+		fips:     map[string]*floatingIP{},
+	}
+	s.seed()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/identity/", s.handleIdentity)
+	mux.HandleFunc("/compute/", s.handleCompute)
+	mux.HandleFunc("/volume/", s.handleBlockStorage)
+	mux.HandleFunc("/networking/", s.handleNetwork)
+	mux.HandleFunc("/image/", s.handleImage)
+	s.srv = httptest.NewServer(s.intercept(mux))
+	return s
+}
+
+// URL returns the base URL of the fake.
+func (s *Server) URL() string { return s.srv.URL }
+
+// AuthURL returns the Keystone v3 endpoint (OS_AUTH_URL).
+func (s *Server) AuthURL() string { return s.srv.URL + "/identity/v3/" }
+
+// Close shuts the fake down.
+func (s *Server) Close() { s.srv.Close() }
+
+// Config returns a config.Config that authenticates against the fake as admin.
+func (s *Server) Config() *config.Config {
+	return &config.Config{
+		AuthURL:        s.AuthURL(),
+		Username:       AdminUsername,
+		Password:       AdminPassword,
+		UserDomainID:   DefaultDomainID,
+		ProjectName:    AdminProjectName,
+		RegionName:     Region,
+		AdminProjectID: s.adminProjectID,
+		StorageBackend: "memory",
+		Auth: config.AuthConfig{
+			Enabled:        true,
+			CacheTTL:       time.Minute,
+			AdminRole:      "admin",
+			InstructorRole: "instructor",
+			TARole:         "ta",
+			StudentRole:    "member",
+			AdminProjectID: s.adminProjectID,
+		},
+		Lifecycle: config.LifecycleConfig{GracePeriod: 7 * 24 * time.Hour, WarnBefore: 48 * time.Hour},
+		Jobs:      config.JobsConfig{Workers: 1, PollInterval: 50 * time.Millisecond, MaxAttempts: 3, BackoffBase: 100 * time.Millisecond, BackoffMax: time.Second},
+		Leader:    config.LeaderConfig{ID: "openstacktest", LeaseTTL: time.Second, RenewInterval: 100 * time.Millisecond},
+		Capacity:  config.CapacityConfig{Mode: config.CapacityWarn, CPURatio: 4, RAMRatio: 1.5, DiskRatio: 1},
+	}
+}
+
+// AdminProjectID returns the ID of the seeded admin project.
+func (s *Server) AdminProjectID() string { return s.adminProjectID }
+
+// ExternalNetworkID returns the ID of the seeded external ("public") network.
+func (s *Server) ExternalNetworkID() string { return s.externalNetID }
+
+// PrivateNetworkID returns the ID of the seeded tenant ("private") network.
+func (s *Server) PrivateNetworkID() string { return s.privateNetID }
+
+// Inject registers a fault for matching requests.
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f.Status == 0 {
+		f.Status = http.StatusInternalServerError
+	}
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes every injected fault.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns the requests served so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// intercept records every request, applies injected faults and checks the
+// X-Auth-Token header for everything but token issuance and version discovery.
+func (s *Server) intercept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery})
+		if f := s.matchFault(r); f != nil {
+			s.mu.Unlock()
+			body := f.Body
+			if body == "" {
+				body = fmt.Sprintf(`{"error":{"code":%d,"message":"injected fault"}}`, f.Status)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(f.Status)
+			_, _ = w.Write([]byte(body))
+			return
+		}
+
+		public := r.URL.Path == "/identity/v3/auth/tokens" && r.Method == http.MethodPost ||
+			isVersionDocument(r.URL.Path)
+		if !public {
+			if _, ok := s.validToken(r.Header.Get("X-Auth-Token")); !ok {
+				s.mu.Unlock()
+				writeError(w, http.StatusUnauthorized, "The request you have made requires authentication.")
+				return
+			}
+		}
+		s.mu.Unlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// matchFault must be called with s.mu held.
+func (s *Server) matchFault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && !strings.EqualFold(f.Method, r.Method) {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func isVersionDocument(path string) bool {
+	switch strings.TrimSuffix(path, "/") {
+	case "/identity", "/networking", "/image":
+		return true
+	}
+	return false
+}
+
+// ---- quota state ----
+
+var defaultQuotas = map[Service]map[string]int{
+	Compute: {
+		"cores": 20, "ram": 51200, "instances": 10, "key_pairs": 100,
+		"metadata_items": 128, "server_groups": 10, "server_group_members": 10,
+		"injected_files": 5, "injected_file_content_bytes": 10240, "injected_file_path_bytes": 255,
+	},
+	BlockStorage: {
+		"volumes": 10, "snapshots": 10, "gigabytes": 1000, "per_volume_gigabytes": -1,
+		"backups": 10, "backup_gigabytes": 1000, "groups": 10,
+		// 볼륨 타입별 한도 (Cinder 가 기본으로 만드는 __DEFAULT__ 타입)
+		"volumes___DEFAULT__": -1, "gigabytes___DEFAULT__": -1, "snapshots___DEFAULT__": -1,
+	},
+	Network: {
+		"network": 100, "subnet": 100, "port": 500, "router": 10, "floatingip": 50,
+		"security_group": 10, "security_group_rule": 100, "rbac_policy": 10, "subnetpool": -1, "trunk": -1,
+	},
+}
+
+// quotaSet returns (creating on first use) the quota set of a project.
+// Must be called with s.mu held.
+func (s *Server) quotaSet(svc Service, projectID string) map[string]*QuotaItem {
+	qs, ok := s.quotas[svc][projectID]
+	if !ok {
+		qs = map[string]*QuotaItem{}
+		for k, v := range defaultQuotas[svc] {
+			qs[k] = &QuotaItem{Limit: v}
+		}
+		s.quotas[svc][projectID] = qs
+	}
+	return qs
+}
+
+// Quota returns a copy of a project's quota set for the given service.
+func (s *Server) Quota(svc Service, projectID string) map[string]QuotaItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := map[string]QuotaItem{}
+	for k, v := range s.quotaSet(svc, projectID) {
+		out[k] = *v
+	}
+	return out
+}
+
+// SetUsage overrides the in-use counter of one quota resource.
+func (s *Server) SetUsage(svc Service, projectID, resource string, inUse int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	qs := s.quotaSet(svc, projectID)
+	if _, ok := qs[resource]; !ok {
+		qs[resource] = &QuotaItem{Limit: -1}
+	}
+	qs[resource].InUse = inUse
+}
+
+// SetLimit overrides a quota limit directly, as an operator would in Horizon.
+func (s *Server) SetLimit(svc Service, projectID, resource string, limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	qs := s.quotaSet(svc, projectID)
+	if _, ok := qs[resource]; !ok {
+		qs[resource] = &QuotaItem{}
+	}
+	qs[resource].Limit = limit
+}
+
+// updateQuota applies a PUT body to a quota set. When strict is set the
+// new limit may not drop below current usage (Nova/Cinder behaviour).
+// Must be called with s.mu held.
+func (s *Server) updateQuota(svc Service, projectID string, body map[string]any, strict bool) error {
+	qs := s.quotaSet(svc, projectID)
+	force, _ := body["force"].(bool)
+	updates := map[string]int{}
+	for k, v := range body {
+		f, ok := v.(float64)
+		if !ok {
+			continue
+		}
+		limit := int(f)
+		if cur, ok := qs[k]; ok && strict && !force && limit >= 0 && limit < cur.InUse {
+			return fmt.Errorf("Quota limit %d for %s must be greater than or equal to already used and reserved %d.", limit, k, cur.InUse)
+		}
+		updates[k] = limit
+	}
+	for k, limit := range updates {
+		if _, ok := qs[k]; !ok {
+			qs[k] = &QuotaItem{}
+		}
+		qs[k].Limit = limit
+	}
+	return nil
+}
+
+// addUsage bumps usage counters after checking limits (-1 = unlimited).
+// Must be called with s.mu held.
+func (s *Server) addUsage(svc Service, projectID string, deltas map[string]int) error {
+	qs := s.quotaSet(svc, projectID)
+	for k, d := range deltas {
+		q, ok := qs[k]
+		if !ok || d <= 0 {
+			continue
+		}
+		if q.Limit >= 0 && q.InUse+d > q.Limit {
+			return fmt.Errorf("Quota exceeded for %s: Requested %d, but already used %d of %d %s", k, d, q.InUse, q.Limit, k)
+		}
+	}
+	for k, d := range deltas {
+		if q, ok := qs[k]; ok {
+			q.InUse += d
+			if q.InUse < 0 {
+				q.InUse = 0
+			}
+		}
+	}
+	return nil
+}
+
+// ---- helpers ----
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if v != nil {
+		_ = json.NewEncoder(w).Encode(v)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]any{
+		"error": map[string]any{"code": code, "message": msg},
+	})
+}
+
+func decodeBody(r *http.Request, v any) error {
+	defer r.Body.Close()
+	return json.NewDecoder(r.Body).Decode(v)
+}
+
+// splitPath trims prefix and returns the remaining non-empty path segments.
+func splitPath(path, prefix string) []string {
+	rest := strings.Trim(strings.TrimPrefix(path, prefix), "/")
+	if rest == "" {
+		return nil
+	}
+	return strings.Split(rest, "/")
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000000Z")
+}
@@ -0,0 +1,298 @@
+package openstacktest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"example.com/quotaapi/internal/config"
+	"example.com/quotaapi/internal/models"
+	"example.com/quotaapi/internal/openstack"
+	"example.com/quotaapi/internal/openstack/openstacktest"
+	"github.com/gophercloud/gophercloud/v2"
+)
+
+func newClients(t *testing.T) (*openstacktest.Server, *openstack.Clients) {
+	t.Helper()
+	fake := openstacktest.NewServer()
+	t.Cleanup(fake.Close)
+	osc, err := openstack.NewServiceClients(fake.Config())
+	if err != nil {
+		t.Fatalf("NewServiceClients: %v", err)
+	}
+	return fake, osc
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestNewServiceClients(t *testing.T) {
+	fake := openstacktest.NewServer()
+	defer fake.Close()
+
+	tests := []struct {
+		name    string
+		mutate  func(*config.Config)
+		fault   *openstacktest.Fault
+		wantErr bool
+	}{
+		{name: "admin credentials", mutate: func(*config.Config) {}},
+		{name: "wrong password", mutate: func(c *config.Config) { c.Password = "wrong" }, wantErr: true},
+		{name: "unknown project", mutate: func(c *config.Config) { c.ProjectName = "nope" }, wantErr: true},
+		{name: "unknown region", mutate: func(c *config.Config) { c.RegionName = "Elsewhere" }, wantErr: true},
+		{
+			name:    "keystone down",
+			mutate:  func(*config.Config) {},
+			fault:   &openstacktest.Fault{Method: http.MethodPost, Path: "/identity/v3/auth/tokens", Status: http.StatusServiceUnavailable, Times: 1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer fake.ClearFaults()
+			if tt.fault != nil {
+				fake.Inject(*tt.fault)
+			}
+			cfg := fake.Config()
+			tt.mutate(cfg)
+
+			osc, err := openstack.NewServiceClients(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err == nil && (osc.ComputeV2 == nil || osc.BlockStorageV3 == nil || osc.NetworkV2 == nil || osc.ImageV2 == nil) {
+				t.Fatalf("missing service client: %+v", osc)
+			}
+		})
+	}
+}
+
+func TestCreateStudentProject(t *testing.T) {
+	fake, osc := newClients(t)
+	pm := openstack.NewProjectManager(osc)
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		student     models.Student
+		setup       func()
+		wantAdopted bool
+		wantErr     error
+	}{
+		{name: "new student", student: models.Student{StudentID: "20240001", Name: "Kim"}},
+		{
+			name:    "existing project is adopted",
+			student: models.Student{StudentID: "20240002", Name: "Lee"},
+			setup: func() {
+				fake.AddProject(openstack.StudentProjectName("20240002"), "Project for student Lee (20240002)",
+					openstack.ManagedByTag, "student=20240002")
+			},
+			wantAdopted: true,
+		},
+		{
+			name:    "foreign project with the same name",
+			student: models.Student{StudentID: "20240003", Name: "Park"},
+			setup: func() {
+				fake.AddProject(openstack.StudentProjectName("20240003"), "someone else's project")
+			},
+			wantErr: openstack.ErrNotOwned,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+			project, adopted, err := pm.EnsureStudentProject(ctx, &tt.student)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EnsureStudentProject: %v", err)
+			}
+			if adopted != tt.wantAdopted {
+				t.Errorf("adopted = %t, want %t", adopted, tt.wantAdopted)
+			}
+			user, _, err := pm.EnsureStudentUser(ctx, &tt.student)
+			if err != nil {
+				t.Fatalf("EnsureStudentUser: %v", err)
+			}
+			if err := pm.AssignMemberRole(ctx, user.ID, project.ID); err != nil {
+				t.Fatalf("AssignMemberRole: %v", err)
+			}
+			if roles := fake.RoleNames(project.ID, user.ID); !slices.Contains(roles, "member") {
+				t.Errorf("roles = %v, want member", roles)
+			}
+			found, err := pm.FindStudentProject(ctx, tt.student.StudentID)
+			if err != nil || found.ID != project.ID {
+				t.Errorf("FindStudentProject = %v, %v; want %s", found, err, project.ID)
+			}
+		})
+	}
+}
+
+func TestApplyQuota(t *testing.T) {
+	fake, osc := newClients(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		svc   openstacktest.Service
+		apply func(projectID string) error
+		want  map[string]int
+	}{
+		{
+			name: "nova",
+			svc:  openstacktest.Compute,
+			apply: func(pid string) error {
+				return osc.ApplyNovaQuota(ctx, pid, openstack.NovaQuotaUpdate{Cores: intPtr(8), RAMMB: intPtr(16384), KeyPairs: intPtr(3)})
+			},
+			want: map[string]int{"cores": 8, "ram": 16384, "key_pairs": 3, "instances": 10},
+		},
+		{
+			name: "cinder with volume types",
+			svc:  openstacktest.BlockStorage,
+			apply: func(pid string) error {
+				return osc.ApplyCinderQuota(ctx, pid, openstack.CinderQuotaUpdate{
+					Gigabytes:   intPtr(100),
+					Backups:     intPtr(2),
+					VolumeTypes: map[string]models.VolumeTypeQuota{"ssd": {Volumes: 5, Gigabytes: 50}},
+				})
+			},
+			want: map[string]int{"gigabytes": 100, "backups": 2, "volumes_ssd": 5, "gigabytes_ssd": 50, "volumes": 10},
+		},
+		{
+			name: "neutron",
+			svc:  openstacktest.Network,
+			apply: func(pid string) error {
+				return osc.ApplyNeutronQuota(ctx, pid, openstack.NeutronQuotaUpdate{Ports: intPtr(20), Routers: intPtr(1), SecurityGroupRules: intPtr(50)})
+			},
+			want: map[string]int{"port": 20, "router": 1, "security_group_rule": 50, "network": 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pid := fake.AddProject("quota-"+tt.name, "")
+			if err := tt.apply(pid); err != nil {
+				t.Fatalf("apply: %v", err)
+			}
+			got := fake.Quota(tt.svc, pid)
+			for resource, limit := range tt.want {
+				if got[resource].Limit != limit {
+					t.Errorf("%s limit = %d, want %d", resource, got[resource].Limit, limit)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyQuotaBelowUsage(t *testing.T) {
+	fake, osc := newClients(t)
+	pid := fake.AddProject("busy", "")
+	fake.SetUsage(openstacktest.Compute, pid, "cores", 6)
+
+	err := osc.ApplyNovaQuota(context.Background(), pid, openstack.NovaQuotaUpdate{Cores: intPtr(4)})
+	if !gophercloud.ResponseCodeIs(err, http.StatusBadRequest) {
+		t.Fatalf("err = %v, want 400", err)
+	}
+	if got := fake.Quota(openstacktest.Compute, pid)["cores"].Limit; got != 20 {
+		t.Errorf("cores limit = %d, want unchanged 20", got)
+	}
+}
+
+func TestFaultInjection(t *testing.T) {
+	fake, osc := newClients(t)
+	ctx := context.Background()
+	pid := fake.AddProject("faulty", "")
+	cores := openstack.NovaQuotaUpdate{Cores: intPtr(4)}
+
+	tests := []struct {
+		name     string
+		fault    openstacktest.Fault
+		call     func() error
+		wantCode int // 0 = 성공
+	}{
+		{
+			name:     "matching method and path",
+			fault:    openstacktest.Fault{Method: http.MethodPut, Path: "/compute/v2.1/os-quota-sets", Status: http.StatusConflict},
+			call:     func() error { return osc.ApplyNovaQuota(ctx, pid, cores) },
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "default status is 500",
+			fault:    openstacktest.Fault{Path: "/networking/v2.0/quotas"},
+			call:     func() error { return osc.ApplyNeutronQuota(ctx, pid, openstack.NeutronQuotaUpdate{Ports: intPtr(1)}) },
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:  "other method is not affected",
+			fault: openstacktest.Fault{Method: http.MethodGet, Path: "/compute/v2.1/os-quota-sets", Status: http.StatusConflict},
+			call:  func() error { return osc.ApplyNovaQuota(ctx, pid, cores) },
+		},
+		{
+			name:  "other service is not affected",
+			fault: openstacktest.Fault{Path: "/volume/", Status: http.StatusServiceUnavailable},
+			call:  func() error { return osc.ApplyNovaQuota(ctx, pid, cores) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer fake.ClearFaults()
+			fake.Inject(tt.fault)
+			err := tt.call()
+			if tt.wantCode == 0 {
+				if err != nil {
+					t.Fatalf("err = %v, want success", err)
+				}
+				return
+			}
+			if !gophercloud.ResponseCodeIs(err, tt.wantCode) {
+				t.Fatalf("err = %v, want %d", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestFaultTimes(t *testing.T) {
+	fake, osc := newClients(t)
+	ctx := context.Background()
+	pid := fake.AddProject("flaky", "")
+	fake.Inject(openstacktest.Fault{Path: "/volume/v3/os-quota-sets", Status: http.StatusServiceUnavailable, Times: 2})
+
+	update := openstack.CinderQuotaUpdate{Volumes: intPtr(3)}
+	for i, wantErr := range []bool{true, true, false} {
+		if err := osc.ApplyCinderQuota(ctx, pid, update); (err != nil) != wantErr {
+			t.Fatalf("call %d: err = %v, wantErr %t", i+1, err, wantErr)
+		}
+	}
+	if got := fake.Quota(openstacktest.BlockStorage, pid)["volumes"].Limit; got != 3 {
+		t.Errorf("volumes limit = %d, want 3", got)
+	}
+}
+
+// TestProjectManagerConcurrentUse shares one ProjectManager between
+// goroutines the way the worker pool does (go test -race 로 도메인 ID 캐시 검사).
+func TestProjectManagerConcurrentUse(t *testing.T) {
+	_, osc := newClients(t)
+	pm := openstack.NewProjectManager(osc)
+	ctx := context.Background()
+
+	errs := make(chan error, 8)
+	for i := range 8 {
+		go func() {
+			student := models.Student{StudentID: fmt.Sprintf("2024%04d", i), Name: "S"}
+			_, _, err := pm.EnsureStudentProject(ctx, &student)
+			errs <- err
+		}()
+	}
+	for range 8 {
+		if err := <-errs; err != nil {
+			t.Errorf("EnsureStudentProject: %v", err)
+		}
+	}
+}
@@ -2,10 +2,14 @@ package openstack
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
+	"sync"
 
 	"example.com/quotaapi/internal/models"
+	"github.com/gophercloud/gophercloud/v2"
 	"github.com/gophercloud/gophercloud/v2/openstack/identity/v3/domains"
 	"github.com/gophercloud/gophercloud/v2/openstack/identity/v3/projects"
 	"github.com/gophercloud/gophercloud/v2/openstack/identity/v3/roles"
@@ -14,7 +18,10 @@ import (
 
 // ProjectManager handles OpenStack project operations for students
 type ProjectManager struct {
-	clients  *Clients
+	clients *Clients
+
+	// 워커 풀, 작업 큐 워커, bootstrap 이 동시에 쓰므로 mu 로 보호한다
+	mu       sync.Mutex
 	domainID string // 캐시
 }
 
@@ -23,8 +30,11 @@ func NewProjectManager(clients *Clients) *ProjectManager {
 	return &ProjectManager{clients: clients}
 }
 
-// ensureDomainID gets and caches the default domain ID
+// ensureDomainID gets and caches the default domain ID. 조회에 실패하면
+// 캐시하지 않으므로 다음 호출에서 다시 시도한다.
 func (pm *ProjectManager) ensureDomainID(ctx context.Context) (string, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
 	if pm.domainID != "" {
 		return pm.domainID, nil
 	}
@@ -49,52 +59,27 @@ func ptrBool(b bool) *bool {
 	return &b
 }
 
-// CreateStudentProject creates a complete student environment in OpenStack
-func (pm *ProjectManager) CreateStudentProject(ctx context.Context, student *models.Student) error {
-	// 1. 프로젝트 생성
-	project, err := pm.createProject(ctx, student)
-	if err != nil {
-		return fmt.Errorf("failed to create project: %w", err)
-	}
-
-	// 2. 사용자 생성
-	user, err := pm.createUser(ctx, student)
-	if err != nil {
-		return fmt.Errorf("failed to create user: %w", err)
-	}
-
-	// 3. 사용자를 프로젝트에 할당하고 기본 역할 부여
-	if err := pm.assignUserToProjectWithRoles(ctx, user.ID, project.ID); err != nil {
-		return fmt.Errorf("failed to assign user to project: %w", err)
-	}
-
-	// 4. 기본 쿼타 설정 (basic 프로파일)
-	if err := pm.setDefaultQuotas(ctx, project.ID); err != nil {
-		return fmt.Errorf("failed to set default quotas: %w", err)
-	}
-
-	// 5. 학생 정보 업데이트
-	student.KeystoneProjectID = project.ID
-	student.KeystoneUserID = user.ID
-
-	return nil
-}
-
-// createProject creates a new project for a student
-func (pm *ProjectManager) createProject(ctx context.Context, student *models.Student) (*projects.Project, error) {
+// CreateProject creates a new project for a student
+func (pm *ProjectManager) CreateProject(ctx context.Context, student *models.Student) (*projects.Project, error) {
 	did, err := pm.ensureDomainID(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	projectName := fmt.Sprintf("student-%s-project", student.StudentID)
+	projectName := StudentProjectName(student.StudentID)
 	projectDescription := fmt.Sprintf("Project for student %s (%s)", student.Name, student.StudentID)
 
+	tags := []string{ManagedByTag, studentTag(student.StudentID)} // 소유 표식 (재등록 시 채택 근거)
+	if student.BootstrapSagaID != "" {
+		tags = append(tags, sagaTag(student.BootstrapSagaID))
+	}
+
 	createOpts := projects.CreateOpts{
 		Name:        projectName,
 		Description: projectDescription,
 		DomainID:    did, // 올바른 DomainID 사용
 		Enabled:     ptrBool(true),
+		Tags:        tags,
 	}
 
 	project, err := projects.Create(ctx, pm.clients.Identity, createOpts).Extract()
@@ -106,25 +91,33 @@ func (pm *ProjectManager) createProject(ctx context.Context, student *models.Stu
 	return project, nil
 }
 
-// createUser creates a new user for a student
-func (pm *ProjectManager) createUser(ctx context.Context, student *models.Student) (*users.User, error) {
+// CreateUser creates a new user for a student
+func (pm *ProjectManager) CreateUser(ctx context.Context, student *models.Student) (*users.User, error) {
 	did, err := pm.ensureDomainID(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	userName := fmt.Sprintf("student-%s-user", student.StudentID)
-	userDescription := fmt.Sprintf("User account for student %s (%s)", student.Name, student.StudentID)
+	userName := StudentUserName(student.StudentID)
+	userDescription := fmt.Sprintf("User account for student %s (%s) %s", student.Name, student.StudentID, userOwnershipMarker(student.StudentID))
+	if student.BootstrapSagaID != "" {
+		userDescription += " " + sagaUserMarker(student.BootstrapSagaID)
+	}
 
-	// 임시 비밀번호 생성 (실제로는 안전한 방법 사용)
-	tempPassword := fmt.Sprintf("temp-%s-2025", student.StudentID)
+	// 아무도 모르는 무작위 비밀번호로 생성한다. 학생은 일회용 조회 토큰으로
+	// 새 비밀번호를 발급받는다 (services.CredentialService).
+	initialPassword, err := GeneratePassword()
+	if err != nil {
+		return nil, err
+	}
 
 	createOpts := users.CreateOpts{
 		Name:        userName,
 		Description: userDescription,
-		Password:    tempPassword,
+		Password:    initialPassword,
 		DomainID:    did, // 올바른 DomainID 사용
 		Enabled:     ptrBool(true),
+		Options:     forcePasswordChangeOptions,
 	}
 
 	user, err := users.Create(ctx, pm.clients.Identity, createOpts).Extract()
@@ -136,8 +129,8 @@ func (pm *ProjectManager) createUser(ctx context.Context, student *models.Studen
 	return user, nil
 }
 
-// assignUserToProjectWithRoles assigns a user to a project with default roles
-func (pm *ProjectManager) assignUserToProjectWithRoles(ctx context.Context, userID, projectID string) error {
+// AssignMemberRole assigns a user to a project with default roles
+func (pm *ProjectManager) AssignMemberRole(ctx context.Context, userID, projectID string) error {
 	// 기본 역할: member (실제 존재하는 롤)
 	role, err := pm.findRoleByName(ctx, "member")
 	if err != nil {
@@ -200,6 +193,25 @@ func (pm *ProjectManager) DeleteStudentProject(ctx context.Context, student *mod
 	return nil
 }
 
+// DeleteProject deletes a Keystone project; an already missing project is
+// not an error so that compensation can be retried safely.
+func (pm *ProjectManager) DeleteProject(ctx context.Context, projectID string) error {
+	err := projects.Delete(ctx, pm.clients.Identity, projectID).ExtractErr()
+	if err != nil && !gophercloud.ResponseCodeIs(err, http.StatusNotFound) {
+		return fmt.Errorf("failed to delete project %s: %w", projectID, err)
+	}
+	return nil
+}
+
+// DeleteUser deletes a Keystone user; an already missing user is not an error.
+func (pm *ProjectManager) DeleteUser(ctx context.Context, userID string) error {
+	err := users.Delete(ctx, pm.clients.Identity, userID).ExtractErr()
+	if err != nil && !gophercloud.ResponseCodeIs(err, http.StatusNotFound) {
+		return fmt.Errorf("failed to delete user %s: %w", userID, err)
+	}
+	return nil
+}
+
 // ListAllProjects lists all projects in OpenStack
 func (pm *ProjectManager) ListAllProjects(ctx context.Context) ([]projects.Project, error) {
 	// 프로젝트 목록 조회
@@ -216,55 +228,45 @@ func (pm *ProjectManager) ListAllProjects(ctx context.Context) ([]projects.Proje
 	return projectList, nil
 }
 
-// FindStudentProject finds a project by student ID pattern
+// FindStudentProject finds a student's project by its expected name
 func (pm *ProjectManager) FindStudentProject(ctx context.Context, studentID string) (*projects.Project, error) {
-	allProjects, err := pm.ListAllProjects(ctx)
+	did, err := pm.ensureDomainID(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// student-{studentID}-project 패턴으로 검색
-	expectedName := fmt.Sprintf("student-%s-project", studentID)
-
-	for _, project := range allProjects {
-		if project.Name == expectedName {
-			return &project, nil
-		}
+	// student-{studentID}-project 이름으로 검색
+	allPages, err := projects.List(pm.clients.Identity, projects.ListOpts{
+		Name:     StudentProjectName(studentID),
+		DomainID: did,
+	}).AllPages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
 	}
-
-	return nil, fmt.Errorf("project not found for student %s", studentID)
-}
-
-// setDefaultQuotas sets default quotas for a student project using basic profile
-func (pm *ProjectManager) setDefaultQuotas(ctx context.Context, projectID string) error {
-	// models.Profiles의 basic 프로파일 사용
-	basicProfile := models.GetBasicProfile()
-
-	// Nova 쿼타 설정 (basic 프로파일 값)
-	cores := basicProfile.Cores
-	ramMB := basicProfile.RAMMB
-	instances := basicProfile.Instances
-	if err := pm.clients.ApplyNovaQuota(ctx, projectID, &cores, &ramMB, &instances); err != nil {
-		return fmt.Errorf("failed to set Nova quotas: %w", err)
+	projectList, err := projects.ExtractProjects(allPages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract projects: %w", err)
 	}
-
-	// Cinder 쿼타 설정 (basic 프로파일 값)
-	volumes := basicProfile.Volumes
-	gigabytes := basicProfile.Gigabytes
-	snapshots := basicProfile.Snapshots
-	if err := pm.clients.ApplyCinderQuota(ctx, projectID, &volumes, &snapshots, &gigabytes); err != nil {
-		return fmt.Errorf("failed to set Cinder quotas: %w", err)
+	if len(projectList) == 0 {
+		return nil, fmt.Errorf("project %w for student %s", ErrNotFound, studentID)
 	}
+	return &projectList[0], nil
+}
 
-	// Neutron 쿼타 설정
-	ports := basicProfile.Ports
-	floatingIPs := basicProfile.FloatingIPs
-	if err := pm.clients.ApplyNeutronQuota(ctx, projectID, &ports, &floatingIPs); err != nil {
-		return fmt.Errorf("failed to set Neutron quotas: %w", err)
+// ApplyQuotaProfile sets Nova, Cinder and Neutron quotas of a project to
+// the given profile limits (프로파일은 호출자가 카탈로그에서 조회해 넘긴다)
+func (pm *ProjectManager) ApplyQuotaProfile(ctx context.Context, projectID string, profile models.QuotaProfile) error {
+	if _, err := NewQuotaService(pm.clients).ApplyProfile(ctx, projectID, profile); err != nil {
+		var svcErr *QuotaServiceError
+		if errors.As(err, &svcErr) {
+			return fmt.Errorf("failed to set %s quotas: %w", svcErr.Service, err)
+		}
+		return err
 	}
 
-	fmt.Printf("Set basic profile quotas for project %s: vCPU=%d, RAM=%dMB, Instances=%d, Volumes=%d, Disk=%dGB, Ports=%d, FloatingIPs=%d\n",
-		projectID, cores, ramMB, instances, volumes, gigabytes, ports, floatingIPs)
+	fmt.Printf("Set profile quotas for project %s: vCPU=%d, RAM=%dMB, Instances=%d, Volumes=%d, Disk=%dGB, Ports=%d, FloatingIPs=%d, Networks=%d, Routers=%d, VolumeTypes=%d\n",
+		projectID, profile.Cores, profile.RAMMB, profile.Instances, profile.Volumes, profile.Gigabytes,
+		profile.Ports, profile.FloatingIPs, profile.Networks, profile.Routers, len(profile.VolumeTypes))
 	return nil
 }
 
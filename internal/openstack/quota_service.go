@@ -0,0 +1,387 @@
+package openstack
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"example.com/quotaapi/internal/models"
+)
+
+// QuotaServiceName names an OpenStack service that holds project quotas.
+type QuotaServiceName string
+
+const (
+	QuotaNova    QuotaServiceName = "nova"
+	QuotaCinder  QuotaServiceName = "cinder"
+	QuotaNeutron QuotaServiceName = "neutron"
+)
+
+// QuotaThrottle is called before every request to a service (요청 속도 제한용).
+type QuotaThrottle func(ctx context.Context, svc QuotaServiceName) error
+
+// QuotaServiceError is a quota read or update that failed at one service.
+type QuotaServiceError struct {
+	Service QuotaServiceName
+	Err     error
+}
+
+func (e *QuotaServiceError) Error() string { return e.Err.Error() }
+func (e *QuotaServiceError) Unwrap() error { return e.Err }
+
+// QuotaService reads, diffs and applies a complete models.QuotaProfile
+// across Nova, Cinder and Neutron. 핸들러와 리콘실 서비스가 같은 경로로
+// 쿼타를 다루도록 서비스별 호출을 한곳에 모은다.
+type QuotaService struct {
+	clients  *Clients
+	throttle QuotaThrottle
+}
+
+// NewQuotaService creates a quota service without rate limiting.
+func NewQuotaService(clients *Clients) *QuotaService {
+	return &QuotaService{clients: clients}
+}
+
+// WithThrottle sets the hook called before every service request.
+func (s *QuotaService) WithThrottle(throttle QuotaThrottle) *QuotaService {
+	s.throttle = throttle
+	return s
+}
+
+func (s *QuotaService) wait(ctx context.Context, svc QuotaServiceName) error {
+	if s.throttle == nil {
+		return ctx.Err()
+	}
+	return s.throttle(ctx, svc)
+}
+
+// ProjectQuota is the limit and usage of every quota of a project.
+type ProjectQuota struct {
+	ProjectID string              `json:"projectId"`
+	Nova      *NovaQuotaDetail    `json:"nova"`
+	Cinder    *CinderQuotaDetail  `json:"cinder"`
+	Neutron   *NeutronQuotaDetail `json:"neutron"`
+}
+
+// Get reads the project's quota from all three services, stopping at the
+// first service that fails.
+func (s *QuotaService) Get(ctx context.Context, projectID string) (*ProjectQuota, error) {
+	q := &ProjectQuota{ProjectID: projectID}
+	var err error
+
+	if err := s.wait(ctx, QuotaNova); err != nil {
+		return nil, &QuotaServiceError{Service: QuotaNova, Err: err}
+	}
+	if q.Nova, err = s.clients.GetNovaQuotaDetail(ctx, projectID); err != nil {
+		return nil, &QuotaServiceError{Service: QuotaNova, Err: err}
+	}
+
+	if err := s.wait(ctx, QuotaCinder); err != nil {
+		return nil, &QuotaServiceError{Service: QuotaCinder, Err: err}
+	}
+	if q.Cinder, err = s.clients.GetCinderQuotaDetail(ctx, projectID); err != nil {
+		return nil, &QuotaServiceError{Service: QuotaCinder, Err: err}
+	}
+
+	if err := s.wait(ctx, QuotaNeutron); err != nil {
+		return nil, &QuotaServiceError{Service: QuotaNeutron, Err: err}
+	}
+	if q.Neutron, err = s.clients.GetNeutronQuotaDetail(ctx, projectID); err != nil {
+		return nil, &QuotaServiceError{Service: QuotaNeutron, Err: err}
+	}
+	return q, nil
+}
+
+// Limits returns the current limits as a profile.
+func (q *ProjectQuota) Limits() models.QuotaProfile {
+	return q.profile(func(d QuotaDetail) int { return d.Limit })
+}
+
+// Usage returns the in-use counts as a profile.
+func (q *ProjectQuota) Usage() models.QuotaProfile {
+	return q.profile(func(d QuotaDetail) int { return d.InUse })
+}
+
+func (q *ProjectQuota) profile(value func(QuotaDetail) int) models.QuotaProfile {
+	p := models.QuotaProfile{
+		Cores:              value(q.Nova.Cores),
+		RAMMB:              value(q.Nova.RAMMB),
+		Instances:          value(q.Nova.Instances),
+		KeyPairs:           value(q.Nova.KeyPairs),
+		ServerGroups:       value(q.Nova.ServerGroups),
+		ServerGroupMembers: value(q.Nova.ServerGroupMembers),
+		MetadataItems:      value(q.Nova.MetadataItems),
+
+		Volumes:         value(q.Cinder.Volumes),
+		Snapshots:       value(q.Cinder.Snapshots),
+		Gigabytes:       value(q.Cinder.Gigabytes),
+		Backups:         value(q.Cinder.Backups),
+		BackupGigabytes: value(q.Cinder.BackupGigabytes),
+
+		Ports:              value(q.Neutron.Port),
+		FloatingIPs:        value(q.Neutron.FloatingIP),
+		Networks:           value(q.Neutron.Network),
+		Subnets:            value(q.Neutron.Subnet),
+		Routers:            value(q.Neutron.Router),
+		SecurityGroups:     value(q.Neutron.SecurityGroup),
+		SecurityGroupRules: value(q.Neutron.SecurityGroupRule),
+	}
+	if len(q.Cinder.VolumeTypes) > 0 {
+		p.VolumeTypes = make(map[string]models.VolumeTypeQuota, len(q.Cinder.VolumeTypes))
+		for name, t := range q.Cinder.VolumeTypes {
+			p.VolumeTypes[name] = models.VolumeTypeQuota{Volumes: value(t.Volumes), Gigabytes: value(t.Gigabytes)}
+		}
+	}
+	return p
+}
+
+// QuotaChange is one limit whose current value differs from the target.
+// 볼륨 타입별 한도는 Cinder 처럼 volumes_<type>, gigabytes_<type> 이름을 쓴다.
+type QuotaChange struct {
+	Resource string `json:"resource"`
+	Current  int    `json:"current"`
+	Target   int    `json:"target"`
+}
+
+// QuotaDiff lists the changes applying a profile would make, per service.
+type QuotaDiff struct {
+	Nova    []QuotaChange `json:"nova"`
+	Cinder  []QuotaChange `json:"cinder"`
+	Neutron []QuotaChange `json:"neutron"`
+}
+
+// IsEmpty reports whether applying the profile would change nothing.
+func (d QuotaDiff) IsEmpty() bool {
+	return len(d.Nova) == 0 && len(d.Cinder) == 0 && len(d.Neutron) == 0
+}
+
+// Diff compares the current limits with a target profile. Cinder 는
+// 프로파일에 있는 볼륨 타입만 비교하며(적용도 그 타입만 바꾼다), OpenStack 에
+// 없는 타입의 현재 값은 -1 (제한 없음) 이다.
+func (q *ProjectQuota) Diff(target models.QuotaProfile) QuotaDiff {
+	current := q.Limits()
+	changed := func(all ...QuotaChange) []QuotaChange {
+		out := []QuotaChange{}
+		for _, c := range all {
+			if c.Current != c.Target {
+				out = append(out, c)
+			}
+		}
+		return out
+	}
+
+	diff := QuotaDiff{
+		Nova: changed(
+			QuotaChange{"instances", current.Instances, target.Instances},
+			QuotaChange{"cores", current.Cores, target.Cores},
+			QuotaChange{"ramMB", current.RAMMB, target.RAMMB},
+			QuotaChange{"keyPairs", current.KeyPairs, target.KeyPairs},
+			QuotaChange{"serverGroups", current.ServerGroups, target.ServerGroups},
+			QuotaChange{"serverGroupMembers", current.ServerGroupMembers, target.ServerGroupMembers},
+			QuotaChange{"metadataItems", current.MetadataItems, target.MetadataItems},
+		),
+		Cinder: changed(
+			QuotaChange{"volumes", current.Volumes, target.Volumes},
+			QuotaChange{"gigabytes", current.Gigabytes, target.Gigabytes},
+			QuotaChange{"snapshots", current.Snapshots, target.Snapshots},
+			QuotaChange{"backups", current.Backups, target.Backups},
+			QuotaChange{"backupGigabytes", current.BackupGigabytes, target.BackupGigabytes},
+		),
+		Neutron: changed(
+			QuotaChange{"ports", current.Ports, target.Ports},
+			QuotaChange{"floatingIPs", current.FloatingIPs, target.FloatingIPs},
+			QuotaChange{"networks", current.Networks, target.Networks},
+			QuotaChange{"subnets", current.Subnets, target.Subnets},
+			QuotaChange{"routers", current.Routers, target.Routers},
+			QuotaChange{"securityGroups", current.SecurityGroups, target.SecurityGroups},
+			QuotaChange{"securityGroupRules", current.SecurityGroupRules, target.SecurityGroupRules},
+		),
+	}
+
+	names := make([]string, 0, len(target.VolumeTypes))
+	for name := range target.VolumeTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		want := target.VolumeTypes[name]
+		got, ok := current.VolumeTypes[name]
+		if !ok {
+			got = models.VolumeTypeQuota{Volumes: -1, Gigabytes: -1}
+		}
+		diff.Cinder = append(diff.Cinder, changed(
+			QuotaChange{volumeTypeVolumesPrefix + name, got.Volumes, want.Volumes},
+			QuotaChange{volumeTypeGigabytesPrefix + name, got.Gigabytes, want.Gigabytes},
+		)...)
+	}
+	return diff
+}
+
+// Diff reads the project's quota and compares it with the target profile.
+func (s *QuotaService) Diff(ctx context.Context, projectID string, target models.QuotaProfile) (*ProjectQuota, QuotaDiff, error) {
+	current, err := s.Get(ctx, projectID)
+	if err != nil {
+		return nil, QuotaDiff{}, err
+	}
+	return current, current.Diff(target), nil
+}
+
+// QuotaUpdate lists the limits to change in every service; nil fields keep
+// their current value.
+type QuotaUpdate struct {
+	Nova    NovaQuotaUpdate
+	Cinder  CinderQuotaUpdate
+	Neutron NeutronQuotaUpdate
+}
+
+// QuotaUpdateFromProfile sets every limit of the profile.
+func QuotaUpdateFromProfile(profile models.QuotaProfile) QuotaUpdate {
+	return QuotaUpdate{
+		Nova:    NovaQuotaFromProfile(profile),
+		Cinder:  CinderQuotaFromProfile(profile),
+		Neutron: NeutronQuotaFromProfile(profile),
+	}
+}
+
+// QuotaApplyStatus is the outcome of one service in an apply.
+type QuotaApplyStatus string
+
+const (
+	QuotaApplied        QuotaApplyStatus = "applied"
+	QuotaSkipped        QuotaApplyStatus = "skipped"         // 바꿀 항목이 없음
+	QuotaFailed         QuotaApplyStatus = "failed"          // 이 서비스에서 실패 (바뀌지 않음)
+	QuotaNotAttempted   QuotaApplyStatus = "not_attempted"   // 앞 서비스가 실패해 시도하지 않음
+	QuotaRolledBack     QuotaApplyStatus = "rolled_back"     // 적용했다가 이전 값으로 되돌림
+	QuotaRollbackFailed QuotaApplyStatus = "rollback_failed" // 적용된 채로 남음, 수동 확인 필요
+)
+
+// QuotaServiceResult is what an apply did at one service.
+type QuotaServiceResult struct {
+	Service       QuotaServiceName `json:"service"`
+	Status        QuotaApplyStatus `json:"status"`
+	Error         string           `json:"error,omitempty"`
+	RollbackError string           `json:"rollback_error,omitempty"`
+}
+
+// quotaRollbackTimeout bounds the rollback after a failed apply. 요청
+// context 가 취소되어 실패한 경우에도 되돌리기는 끝까지 시도한다.
+const quotaRollbackTimeout = 30 * time.Second
+
+// Apply reads the project's current limits and applies the update on top
+// of them (ApplyFrom). 현재 값을 읽지 못하면 아무것도 바꾸지 않는다.
+func (s *QuotaService) Apply(ctx context.Context, projectID string, u QuotaUpdate) ([]QuotaServiceResult, error) {
+	prior, err := s.Get(ctx, projectID)
+	if err != nil {
+		results := make([]QuotaServiceResult, 0, 3)
+		for _, svc := range []QuotaServiceName{QuotaNova, QuotaCinder, QuotaNeutron} {
+			results = append(results, QuotaServiceResult{Service: svc, Status: QuotaNotAttempted})
+		}
+		return results, err
+	}
+	return s.ApplyFrom(ctx, prior, u)
+}
+
+// ApplyFrom applies the update to Nova, Cinder and Neutron in that order
+// as one unit. prior 는 적용 직전에 읽은 프로젝트 쿼타이며, 한 서비스가
+// 실패하면 이미 바꾼 서비스를 역순으로 prior 의 값으로 되돌린다. 실패한
+// 서비스의 *QuotaServiceError 를 돌려주고, 되돌리기 결과는 서비스별
+// Status (rolled_back, rollback_failed) 로 알린다.
+func (s *QuotaService) ApplyFrom(ctx context.Context, prior *ProjectQuota, u QuotaUpdate) ([]QuotaServiceResult, error) {
+	projectID := prior.ProjectID
+	before := prior.Limits()
+	// Cinder 는 바꾼 볼륨 타입만 되돌린다 (없던 타입은 -1, 제한 없음)
+	restoreCinder := CinderQuotaFromProfile(before)
+	restoreCinder.VolumeTypes = make(map[string]models.VolumeTypeQuota, len(u.Cinder.VolumeTypes))
+	for name := range u.Cinder.VolumeTypes {
+		t, ok := before.VolumeTypes[name]
+		if !ok {
+			t = models.VolumeTypeQuota{Volumes: -1, Gigabytes: -1}
+		}
+		restoreCinder.VolumeTypes[name] = t
+	}
+
+	steps := []struct {
+		service QuotaServiceName
+		empty   bool
+		apply   func(ctx context.Context) error
+		restore func(ctx context.Context) error
+	}{
+		{
+			QuotaNova, u.Nova.IsEmpty(),
+			func(ctx context.Context) error { return s.clients.ApplyNovaQuota(ctx, projectID, u.Nova) },
+			func(ctx context.Context) error {
+				return s.clients.ApplyNovaQuota(ctx, projectID, NovaQuotaFromProfile(before))
+			},
+		},
+		{
+			QuotaCinder, u.Cinder.IsEmpty(),
+			func(ctx context.Context) error { return s.clients.ApplyCinderQuota(ctx, projectID, u.Cinder) },
+			func(ctx context.Context) error { return s.clients.ApplyCinderQuota(ctx, projectID, restoreCinder) },
+		},
+		{
+			QuotaNeutron, u.Neutron.IsEmpty(),
+			func(ctx context.Context) error { return s.clients.ApplyNeutronQuota(ctx, projectID, u.Neutron) },
+			func(ctx context.Context) error {
+				return s.clients.ApplyNeutronQuota(ctx, projectID, NeutronQuotaFromProfile(before))
+			},
+		},
+	}
+
+	results := make([]QuotaServiceResult, len(steps))
+	var failed *QuotaServiceError
+	for i, step := range steps {
+		results[i].Service = step.service
+		switch {
+		case failed != nil:
+			results[i].Status = QuotaNotAttempted
+			continue
+		case step.empty:
+			results[i].Status = QuotaSkipped
+			continue
+		}
+
+		err := s.wait(ctx, step.service)
+		if err == nil {
+			err = step.apply(ctx)
+		}
+		if err != nil {
+			failed = &QuotaServiceError{Service: step.service, Err: err}
+			results[i].Status, results[i].Error = QuotaFailed, err.Error()
+			continue
+		}
+		results[i].Status = QuotaApplied
+	}
+	if failed == nil {
+		return results, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), quotaRollbackTimeout)
+	defer cancel()
+	var stuck []string
+	for i := len(steps) - 1; i >= 0; i-- {
+		if results[i].Status != QuotaApplied {
+			continue
+		}
+		err := s.wait(rctx, steps[i].service)
+		if err == nil {
+			err = steps[i].restore(rctx)
+		}
+		if err != nil {
+			results[i].Status, results[i].RollbackError = QuotaRollbackFailed, err.Error()
+			stuck = append(stuck, string(steps[i].service))
+			continue
+		}
+		results[i].Status = QuotaRolledBack
+	}
+	if len(stuck) > 0 {
+		return results, fmt.Errorf("%w (rollback failed, left applied: %s)", failed, strings.Join(stuck, ", "))
+	}
+	return results, failed
+}
+
+// ApplyProfile sets every limit of the project to the profile.
+func (s *QuotaService) ApplyProfile(ctx context.Context, projectID string, profile models.QuotaProfile) ([]QuotaServiceResult, error) {
+	return s.Apply(ctx, projectID, QuotaUpdateFromProfile(profile))
+}
@@ -0,0 +1,127 @@
+package openstack_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"example.com/quotaapi/internal/openstack"
+	"example.com/quotaapi/internal/openstack/openstacktest"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestApplyFromRollback(t *testing.T) {
+	update := openstack.QuotaUpdate{
+		Nova:    openstack.NovaQuotaUpdate{Cores: intPtr(12)},
+		Cinder:  openstack.CinderQuotaUpdate{Volumes: intPtr(7)},
+		Neutron: openstack.NeutronQuotaUpdate{Routers: intPtr(3)},
+	}
+	tests := []struct {
+		name   string
+		update openstack.QuotaUpdate
+		fault  *openstacktest.Fault
+		// onWait 는 서비스 요청 직전에 호출된다 (되돌리기 실패를 중간에 주입하는 데 쓴다)
+		onWait      func(fake *openstacktest.Server, svc openstack.QuotaServiceName)
+		wantStatus  []openstack.QuotaApplyStatus // nova, cinder, neutron
+		wantFailed  openstack.QuotaServiceName   // 빈 값이면 성공
+		wantCores   int
+		wantVolumes int
+	}{
+		{
+			name:        "all applied",
+			update:      update,
+			wantStatus:  []openstack.QuotaApplyStatus{openstack.QuotaApplied, openstack.QuotaApplied, openstack.QuotaApplied},
+			wantCores:   12,
+			wantVolumes: 7,
+		},
+		{
+			name:        "unchanged service is skipped",
+			update:      openstack.QuotaUpdate{Nova: update.Nova},
+			wantStatus:  []openstack.QuotaApplyStatus{openstack.QuotaApplied, openstack.QuotaSkipped, openstack.QuotaSkipped},
+			wantCores:   12,
+			wantVolumes: 10,
+		},
+		{
+			name:        "cinder fails after nova, nova restored",
+			update:      update,
+			fault:       &openstacktest.Fault{Method: http.MethodPut, Path: "/volume/v3/os-quota-sets", Status: http.StatusServiceUnavailable, Times: 1},
+			wantStatus:  []openstack.QuotaApplyStatus{openstack.QuotaRolledBack, openstack.QuotaFailed, openstack.QuotaNotAttempted},
+			wantFailed:  openstack.QuotaCinder,
+			wantCores:   20,
+			wantVolumes: 10,
+		},
+		{
+			name:   "neutron fails and nova rollback fails",
+			update: update,
+			fault:  &openstacktest.Fault{Method: http.MethodPut, Path: "/networking/v2.0/quotas", Status: http.StatusInternalServerError, Times: 1},
+			onWait: func(fake *openstacktest.Server, svc openstack.QuotaServiceName) {
+				if svc == openstack.QuotaNeutron {
+					fake.Inject(openstacktest.Fault{Method: http.MethodPut, Path: "/compute/v2.1/os-quota-sets", Status: http.StatusServiceUnavailable})
+				}
+			},
+			wantStatus:  []openstack.QuotaApplyStatus{openstack.QuotaRollbackFailed, openstack.QuotaRolledBack, openstack.QuotaFailed},
+			wantFailed:  openstack.QuotaNeutron,
+			wantCores:   12, // 되돌리지 못해 적용된 값이 남는다
+			wantVolumes: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := openstacktest.NewServer()
+			defer fake.Close()
+			osc, err := openstack.NewServiceClients(fake.Config())
+			if err != nil {
+				t.Fatal(err)
+			}
+			pid := fake.AddProject("apply", "")
+			ctx := context.Background()
+
+			applying := false // Get 의 요청에는 onWait 를 적용하지 않는다
+			quotas := openstack.NewQuotaService(osc).WithThrottle(func(ctx context.Context, svc openstack.QuotaServiceName) error {
+				if applying && tt.onWait != nil {
+					tt.onWait(fake, svc)
+				}
+				return ctx.Err()
+			})
+			prior, err := quotas.Get(ctx, pid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.fault != nil {
+				fake.Inject(*tt.fault)
+			}
+
+			applying = true
+			results, err := quotas.ApplyFrom(ctx, prior, tt.update)
+			var svcErr *openstack.QuotaServiceError
+			switch {
+			case tt.wantFailed == "" && err != nil:
+				t.Fatalf("err = %v, want success", err)
+			case tt.wantFailed != "" && (!errors.As(err, &svcErr) || svcErr.Service != tt.wantFailed):
+				t.Fatalf("err = %v, want failure at %s", err, tt.wantFailed)
+			}
+			if tt.wantStatus[0] == openstack.QuotaRollbackFailed && !strings.Contains(err.Error(), "rollback failed") {
+				t.Errorf("err = %v, want rollback failure reported", err)
+			}
+
+			if len(results) != len(tt.wantStatus) {
+				t.Fatalf("results = %+v", results)
+			}
+			for i, want := range tt.wantStatus {
+				if results[i].Status != want {
+					t.Errorf("%s status = %s, want %s", results[i].Service, results[i].Status, want)
+				}
+			}
+			fake.ClearFaults()
+			if got := fake.Quota(openstacktest.Compute, pid)["cores"].Limit; got != tt.wantCores {
+				t.Errorf("cores = %d, want %d", got, tt.wantCores)
+			}
+			if got := fake.Quota(openstacktest.BlockStorage, pid)["volumes"].Limit; got != tt.wantVolumes {
+				t.Errorf("volumes = %d, want %d", got, tt.wantVolumes)
+			}
+		})
+	}
+}
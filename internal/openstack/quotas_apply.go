@@ -4,16 +4,53 @@ import (
 	"context"
 	"fmt"
 
+	"example.com/quotaapi/internal/models"
+
 	cqs "github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/quotasets"
 	novaqs "github.com/gophercloud/gophercloud/v2/openstack/compute/v2/quotasets"
 	neutronqs "github.com/gophercloud/gophercloud/v2/openstack/networking/v2/extensions/quotas"
 )
 
-func (c *Clients) ApplyNovaQuota(ctx context.Context, projectID string, cores, ramMB, instances *int) error {
+// NovaQuotaUpdate lists the Nova limits to change; nil fields keep their
+// current value.
+type NovaQuotaUpdate struct {
+	Cores              *int `json:"cores"`
+	RAMMB              *int `json:"ramMB"`
+	Instances          *int `json:"instances"`
+	KeyPairs           *int `json:"keyPairs"`
+	ServerGroups       *int `json:"serverGroups"`
+	ServerGroupMembers *int `json:"serverGroupMembers"`
+	MetadataItems      *int `json:"metadataItems"`
+}
+
+// IsEmpty reports whether the update changes nothing.
+func (u NovaQuotaUpdate) IsEmpty() bool {
+	return u.Cores == nil && u.RAMMB == nil && u.Instances == nil && u.KeyPairs == nil &&
+		u.ServerGroups == nil && u.ServerGroupMembers == nil && u.MetadataItems == nil
+}
+
+// NovaQuotaFromProfile sets every Nova limit of the profile.
+func NovaQuotaFromProfile(profile models.QuotaProfile) NovaQuotaUpdate {
+	return NovaQuotaUpdate{
+		Cores:              &profile.Cores,
+		RAMMB:              &profile.RAMMB,
+		Instances:          &profile.Instances,
+		KeyPairs:           &profile.KeyPairs,
+		ServerGroups:       &profile.ServerGroups,
+		ServerGroupMembers: &profile.ServerGroupMembers,
+		MetadataItems:      &profile.MetadataItems,
+	}
+}
+
+func (c *Clients) ApplyNovaQuota(ctx context.Context, projectID string, q NovaQuotaUpdate) error {
 	opts := novaqs.UpdateOpts{
-		Cores:     cores,
-		RAM:       ramMB,
-		Instances: instances,
+		Cores:              q.Cores,
+		RAM:                q.RAMMB,
+		Instances:          q.Instances,
+		KeyPairs:           q.KeyPairs,
+		ServerGroups:       q.ServerGroups,
+		ServerGroupMembers: q.ServerGroupMembers,
+		MetadataItems:      q.MetadataItems,
 	}
 	_, err := novaqs.Update(ctx, c.ComputeV2, projectID, opts).Extract()
 	if err != nil {
@@ -22,11 +59,52 @@ func (c *Clients) ApplyNovaQuota(ctx context.Context, projectID string, cores, r
 	return nil
 }
 
-func (c *Clients) ApplyCinderQuota(ctx context.Context, projectID string, volumes, snapshots, gigabytes *int) error {
+// CinderQuotaUpdate lists the Cinder limits to change; nil fields and
+// volume types missing from VolumeTypes keep their current value.
+type CinderQuotaUpdate struct {
+	Volumes         *int                              `json:"volumes"`
+	Snapshots       *int                              `json:"snapshots"`
+	Gigabytes       *int                              `json:"gigabytes"`
+	Backups         *int                              `json:"backups"`
+	BackupGigabytes *int                              `json:"backupGigabytes"`
+	VolumeTypes     map[string]models.VolumeTypeQuota `json:"volumeTypes"` // -1 = 타입별 제한 없음
+}
+
+// IsEmpty reports whether the update changes nothing.
+func (u CinderQuotaUpdate) IsEmpty() bool {
+	return u.Volumes == nil && u.Snapshots == nil && u.Gigabytes == nil &&
+		u.Backups == nil && u.BackupGigabytes == nil && len(u.VolumeTypes) == 0
+}
+
+// CinderQuotaFromProfile sets every Cinder limit of the profile, including
+// the volume types it lists.
+func CinderQuotaFromProfile(profile models.QuotaProfile) CinderQuotaUpdate {
+	return CinderQuotaUpdate{
+		Volumes:         &profile.Volumes,
+		Snapshots:       &profile.Snapshots,
+		Gigabytes:       &profile.Gigabytes,
+		Backups:         &profile.Backups,
+		BackupGigabytes: &profile.BackupGigabytes,
+		VolumeTypes:     profile.VolumeTypes,
+	}
+}
+
+// ApplyCinderQuota applies Cinder quotas. 볼륨 타입별 한도는 Cinder 의
+// volumes_<type>, gigabytes_<type> 키로 보낸다.
+func (c *Clients) ApplyCinderQuota(ctx context.Context, projectID string, q CinderQuotaUpdate) error {
 	opts := cqs.UpdateOpts{
-		Volumes:   volumes,
-		Snapshots: snapshots,
-		Gigabytes: gigabytes,
+		Volumes:         q.Volumes,
+		Snapshots:       q.Snapshots,
+		Gigabytes:       q.Gigabytes,
+		Backups:         q.Backups,
+		BackupGigabytes: q.BackupGigabytes,
+	}
+	if len(q.VolumeTypes) > 0 {
+		opts.Extra = map[string]any{}
+		for name, t := range q.VolumeTypes {
+			opts.Extra[volumeTypeVolumesPrefix+name] = t.Volumes
+			opts.Extra[volumeTypeGigabytesPrefix+name] = t.Gigabytes
+		}
 	}
 
 	_, err := cqs.Update(ctx, c.BlockStorageV3, projectID, opts).Extract()
@@ -36,11 +114,48 @@ func (c *Clients) ApplyCinderQuota(ctx context.Context, projectID string, volume
 	return nil
 }
 
-// ApplyNeutronQuota applies Neutron quotas for ports and floating IPs
-func (c *Clients) ApplyNeutronQuota(ctx context.Context, projectID string, ports, floatingIPs *int) error {
+// NeutronQuotaUpdate lists the Neutron limits to change; nil fields keep
+// their current value.
+type NeutronQuotaUpdate struct {
+	Ports              *int `json:"port,omitempty"`
+	FloatingIPs        *int `json:"floatingIP,omitempty"`
+	Networks           *int `json:"network,omitempty"`
+	Subnets            *int `json:"subnet,omitempty"`
+	Routers            *int `json:"router,omitempty"`
+	SecurityGroups     *int `json:"securityGroup,omitempty"`
+	SecurityGroupRules *int `json:"securityGroupRule,omitempty"`
+}
+
+// IsEmpty reports whether the update changes nothing.
+func (u NeutronQuotaUpdate) IsEmpty() bool {
+	return u.Ports == nil && u.FloatingIPs == nil && u.Networks == nil && u.Subnets == nil &&
+		u.Routers == nil && u.SecurityGroups == nil && u.SecurityGroupRules == nil
+}
+
+// NeutronQuotaFromProfile sets every Neutron limit of the profile.
+func NeutronQuotaFromProfile(profile models.QuotaProfile) NeutronQuotaUpdate {
+	return NeutronQuotaUpdate{
+		Ports:              &profile.Ports,
+		FloatingIPs:        &profile.FloatingIPs,
+		Networks:           &profile.Networks,
+		Subnets:            &profile.Subnets,
+		Routers:            &profile.Routers,
+		SecurityGroups:     &profile.SecurityGroups,
+		SecurityGroupRules: &profile.SecurityGroupRules,
+	}
+}
+
+// ApplyNeutronQuota applies Neutron quotas for ports, floating IPs,
+// networks, subnets, routers, security groups and their rules
+func (c *Clients) ApplyNeutronQuota(ctx context.Context, projectID string, q NeutronQuotaUpdate) error {
 	opts := neutronqs.UpdateOpts{
-		Port:       ports,
-		FloatingIP: floatingIPs,
+		Port:              q.Ports,
+		FloatingIP:        q.FloatingIPs,
+		Network:           q.Networks,
+		Subnet:            q.Subnets,
+		Router:            q.Routers,
+		SecurityGroup:     q.SecurityGroups,
+		SecurityGroupRule: q.SecurityGroupRules,
 	}
 
 	_, err := neutronqs.Update(ctx, c.NetworkV2, projectID, opts).Extract()
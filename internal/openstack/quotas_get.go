@@ -2,11 +2,22 @@ package openstack
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 
+	"github.com/gophercloud/gophercloud/v2"
 	cqs "github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/quotasets"
 	novaqs "github.com/gophercloud/gophercloud/v2/openstack/compute/v2/quotasets"
+	floatingips "github.com/gophercloud/gophercloud/v2/openstack/networking/v2/extensions/layer3/floatingips"
+	routers "github.com/gophercloud/gophercloud/v2/openstack/networking/v2/extensions/layer3/routers"
 	neutronqs "github.com/gophercloud/gophercloud/v2/openstack/networking/v2/extensions/quotas"
+	secgroups "github.com/gophercloud/gophercloud/v2/openstack/networking/v2/extensions/security/groups"
+	secrules "github.com/gophercloud/gophercloud/v2/openstack/networking/v2/extensions/security/rules"
+	networks "github.com/gophercloud/gophercloud/v2/openstack/networking/v2/networks"
+	ports "github.com/gophercloud/gophercloud/v2/openstack/networking/v2/ports"
+	subnets "github.com/gophercloud/gophercloud/v2/openstack/networking/v2/subnets"
 )
 
 type QuotaDetail struct {
@@ -15,20 +26,45 @@ type QuotaDetail struct {
 }
 
 type NovaQuotaDetail struct {
-	Cores     QuotaDetail `json:"cores"`
-	RAMMB     QuotaDetail `json:"ramMB"` // MB
-	Instances QuotaDetail `json:"instances"`
+	Cores              QuotaDetail `json:"cores"`
+	RAMMB              QuotaDetail `json:"ramMB"` // MB
+	Instances          QuotaDetail `json:"instances"`
+	KeyPairs           QuotaDetail `json:"keyPairs"`
+	ServerGroups       QuotaDetail `json:"serverGroups"`
+	ServerGroupMembers QuotaDetail `json:"serverGroupMembers"`
+	MetadataItems      QuotaDetail `json:"metadataItems"`
 }
 
 type CinderQuotaDetail struct {
-	Gigabytes QuotaDetail `json:"gigabytes"` // GB
+	Gigabytes       QuotaDetail                      `json:"gigabytes"` // GB
+	Volumes         QuotaDetail                      `json:"volumes"`
+	Snapshots       QuotaDetail                      `json:"snapshots"`
+	Backups         QuotaDetail                      `json:"backups"`
+	BackupGigabytes QuotaDetail                      `json:"backupGigabytes"` // GB
+	VolumeTypes     map[string]VolumeTypeQuotaDetail `json:"volumeTypes,omitempty"`
+}
+
+// VolumeTypeQuotaDetail is the per-volume-type part of a Cinder quota
+// (limit -1 = 타입별 제한 없음, 전체 volumes/gigabytes 만 적용).
+type VolumeTypeQuotaDetail struct {
 	Volumes   QuotaDetail `json:"volumes"`
-	Snapshots QuotaDetail `json:"snapshots"`
+	Gigabytes QuotaDetail `json:"gigabytes"`
 }
 
+// Cinder reports and accepts per-volume-type limits as <prefix><type>.
+const (
+	volumeTypeVolumesPrefix   = "volumes_"
+	volumeTypeGigabytesPrefix = "gigabytes_"
+)
+
 type NeutronQuotaDetail struct {
-	Port       QuotaDetail `json:"port"`
-	FloatingIP QuotaDetail `json:"floatingIP"`
+	Port              QuotaDetail `json:"port"`
+	FloatingIP        QuotaDetail `json:"floatingIP"`
+	Network           QuotaDetail `json:"network"`
+	Subnet            QuotaDetail `json:"subnet"`
+	Router            QuotaDetail `json:"router"`
+	SecurityGroup     QuotaDetail `json:"securityGroup"`
+	SecurityGroupRule QuotaDetail `json:"securityGroupRule"`
 }
 
 func (c *Clients) GetNovaQuotaDetail(ctx context.Context, projectID string) (*NovaQuotaDetail, error) {
@@ -37,35 +73,180 @@ func (c *Clients) GetNovaQuotaDetail(ctx context.Context, projectID string) (*No
 		return nil, fmt.Errorf("nova get quota detail: %w", err)
 	}
 	return &NovaQuotaDetail{
-		Cores:     QuotaDetail{Limit: q.Cores.Limit, InUse: q.Cores.InUse},
-		RAMMB:     QuotaDetail{Limit: q.RAM.Limit, InUse: q.RAM.InUse},
-		Instances: QuotaDetail{Limit: q.Instances.Limit, InUse: q.Instances.InUse},
+		Cores:              QuotaDetail{Limit: q.Cores.Limit, InUse: q.Cores.InUse},
+		RAMMB:              QuotaDetail{Limit: q.RAM.Limit, InUse: q.RAM.InUse},
+		Instances:          QuotaDetail{Limit: q.Instances.Limit, InUse: q.Instances.InUse},
+		KeyPairs:           QuotaDetail{Limit: q.KeyPairs.Limit, InUse: q.KeyPairs.InUse},
+		ServerGroups:       QuotaDetail{Limit: q.ServerGroups.Limit, InUse: q.ServerGroups.InUse},
+		ServerGroupMembers: QuotaDetail{Limit: q.ServerGroupMembers.Limit, InUse: q.ServerGroupMembers.InUse},
+		MetadataItems:      QuotaDetail{Limit: q.MetadataItems.Limit, InUse: q.MetadataItems.InUse},
 	}, nil
 }
 
 // ----- Cinder 상세 조회 -----
 func (c *Clients) GetCinderQuotaDetail(ctx context.Context, targetProjectID string) (*CinderQuotaDetail, error) {
 	// v3: admin project 경로 사용
-	q, err := cqs.GetUsage(ctx, c.BlockStorageV3, targetProjectID).Extract()
+	res := cqs.GetUsage(ctx, c.BlockStorageV3, targetProjectID)
+	q, err := res.Extract()
 	if err != nil {
 		return nil, fmt.Errorf("cinder get quota detail: %w", err)
 	}
-	return &CinderQuotaDetail{
-		Gigabytes: QuotaDetail{Limit: q.Gigabytes.Limit, InUse: q.Gigabytes.InUse},
-		Volumes:   QuotaDetail{Limit: q.Volumes.Limit, InUse: q.Volumes.InUse},
-		Snapshots: QuotaDetail{Limit: q.Snapshots.Limit, InUse: q.Snapshots.InUse},
-	}, nil
+	detail := &CinderQuotaDetail{
+		Gigabytes:       QuotaDetail{Limit: q.Gigabytes.Limit, InUse: q.Gigabytes.InUse},
+		Volumes:         QuotaDetail{Limit: q.Volumes.Limit, InUse: q.Volumes.InUse},
+		Snapshots:       QuotaDetail{Limit: q.Snapshots.Limit, InUse: q.Snapshots.InUse},
+		Backups:         QuotaDetail{Limit: q.Backups.Limit, InUse: q.Backups.InUse},
+		BackupGigabytes: QuotaDetail{Limit: q.BackupGigabytes.Limit, InUse: q.BackupGigabytes.InUse},
+	}
+
+	// 볼륨 타입별 항목은 gophercloud 구조체에 없으므로 원본에서 읽는다
+	var raw struct {
+		QuotaSet map[string]json.RawMessage `json:"quota_set"`
+	}
+	if err := res.ExtractInto(&raw); err != nil {
+		return nil, fmt.Errorf("cinder get quota detail: %w", err)
+	}
+	for key, value := range raw.QuotaSet {
+		var prefix string
+		switch {
+		case strings.HasPrefix(key, volumeTypeVolumesPrefix):
+			prefix = volumeTypeVolumesPrefix
+		case strings.HasPrefix(key, volumeTypeGigabytesPrefix):
+			prefix = volumeTypeGigabytesPrefix
+		default:
+			continue
+		}
+		var usage cqs.QuotaUsage
+		if err := json.Unmarshal(value, &usage); err != nil {
+			return nil, fmt.Errorf("cinder quota %s: %w", key, err)
+		}
+		name := strings.TrimPrefix(key, prefix)
+		if detail.VolumeTypes == nil {
+			detail.VolumeTypes = map[string]VolumeTypeQuotaDetail{}
+		}
+		t := detail.VolumeTypes[name]
+		if prefix == volumeTypeVolumesPrefix {
+			t.Volumes = QuotaDetail{Limit: usage.Limit, InUse: usage.InUse}
+		} else {
+			t.Gigabytes = QuotaDetail{Limit: usage.Limit, InUse: usage.InUse}
+		}
+		detail.VolumeTypes[name] = t
+	}
+	return detail, nil
 }
 
-// GetNeutronQuotaDetail gets detailed Neutron quotas for a project
+// GetNeutronQuotaDetail gets Neutron limits and usage for a project. 사용량은
+// quota details 확장(quotas/{id}/details)에서 읽고, 확장이 없는 배포에서는
+// 프로젝트 자원을 직접 세어 채운다.
 func (c *Clients) GetNeutronQuotaDetail(ctx context.Context, projectID string) (*NeutronQuotaDetail, error) {
-	quota, err := neutronqs.Get(ctx, c.NetworkV2, projectID).Extract()
+	q, err := neutronqs.GetDetail(ctx, c.NetworkV2, projectID).Extract()
+	if gophercloud.ResponseCodeIs(err, http.StatusNotFound) {
+		return c.countNeutronUsage(ctx, projectID)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("get neutron quota: %w", err)
+		return nil, fmt.Errorf("neutron get quota detail: %w", err)
 	}
 
+	// reserved 는 생성 중인 자원이라 곧 사용량이 되므로 함께 센다
+	detail := func(d neutronqs.QuotaDetail) QuotaDetail {
+		return QuotaDetail{Limit: d.Limit, InUse: d.Used + d.Reserved}
+	}
 	return &NeutronQuotaDetail{
-		Port:       QuotaDetail{Limit: quota.Port, InUse: 0}, // Neutron doesn't provide InUse by default
-		FloatingIP: QuotaDetail{Limit: quota.FloatingIP, InUse: 0},
+		Port:              detail(q.Port),
+		FloatingIP:        detail(q.FloatingIP),
+		Network:           detail(q.Network),
+		Subnet:            detail(q.Subnet),
+		Router:            detail(q.Router),
+		SecurityGroup:     detail(q.SecurityGroup),
+		SecurityGroupRule: detail(q.SecurityGroupRule),
 	}, nil
 }
+
+// countNeutronUsage reads the limits and counts the resources the project
+// owns, for Neutron deployments without the quota details extension.
+func (c *Clients) countNeutronUsage(ctx context.Context, projectID string) (*NeutronQuotaDetail, error) {
+	quota, err := neutronqs.Get(ctx, c.NetworkV2, projectID).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("get neutron quota: %w", err)
+	}
+	out := &NeutronQuotaDetail{
+		Port:              QuotaDetail{Limit: quota.Port},
+		FloatingIP:        QuotaDetail{Limit: quota.FloatingIP},
+		Network:           QuotaDetail{Limit: quota.Network},
+		Subnet:            QuotaDetail{Limit: quota.Subnet},
+		Router:            QuotaDetail{Limit: quota.Router},
+		SecurityGroup:     QuotaDetail{Limit: quota.SecurityGroup},
+		SecurityGroupRule: QuotaDetail{Limit: quota.SecurityGroupRule},
+	}
+
+	counts := []struct {
+		name  string
+		into  *int
+		count func() (int, error)
+	}{
+		{"ports", &out.Port.InUse, func() (int, error) {
+			pages, err := ports.List(c.NetworkV2, ports.ListOpts{ProjectID: projectID}).AllPages(ctx)
+			if err != nil {
+				return 0, err
+			}
+			list, err := ports.ExtractPorts(pages)
+			return len(list), err
+		}},
+		{"floating IPs", &out.FloatingIP.InUse, func() (int, error) {
+			pages, err := floatingips.List(c.NetworkV2, floatingips.ListOpts{ProjectID: projectID}).AllPages(ctx)
+			if err != nil {
+				return 0, err
+			}
+			list, err := floatingips.ExtractFloatingIPs(pages)
+			return len(list), err
+		}},
+		{"networks", &out.Network.InUse, func() (int, error) {
+			pages, err := networks.List(c.NetworkV2, networks.ListOpts{ProjectID: projectID}).AllPages(ctx)
+			if err != nil {
+				return 0, err
+			}
+			list, err := networks.ExtractNetworks(pages)
+			return len(list), err
+		}},
+		{"subnets", &out.Subnet.InUse, func() (int, error) {
+			pages, err := subnets.List(c.NetworkV2, subnets.ListOpts{ProjectID: projectID}).AllPages(ctx)
+			if err != nil {
+				return 0, err
+			}
+			list, err := subnets.ExtractSubnets(pages)
+			return len(list), err
+		}},
+		{"routers", &out.Router.InUse, func() (int, error) {
+			pages, err := routers.List(c.NetworkV2, routers.ListOpts{ProjectID: projectID}).AllPages(ctx)
+			if err != nil {
+				return 0, err
+			}
+			list, err := routers.ExtractRouters(pages)
+			return len(list), err
+		}},
+		{"security groups", &out.SecurityGroup.InUse, func() (int, error) {
+			pages, err := secgroups.List(c.NetworkV2, secgroups.ListOpts{ProjectID: projectID}).AllPages(ctx)
+			if err != nil {
+				return 0, err
+			}
+			list, err := secgroups.ExtractGroups(pages)
+			return len(list), err
+		}},
+		{"security group rules", &out.SecurityGroupRule.InUse, func() (int, error) {
+			pages, err := secrules.List(c.NetworkV2, secrules.ListOpts{ProjectID: projectID}).AllPages(ctx)
+			if err != nil {
+				return 0, err
+			}
+			list, err := secrules.ExtractRules(pages)
+			return len(list), err
+		}},
+	}
+	for _, cnt := range counts {
+		n, err := cnt.count()
+		if err != nil {
+			return nil, fmt.Errorf("count neutron %s: %w", cnt.name, err)
+		}
+		*cnt.into = n
+	}
+	return out, nil
+}
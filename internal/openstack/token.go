@@ -0,0 +1,179 @@
+package openstack
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gophercloud/gophercloud/v2"
+)
+
+// ErrInvalidToken is returned when Keystone does not recognize a token
+// (expired, revoked or malformed).
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// TokenInfo is the identity carried by a validated Keystone token.
+type TokenInfo struct {
+	UserID      string    `json:"user_id"`
+	UserName    string    `json:"user_name"`
+	ProjectID   string    `json:"project_id,omitempty"`
+	ProjectName string    `json:"project_name,omitempty"`
+	DomainID    string    `json:"domain_id,omitempty"` // 도메인 스코프 토큰
+	DomainName  string    `json:"domain_name,omitempty"`
+	Roles       []string  `json:"roles"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// HasRole reports whether the token carries the named Keystone role.
+func (t *TokenInfo) HasRole(name string) bool {
+	for _, r := range t.Roles {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+type cachedToken struct {
+	info     *TokenInfo
+	cachedAt time.Time
+}
+
+// TokenValidator validates caller tokens against Keystone and caches the
+// result for a short TTL so that every API call does not hit Keystone.
+// 캐시 키는 토큰 원문이 아니라 SHA-256 해시를 쓴다.
+type TokenValidator struct {
+	clients *Clients
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu    sync.Mutex
+	cache map[[32]byte]cachedToken
+}
+
+// maxCachedTokens bounds the cache; expired entries are swept when it fills.
+const maxCachedTokens = 10000
+
+func NewTokenValidator(clients *Clients, ttl time.Duration) *TokenValidator {
+	return &TokenValidator{
+		clients: clients,
+		ttl:     ttl,
+		now:     time.Now,
+		cache:   map[[32]byte]cachedToken{},
+	}
+}
+
+// Validate returns the identity behind token, using the cache when fresh.
+func (v *TokenValidator) Validate(ctx context.Context, token string) (*TokenInfo, error) {
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+	key := sha256.Sum256([]byte(token))
+	now := v.now()
+
+	v.mu.Lock()
+	if c, ok := v.cache[key]; ok {
+		if now.Sub(c.cachedAt) < v.ttl && now.Before(c.info.ExpiresAt) {
+			v.mu.Unlock()
+			return c.info, nil
+		}
+		delete(v.cache, key)
+	}
+	v.mu.Unlock()
+
+	info, err := v.introspect(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.ttl > 0 {
+		v.mu.Lock()
+		if len(v.cache) >= maxCachedTokens {
+			v.sweepLocked(now)
+		}
+		v.cache[key] = cachedToken{info: info, cachedAt: now}
+		v.mu.Unlock()
+	}
+	return info, nil
+}
+
+// Forget drops a token from the cache (e.g. after logout).
+func (v *TokenValidator) Forget(token string) {
+	key := sha256.Sum256([]byte(token))
+	v.mu.Lock()
+	delete(v.cache, key)
+	v.mu.Unlock()
+}
+
+func (v *TokenValidator) sweepLocked(now time.Time) {
+	for k, c := range v.cache {
+		if now.Sub(c.cachedAt) >= v.ttl || !now.Before(c.info.ExpiresAt) {
+			delete(v.cache, k)
+		}
+	}
+	// 그래도 가득 차 있으면 전부 비운다 (다음 요청에서 다시 채워짐)
+	if len(v.cache) >= maxCachedTokens {
+		v.cache = map[[32]byte]cachedToken{}
+	}
+}
+
+func (v *TokenValidator) introspect(ctx context.Context, token string) (*TokenInfo, error) {
+	var body struct {
+		Token struct {
+			ExpiresAt time.Time `json:"expires_at"`
+			User      struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"user"`
+			Project *struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"project"`
+			Domain *struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"domain"`
+			Roles []struct {
+				Name string `json:"name"`
+			} `json:"roles"`
+		} `json:"token"`
+	}
+
+	ident := v.clients.Identity
+	_, err := ident.Get(ctx, ident.ServiceURL("auth", "tokens")+"?nocatalog", &body, &gophercloud.RequestOpts{
+		MoreHeaders: map[string]string{"X-Subject-Token": token},
+		OkCodes:     []int{http.StatusOK},
+	})
+	if err != nil {
+		if gophercloud.ResponseCodeIs(err, http.StatusNotFound) || gophercloud.ResponseCodeIs(err, http.StatusUnauthorized) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("keystone token validation: %w", err)
+	}
+
+	t := body.Token
+	info := &TokenInfo{
+		UserID:    t.User.ID,
+		UserName:  t.User.Name,
+		ExpiresAt: t.ExpiresAt,
+	}
+	if t.Project != nil {
+		info.ProjectID = t.Project.ID
+		info.ProjectName = t.Project.Name
+	}
+	if t.Domain != nil {
+		info.DomainID = t.Domain.ID
+		info.DomainName = t.Domain.Name
+	}
+	for _, r := range t.Roles {
+		info.Roles = append(info.Roles, r.Name)
+	}
+	if info.UserID == "" || info.ExpiresAt.IsZero() {
+		return nil, ErrInvalidToken
+	}
+	return info, nil
+}
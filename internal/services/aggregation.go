@@ -0,0 +1,154 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/models"
+)
+
+// QuotaAggregator combines course profiles on top of a baseline. 반환값은
+// 과목별 실제 기여분이며 effective = baseline + Σ(기여분) 이 항상 성립한다.
+type QuotaAggregator interface {
+	Name() models.AggregationPolicyName
+	Contributions(baseline models.QuotaProfile, courses []models.QuotaProfile) []models.QuotaProfile
+}
+
+// CourseContribution is what one active course added to the effective quota.
+type CourseContribution struct {
+	CourseID       string              `json:"course_id"`
+	Profile        string              `json:"profile"`
+	ProfileVersion int                 `json:"profile_version"`
+	Quota          models.QuotaProfile `json:"quota"`                 // 프로파일 원래 값
+	Contribution   models.QuotaProfile `json:"contribution"`          // 정책 적용 후 실제 반영된 값
+	GraceUntil     *time.Time          `json:"grace_until,omitempty"` // 종료된 과목이면 유예 기간 끝
+}
+
+// sumAggregator: 모든 과목 프로파일을 그대로 더한다 (기존 동작)
+type sumAggregator struct{}
+
+func (sumAggregator) Name() models.AggregationPolicyName { return models.AggregationSum }
+
+func (sumAggregator) Contributions(_ models.QuotaProfile, courses []models.QuotaProfile) []models.QuotaProfile {
+	return append([]models.QuotaProfile(nil), courses...)
+}
+
+// maxAggregator: 항목별로 가장 큰 과목 하나만 반영한다 (동률이면 먼저 수강한 과목)
+type maxAggregator struct{}
+
+func (maxAggregator) Name() models.AggregationPolicyName { return models.AggregationMax }
+
+func (maxAggregator) Contributions(_ models.QuotaProfile, courses []models.QuotaProfile) []models.QuotaProfile {
+	out := make([]models.QuotaProfile, len(courses))
+	for _, key := range quotaKeys(courses...) {
+		best := -1
+		for i := range courses {
+			if best < 0 || quotaValue(courses[i], key) > quotaValue(courses[best], key) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			setQuotaValue(&out[best], key, quotaValue(courses[best], key))
+		}
+	}
+	return out
+}
+
+// capAggregator: 합산 후 cap 을 넘는 만큼 마지막 과목부터 기여분을 줄인다.
+// cap 이 0 인 항목은 제한하지 않으며, baseline 자체는 줄이지 않는다.
+type capAggregator struct {
+	cap models.QuotaProfile
+}
+
+func (capAggregator) Name() models.AggregationPolicyName { return models.AggregationSumWithCap }
+
+func (a capAggregator) Contributions(baseline models.QuotaProfile, courses []models.QuotaProfile) []models.QuotaProfile {
+	out := append([]models.QuotaProfile(nil), courses...)
+	for _, key := range quotaKeys(a.cap) {
+		limit := quotaValue(a.cap, key)
+		if limit == 0 {
+			continue
+		}
+		total := quotaValue(baseline, key)
+		for i := range out {
+			total += quotaValue(out[i], key)
+		}
+		excess := total - limit
+		for i := len(out) - 1; i >= 0 && excess > 0; i-- {
+			v := quotaValue(out[i], key)
+			cut := min(v, excess)
+			setQuotaValue(&out[i], key, v-cut)
+			excess -= cut
+		}
+	}
+	return out
+}
+
+// weightedAggregator: 항목별로 큰 과목부터 weights[0], weights[1], … 를 곱해
+// 올림한다. 과목 수가 weights 보다 많으면 마지막 가중치를 반복한다.
+type weightedAggregator struct {
+	weights []float64
+}
+
+func (weightedAggregator) Name() models.AggregationPolicyName { return models.AggregationWeighted }
+
+func (a weightedAggregator) Contributions(_ models.QuotaProfile, courses []models.QuotaProfile) []models.QuotaProfile {
+	out := make([]models.QuotaProfile, len(courses))
+	order := make([]int, len(courses))
+	for _, key := range quotaKeys(courses...) {
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(x, y int) bool {
+			return quotaValue(courses[order[x]], key) > quotaValue(courses[order[y]], key)
+		})
+		for rank, i := range order {
+			w := a.weights[min(rank, len(a.weights)-1)]
+			setQuotaValue(&out[i], key, int(math.Ceil(float64(quotaValue(courses[i], key))*w)))
+		}
+	}
+	return out
+}
+
+// ResolveAggregation returns the aggregator for a student's department:
+// 학과 규칙 > 전역 규칙 > sum.
+func ResolveAggregation(db database.Store, department string) (QuotaAggregator, models.AggregationSource, error) {
+	rules, err := db.MatchAggregationRules(department)
+	if err != nil {
+		return nil, models.AggregationSource{}, fmt.Errorf("failed to match aggregation rules: %w", err)
+	}
+
+	source := models.AggregationSource{Scope: models.AggregationScopeDefault, AggregationPolicy: models.DefaultAggregationPolicy}
+	for _, rule := range rules {
+		if rule.Scope == models.AggregationScopeDepartment || source.Scope == models.AggregationScopeDefault {
+			source = models.AggregationSource{Scope: rule.Scope, Value: rule.Value, AggregationPolicy: rule.AggregationPolicy}
+		}
+	}
+
+	aggregator, err := newAggregator(db, source.AggregationPolicy)
+	return aggregator, source, err
+}
+
+func newAggregator(db database.ProfileStore, policy models.AggregationPolicy) (QuotaAggregator, error) {
+	switch policy.Policy {
+	case models.AggregationSum:
+		return sumAggregator{}, nil
+	case models.AggregationMax:
+		return maxAggregator{}, nil
+	case models.AggregationSumWithCap:
+		capProfile, err := db.GetProfile(policy.CapProfile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve cap profile %q: %w", policy.CapProfile, err)
+		}
+		return capAggregator{cap: capProfile.Limits}, nil
+	case models.AggregationWeighted:
+		if len(policy.Weights) == 0 {
+			return nil, fmt.Errorf("weighted aggregation policy has no weights")
+		}
+		return weightedAggregator{weights: policy.Weights}, nil
+	}
+	return nil, fmt.Errorf("unknown aggregation policy %q", policy.Policy)
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"fmt"
+
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/models"
+)
+
+// ResolveBaseline returns the baseline profile for a student: the most
+// specific matching rule (학생 > cohort > 학과) wins, and without any rule
+// the catalog's models.BaselineProfileName is used.
+func ResolveBaseline(db database.Store, student *models.Student) (*models.Profile, models.BaselineSource, error) {
+	rules, err := db.MatchBaselineRules(student)
+	if err != nil {
+		return nil, models.BaselineSource{}, fmt.Errorf("failed to match baseline rules: %w", err)
+	}
+
+	source := models.BaselineSource{Scope: models.BaselineScopeDefault, Profile: models.BaselineProfileName}
+	for _, rule := range rules {
+		if rule.Scope.Specificity() > source.Scope.Specificity() {
+			source = models.BaselineSource{Scope: rule.Scope, Value: rule.Value, Profile: rule.ProfileName}
+		}
+	}
+
+	profile, err := db.GetProfile(source.Profile)
+	if err != nil {
+		return nil, source, fmt.Errorf("failed to resolve baseline profile %q: %w", source.Profile, err)
+	}
+	source.ProfileVersion = profile.Version
+	return profile, source, nil
+}
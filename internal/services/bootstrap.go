@@ -0,0 +1,320 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gophercloud/gophercloud/v2"
+
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/models"
+	"example.com/quotaapi/internal/openstack"
+)
+
+const (
+	bootstrapMaxAttempts = 5
+	bootstrapBaseBackoff = 2 * time.Second
+)
+
+// ErrBootstrapInProgress is returned when a bootstrap for the same student
+// is already running in this process.
+var ErrBootstrapInProgress = errors.New("bootstrap already in progress for this student")
+
+// bootstrapInFlight guards against two goroutines (요청 핸들러와 시작 시
+// 재개 작업 등) advancing the same student's saga concurrently.
+var bootstrapInFlight sync.Map
+
+// incompleteBootstrapStates are resumed at startup.
+var incompleteBootstrapStates = []models.BootstrapState{
+	models.BootstrapPending,
+	models.BootstrapProjectCreated,
+	models.BootstrapUserCreated,
+	models.BootstrapRoleAssigned,
+	models.BootstrapQuotaApplied,
+}
+
+// BootstrapService drives the per-student Keystone bootstrap saga
+// (pending → project_created → user_created → role_assigned →
+// quota_applied → ready). 각 단계가 끝날 때마다 상태를 DB 에 기록하므로
+// 프로세스가 죽어도 마지막으로 완료된 단계 다음부터 이어서 진행한다.
+// 일시적 오류는 지수 백오프로 재시도하고, 영구 실패 시 이미 만든 Keystone
+// 자원을 삭제(보상)한 뒤 failed 로 남긴다.
+type BootstrapService struct {
+	db         database.Store
+	projectMgr *openstack.ProjectManager
+	reconciler *QuotaReconciliationService
+	backoff    time.Duration
+}
+
+// NewBootstrapService creates a new bootstrap service. reconciler 는 쿼타
+// 단계에서 쓰는 공용 리콘실 서비스다 (RECONCILE_* 설정 공유).
+func NewBootstrapService(db database.Store, projectMgr *openstack.ProjectManager, reconciler *QuotaReconciliationService) *BootstrapService {
+	return &BootstrapService{
+		db:         db,
+		projectMgr: projectMgr,
+		reconciler: reconciler,
+		backoff:    bootstrapBaseBackoff,
+	}
+}
+
+// Run advances the student's saga to ready (or failed). A failed student
+// is retried from scratch, reusing any Keystone IDs still recorded.
+func (s *BootstrapService) Run(ctx context.Context, studentID string) (*models.Student, error) {
+	if _, busy := bootstrapInFlight.LoadOrStore(studentID, struct{}{}); busy {
+		return nil, ErrBootstrapInProgress
+	}
+	defer bootstrapInFlight.Delete(studentID)
+
+	student, err := s.db.GetStudent(studentID)
+	if err != nil {
+		return nil, fmt.Errorf("student not found: %s", studentID)
+	}
+	switch student.BootstrapState {
+	case models.BootstrapReady:
+		return student, nil
+	case models.BootstrapFailed, "":
+		// 새 saga: 이전 시도의 표식이 붙은 객체는 보상으로 지워졌거나 ID 가 남아 있다
+		student.BootstrapAttempts, student.BootstrapSagaID = 0, ""
+		if err := s.transition(student, models.BootstrapPending, "", map[string]interface{}{
+			"bootstrap_saga_id": "",
+		}); err != nil {
+			return student, err
+		}
+	}
+
+	for {
+		err := s.advance(ctx, student)
+		if err == nil {
+			log.Printf("bootstrap: student %s is ready (project %s %s, user %s %s)", studentID,
+				student.KeystoneProjectID, adoptedOrCreated(student.ProjectAdopted),
+				student.KeystoneUserID, adoptedOrCreated(student.UserAdopted))
+			return student, nil
+		}
+		if ctx.Err() != nil {
+			// 종료/타임아웃: 보상하지 않고 현재 단계에서 멈춘다 (다음 시작 시 재개)
+			return student, fmt.Errorf("bootstrap interrupted at %s: %w", student.BootstrapState, err)
+		}
+
+		student.BootstrapAttempts++
+		if isPermanentOpenStackError(err) || student.BootstrapAttempts >= bootstrapMaxAttempts {
+			log.Printf("bootstrap: student %s failed permanently at %s: %v", studentID, student.BootstrapState, err)
+			return student, s.compensate(ctx, student, err)
+		}
+
+		if perr := s.transition(student, student.BootstrapState, err.Error(), nil); perr != nil {
+			log.Printf("bootstrap: failed to record attempt for student %s: %v", studentID, perr)
+		}
+		wait := s.backoff << (student.BootstrapAttempts - 1)
+		log.Printf("bootstrap: student %s attempt %d failed at %s, retrying in %s: %v",
+			studentID, student.BootstrapAttempts, student.BootstrapState, wait, err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return student, fmt.Errorf("bootstrap interrupted at %s: %w", student.BootstrapState, ctx.Err())
+		}
+	}
+}
+
+// ResumeIncomplete continues every saga left in a non-terminal state, e.g.
+// after a restart. bootstrapInFlight 는 프로세스 안에서만 유효하므로
+// 리더 레플리카에서만 실행한다 (LeaderElector.Go).
+func (s *BootstrapService) ResumeIncomplete(ctx context.Context) {
+	students, err := s.db.ListStudentsByBootstrapState(incompleteBootstrapStates...)
+	if err != nil {
+		log.Printf("bootstrap: failed to list incomplete students: %v", err)
+		return
+	}
+	if len(students) > 0 {
+		log.Printf("bootstrap: resuming %d incomplete student bootstrap(s)", len(students))
+	}
+	for _, st := range students {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := s.Run(ctx, st.StudentID); err != nil && !errors.Is(err, ErrBootstrapInProgress) {
+			log.Printf("bootstrap: resume of student %s ended with error: %v", st.StudentID, err)
+		}
+	}
+}
+
+// advance executes the remaining steps, persisting after each one.
+// 생성 단계는 이미 기록된 ID 가 있으면 건너뛰므로 재실행해도 중복 생성되지 않는다.
+func (s *BootstrapService) advance(ctx context.Context, st *models.Student) error {
+	for !st.BootstrapState.Terminal() {
+		var next models.BootstrapState
+		updates := map[string]interface{}{}
+
+		switch st.BootstrapState {
+		case models.BootstrapPending:
+			if st.KeystoneProjectID == "" {
+				if err := s.recordIntent(st); err != nil {
+					return err
+				}
+				project, adopted, err := s.projectMgr.EnsureStudentProject(ctx, st)
+				if err != nil {
+					return err
+				}
+				st.KeystoneProjectID, st.ProjectAdopted = project.ID, adopted
+			}
+			next = models.BootstrapProjectCreated
+			updates["keystone_project_id"] = st.KeystoneProjectID
+			updates["keystone_project_adopted"] = st.ProjectAdopted
+
+		case models.BootstrapProjectCreated:
+			if st.KeystoneUserID == "" {
+				if err := s.recordIntent(st); err != nil {
+					return err
+				}
+				user, adopted, err := s.projectMgr.EnsureStudentUser(ctx, st)
+				if err != nil {
+					return err
+				}
+				st.KeystoneUserID, st.UserAdopted = user.ID, adopted
+			}
+			next = models.BootstrapUserCreated
+			updates["keystone_user_id"] = st.KeystoneUserID
+			updates["keystone_user_adopted"] = st.UserAdopted
+
+		case models.BootstrapUserCreated:
+			if err := s.projectMgr.AssignMemberRole(ctx, st.KeystoneUserID, st.KeystoneProjectID); err != nil {
+				return err
+			}
+			next = models.BootstrapRoleAssigned
+
+		case models.BootstrapRoleAssigned:
+			// 기본 쿼타만이 아니라 수강 과목과 합산 정책까지 반영한 쿼타를 사용량에 맞춰
+			// 적용하고 quota_state 를 기록한다. 이미 과목 쿼타와 사용량이 있는 채택
+			// 프로젝트도 baseline 으로 줄이거나 사용량 때문에 실패하지 않는다.
+			if s.reconciler == nil {
+				return errors.New("quota reconciler is not configured")
+			}
+			summary := s.reconciler.reconcileStudentQuota(ctx, st, ReconcileOptions{})
+			if summary.Status == "failed" || summary.Status == "pending" {
+				return fmt.Errorf("failed to apply quota: %s", summary.ErrorMessage)
+			}
+			next = models.BootstrapQuotaApplied
+
+		case models.BootstrapQuotaApplied:
+			next = models.BootstrapReady
+
+		default:
+			return fmt.Errorf("unknown bootstrap state %q", st.BootstrapState)
+		}
+
+		if err := s.transition(st, next, "", updates); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordIntent persists the saga ID before a Keystone create. 생성 후 ID 를
+// 기록하기 전에 죽어도 재개 시 같은 표식이 붙은 객체는 채택이 아닌 생성으로
+// 판단되어(EnsureStudentProject/EnsureStudentUser) 보상 단계에서 삭제된다.
+func (s *BootstrapService) recordIntent(st *models.Student) error {
+	if st.BootstrapSagaID != "" {
+		return nil
+	}
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("generate bootstrap saga id: %w", err)
+	}
+	sagaID := hex.EncodeToString(b)
+	if err := s.db.UpdateStudent(st.StudentID, map[string]interface{}{"bootstrap_saga_id": sagaID}); err != nil {
+		return fmt.Errorf("failed to persist bootstrap intent: %w", err)
+	}
+	st.BootstrapSagaID = sagaID
+	return nil
+}
+
+// compensate deletes the Keystone objects created so far and marks the
+// saga failed. 채택한 기존 자원은 지우지 않고 연결만 끊으며, 삭제에 실패한
+// ID 는 남겨 두어 재시도 시 재사용/정리할 수 있게 한다.
+func (s *BootstrapService) compensate(ctx context.Context, st *models.Student, cause error) error {
+	cctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+	defer cancel()
+
+	var errs []error
+	if st.KeystoneUserID != "" {
+		if st.UserAdopted {
+			st.KeystoneUserID, st.UserAdopted = "", false
+		} else if err := s.projectMgr.DeleteUser(cctx, st.KeystoneUserID); err != nil {
+			errs = append(errs, err)
+		} else {
+			st.KeystoneUserID = ""
+		}
+	}
+	if st.KeystoneProjectID != "" {
+		if st.ProjectAdopted {
+			st.KeystoneProjectID, st.ProjectAdopted = "", false
+		} else if err := s.projectMgr.DeleteProject(cctx, st.KeystoneProjectID); err != nil {
+			errs = append(errs, err)
+		} else {
+			st.KeystoneProjectID = ""
+		}
+	}
+
+	msg := cause.Error()
+	if len(errs) > 0 {
+		msg += "; compensation failed: " + errors.Join(errs...).Error()
+	}
+	if err := s.transition(st, models.BootstrapFailed, msg, map[string]interface{}{
+		"keystone_project_id":      st.KeystoneProjectID,
+		"keystone_user_id":         st.KeystoneUserID,
+		"keystone_project_adopted": st.ProjectAdopted,
+		"keystone_user_adopted":    st.UserAdopted,
+	}); err != nil {
+		return fmt.Errorf("%s (and failed to record failure: %v)", msg, err)
+	}
+	return errors.New(msg)
+}
+
+// transition persists the new state together with any extra column updates.
+func (s *BootstrapService) transition(st *models.Student, state models.BootstrapState, errMsg string, updates map[string]interface{}) error {
+	now := time.Now().UTC()
+	if updates == nil {
+		updates = map[string]interface{}{}
+	}
+	updates["bootstrap_state"] = string(state)
+	updates["bootstrap_error"] = errMsg
+	updates["bootstrap_attempts"] = st.BootstrapAttempts
+	updates["bootstrap_updated_at"] = now
+
+	if err := s.db.UpdateStudent(st.StudentID, updates); err != nil {
+		return fmt.Errorf("failed to persist bootstrap state %s: %w", state, err)
+	}
+	st.BootstrapState = state
+	st.BootstrapError = errMsg
+	st.BootstrapUpdatedAt = &now
+	return nil
+}
+
+func adoptedOrCreated(adopted bool) string {
+	if adopted {
+		return "adopted"
+	}
+	return "created"
+}
+
+// isPermanentOpenStackError reports client errors that retrying cannot fix.
+func isPermanentOpenStackError(err error) bool {
+	if errors.Is(err, openstack.ErrNotOwned) {
+		return true
+	}
+	for _, code := range []int{
+		http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden,
+		http.StatusNotFound, http.StatusConflict,
+	} {
+		if gophercloud.ResponseCodeIs(err, code) {
+			return true
+		}
+	}
+	return false
+}
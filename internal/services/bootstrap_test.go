@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/models"
+	"example.com/quotaapi/internal/openstack"
+	"example.com/quotaapi/internal/openstack/openstacktest"
+)
+
+// TestBootstrapCompensatesObjectCreatedBeforeCrash covers a crash between the
+// Keystone create and saving its ID: 재개 시 이번 saga 표식이 붙은 프로젝트는
+// 채택이 아닌 생성으로 보고, 이후 영구 실패하면 보상 단계에서 지워야 한다.
+func TestBootstrapCompensatesObjectCreatedBeforeCrash(t *testing.T) {
+	tests := []struct {
+		name        string
+		sagaID      string // 프로젝트에 붙은 saga 표식 (빈 값이면 이전부터 있던 프로젝트)
+		wantDeleted bool
+	}{
+		{name: "created by this saga", sagaID: "0123456789abcdef", wantDeleted: true},
+		{name: "pre-existing project is adopted", wantDeleted: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := openstacktest.NewServer()
+			defer fake.Close()
+			osc, err := openstack.NewServiceClients(fake.Config())
+			if err != nil {
+				t.Fatal(err)
+			}
+			pm := openstack.NewProjectManager(osc)
+			db := database.NewMemoryStore()
+			ctx := context.Background()
+
+			student := &models.Student{StudentID: "20240001", Name: "Kim", Department: "cs", BootstrapSagaID: tt.sagaID}
+			if err := db.CreateStudent(student); err != nil {
+				t.Fatal(err)
+			}
+			// 생성 직후(ID 기록 전) 죽은 상태: 의도만 기록되어 있고 프로젝트 ID 는 없다
+			project, err := pm.CreateProject(ctx, student)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := db.UpdateStudent(student.StudentID, map[string]interface{}{"bootstrap_saga_id": "0123456789abcdef"}); err != nil {
+				t.Fatal(err)
+			}
+
+			// 사용자 생성이 영구 실패하면 보상이 실행된다
+			fake.Inject(openstacktest.Fault{Method: http.MethodPost, Path: "/identity/v3/users", Status: http.StatusForbidden})
+			svc := NewBootstrapService(db, pm, NewQuotaReconciliationService(db, pm))
+			if _, err := svc.Run(ctx, student.StudentID); err == nil {
+				t.Fatal("Run succeeded, want permanent failure")
+			}
+
+			got, err := db.GetStudent(student.StudentID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.BootstrapState != models.BootstrapFailed {
+				t.Errorf("state = %s, want failed", got.BootstrapState)
+			}
+			exists := false
+			for _, p := range fake.Projects() {
+				exists = exists || p.ID == project.ID
+			}
+			if exists == tt.wantDeleted {
+				t.Errorf("project still exists = %t, want deleted = %t", exists, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+// TestBootstrapAppliesFullQuota re-bootstraps an adopted project that already
+// carries course quota and usage above the baseline: 쿼타 단계는 baseline 이 아니라
+// 수강 과목까지 반영한 쿼타를 적용해야 한다 (baseline 8 코어 < 사용량 20 이면 Nova 400).
+func TestBootstrapAppliesFullQuota(t *testing.T) {
+	fake := openstacktest.NewServer()
+	defer fake.Close()
+	osc, err := openstack.NewServiceClients(fake.Config())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pm := openstack.NewProjectManager(osc)
+	db := database.NewMemoryStore()
+
+	now := time.Now()
+	student := &models.Student{StudentID: "20240002", Name: "Lee", Department: "cs"}
+	mustCreate(t, db.CreateStudent(student))
+	mustCreate(t, db.CreateCourse(&models.Course{CourseID: "CS101", Title: "OS", Department: "cs", Semester: "2024-1",
+		StartAt: now.Add(-time.Hour), EndAt: now.Add(time.Hour), ProfileName: "lab"}))
+	mustCreate(t, db.EnrollStudent(&models.Enrollment{StudentID: student.StudentID, CourseID: "CS101", Status: "active",
+		StartAt: now.Add(-time.Hour), EndAt: now.Add(time.Hour)}))
+
+	pid := fake.AddProject(openstack.StudentProjectName(student.StudentID), "", openstack.ManagedByTag, "student="+student.StudentID)
+	fake.SetLimit(openstacktest.Compute, pid, "cores", 24)
+	fake.SetUsage(openstacktest.Compute, pid, "cores", 20)
+
+	got, err := NewBootstrapService(db, pm, NewQuotaReconciliationService(db, pm)).Run(context.Background(), student.StudentID)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got.BootstrapState != models.BootstrapReady || !got.ProjectAdopted {
+		t.Errorf("state = %s, adopted = %t; want ready, adopted", got.BootstrapState, got.ProjectAdopted)
+	}
+	want := models.BuiltinProfiles["basic"].Cores + models.BuiltinProfiles["lab"].Cores
+	if cores := fake.Quota(openstacktest.Compute, pid)["cores"].Limit; cores != want {
+		t.Errorf("cores = %d, want baseline + course = %d", cores, want)
+	}
+	if stored, _ := db.GetStudent(student.StudentID); stored.QuotaState != models.QuotaStateInSync {
+		t.Errorf("quota_state = %q, want %q", stored.QuotaState, models.QuotaStateInSync)
+	}
+}
+
+func mustCreate(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
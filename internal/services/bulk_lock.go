@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// bulkLeaseName is the lease held while a persisted bulk or drift run is in
+// progress. 리더가 아닌 레플리카도 수동 리콘실 요청을 받으므로 프로세스 안의
+// 뮤텍스만으로는 레플리카 간 실행이 겹치는 것을 막을 수 없다.
+const bulkLeaseName = "bulk_reconciliation"
+
+// errBulkLeaseLost cancels a run whose bulk lease could not be kept.
+var errBulkLeaseLost = errors.New("bulk reconciliation lease lost")
+
+// processStarted tells this process apart from an earlier one that used the
+// same LEADER_ID.
+var processStarted = time.Now()
+
+// bulkHolder names this process on the bulk lease and on the runs it
+// starts. 같은 LEADER_ID 로 재시작한 프로세스가 죽은 프로세스의 lease 와 실행을
+// 자기 것으로 보지 않도록 프로세스 시작 시각을 붙인다.
+func bulkHolder(replicaID string) string {
+	return fmt.Sprintf("%s@%d", replicaID, processStarted.UnixNano())
+}
+
+// bulkRunning serializes bulk runs across every QuotaReconciliationService
+// in this process, before the bulk lease is asked for.
+var bulkRunning sync.Mutex
+
+// lockBulk takes bulkRunning and the bulk lease, or returns
+// ErrReconciliationRunning when another run holds either. 반환된 ctx 는
+// lease 를 갱신하지 못하면 취소되며, unlock 은 갱신을 멈추고 lease 를 놓는다.
+func (s *QuotaReconciliationService) lockBulk(ctx context.Context) (context.Context, func(), error) {
+	if !bulkRunning.TryLock() {
+		return nil, nil, ErrReconciliationRunning
+	}
+	lease, err := s.db.AcquireLeaderLease(bulkLeaseName, s.holder, s.lease.LeaseTTL)
+	if err != nil {
+		bulkRunning.Unlock()
+		return nil, nil, err
+	}
+	if lease == nil || lease.Holder != s.holder {
+		bulkRunning.Unlock()
+		return nil, nil, ErrReconciliationRunning
+	}
+	// lease 를 잡았으니 running 으로 남은 다른 실행은 모두 죽은 프로세스의 것이다
+	s.FailOrphanedRuns()
+
+	runCtx, cancel := context.WithCancelCause(ctx)
+	renewing := make(chan struct{})
+	go func() {
+		defer close(renewing)
+		s.renewBulkLease(runCtx, cancel)
+	}()
+	return runCtx, func() {
+		cancel(nil)
+		<-renewing
+		if err := s.db.ReleaseLeaderLease(bulkLeaseName, s.holder); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		bulkRunning.Unlock()
+	}, nil
+}
+
+// renewBulkLease renews the bulk lease until ctx is done and cancels the run
+// when the lease is taken over or cannot be renewed before it would expire
+// (리더 선출과 같이 갱신 한 번만큼 여유를 둔다).
+func (s *QuotaReconciliationService) renewBulkLease(ctx context.Context, cancel context.CancelCauseFunc) {
+	renewed := time.Now()
+	ticker := time.NewTicker(s.lease.RenewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		lease, err := s.db.AcquireLeaderLease(bulkLeaseName, s.holder, s.lease.LeaseTTL)
+		switch {
+		case err != nil:
+			log.Printf("Warning: failed to renew bulk reconciliation lease: %v", err)
+			if time.Since(renewed) >= s.lease.LeaseTTL-s.lease.RenewInterval {
+				cancel(errBulkLeaseLost)
+				return
+			}
+		case lease == nil || lease.Holder != s.holder:
+			log.Printf("Warning: bulk reconciliation lease taken over by %v, stopping", lease)
+			cancel(errBulkLeaseLost)
+			return
+		default:
+			renewed = time.Now()
+		}
+	}
+}
+
+// FailOrphanedRuns marks running runs whose process no longer holds the bulk
+// lease as failed, leaving runs in progress on other replicas alone.
+func (s *QuotaReconciliationService) FailOrphanedRuns() {
+	n, err := s.db.FailOrphanedReconciliationRuns(bulkLeaseName, "interrupted: the server running it stopped")
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	} else if n > 0 {
+		log.Printf("Marked %d interrupted reconciliation runs as failed", n)
+	}
+}
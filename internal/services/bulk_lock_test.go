@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/quotaapi/internal/config"
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/models"
+)
+
+// TestBulkLeaseAcrossReplicas checks that a bulk run on one replica refuses
+// to start while another replica holds the bulk lease.
+func TestBulkLeaseAcrossReplicas(t *testing.T) {
+	db := database.NewMemoryStore()
+	lease := config.LeaderConfig{ID: "replica-a", LeaseTTL: time.Minute, RenewInterval: 10 * time.Second}
+	a := NewQuotaReconciliationService(db, nil).WithLease(lease)
+	ctx := context.Background()
+
+	if _, err := db.AcquireLeaderLease(bulkLeaseName, "replica-b", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.RunBulkReconciliation(ctx, "manual", ReconcileOptions{}); !errors.Is(err, ErrReconciliationRunning) {
+		t.Fatalf("bulk run while another replica holds the lease: err = %v", err)
+	}
+	if _, err := a.ReconcileDrift(ctx); !errors.Is(err, ErrReconciliationRunning) {
+		t.Fatalf("drift run while another replica holds the lease: err = %v", err)
+	}
+	// dry run 은 아무것도 바꾸지 않으므로 lease 없이 실행된다
+	if _, err := a.RunBulkReconciliation(ctx, "manual", ReconcileOptions{DryRun: true}); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+
+	if err := db.ReleaseLeaderLease(bulkLeaseName, "replica-b"); err != nil {
+		t.Fatal(err)
+	}
+	result, err := a.RunBulkReconciliation(ctx, "manual", ReconcileOptions{})
+	if err != nil {
+		t.Fatalf("bulk run after release: %v", err)
+	}
+	if result.RunID == 0 {
+		t.Fatal("run not recorded")
+	}
+	if held, _ := db.GetLeaderLease(bulkLeaseName); held != nil {
+		t.Fatalf("lease not released after the run: %+v", held)
+	}
+}
+
+// TestBulkLeaseLostCancelsRun checks that losing the bulk lease cancels the
+// run's context.
+func TestBulkLeaseLostCancelsRun(t *testing.T) {
+	db := database.NewMemoryStore()
+	lease := config.LeaderConfig{ID: "replica-a", LeaseTTL: 40 * time.Millisecond, RenewInterval: 10 * time.Millisecond}
+	a := NewQuotaReconciliationService(db, nil).WithLease(lease)
+
+	ctx, unlock, err := a.lockBulk(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	// 다른 레플리카가 lease 를 가져간 상황
+	if err := db.ReleaseLeaderLease(bulkLeaseName, a.holder); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.AcquireLeaderLease(bulkLeaseName, "replica-b", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("run not cancelled after losing the lease")
+	}
+	if cause := context.Cause(ctx); !errors.Is(cause, errBulkLeaseLost) {
+		t.Fatalf("cause = %v, want %v", cause, errBulkLeaseLost)
+	}
+}
+
+// TestFailOrphanedRuns checks that only runs whose process lost the bulk
+// lease are failed, not a run in progress on another replica.
+func TestFailOrphanedRuns(t *testing.T) {
+	db := database.NewMemoryStore()
+	a := NewQuotaReconciliationService(db, nil).WithLease(config.LeaderConfig{ID: "replica-a"})
+	b := NewQuotaReconciliationService(db, nil).WithLease(config.LeaderConfig{ID: "replica-b"})
+
+	if _, err := db.AcquireLeaderLease(bulkLeaseName, b.holder, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	live := &models.ReconciliationRun{Trigger: "manual", Status: models.ReconciliationRunning, Holder: b.holder, StartedAt: time.Now()}
+	dead := &models.ReconciliationRun{Trigger: "scheduled", Status: models.ReconciliationRunning, Holder: "replica-c@1", StartedAt: time.Now()}
+	for _, run := range []*models.ReconciliationRun{live, dead} {
+		if err := db.CreateReconciliationRun(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 리더가 된 레플리카 a 의 스케줄러가 정리해도 b 의 실행은 남는다
+	a.FailOrphanedRuns()
+	if got, _ := db.GetReconciliationRun(live.ID); got.Status != models.ReconciliationRunning {
+		t.Fatalf("live run on another replica = %s, want running", got.Status)
+	}
+	if got, _ := db.GetReconciliationRun(dead.ID); got.Status != models.ReconciliationFailed {
+		t.Fatalf("orphaned run = %s, want failed", got.Status)
+	}
+	if _, err := a.RunBulkReconciliation(context.Background(), "manual", ReconcileOptions{}); !errors.Is(err, ErrReconciliationRunning) {
+		t.Fatalf("bulk run while b holds the lease: err = %v", err)
+	}
+
+	// b 가 죽어 lease 가 끝나면 그 실행도 중단된 것으로 본다
+	if err := db.ReleaseLeaderLease(bulkLeaseName, b.holder); err != nil {
+		t.Fatal(err)
+	}
+	result, err := a.RunBulkReconciliation(context.Background(), "manual", ReconcileOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := db.GetReconciliationRun(live.ID); got.Status != models.ReconciliationFailed {
+		t.Fatalf("run of the dead replica = %s, want failed", got.Status)
+	}
+	if got, _ := db.GetReconciliationRun(result.RunID); got.Holder != a.holder || got.Status != models.ReconciliationCompleted {
+		t.Fatalf("new run = %+v", got)
+	}
+}
@@ -0,0 +1,369 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/quotaapi/internal/config"
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/models"
+	"example.com/quotaapi/internal/openstack"
+)
+
+// CapacityTotals is the part of a quota that the capacity guard compares
+// with the cluster. -1 (제한 없음) 쿼타는 합산하지 않는다.
+type CapacityTotals struct {
+	Cores     int `json:"cores"`
+	RAMMB     int `json:"ramMB"`
+	Gigabytes int `json:"gigabytes"`
+}
+
+func capacityTotalsOf(q models.QuotaProfile) CapacityTotals {
+	return CapacityTotals{Cores: max(q.Cores, 0), RAMMB: max(q.RAMMB, 0), Gigabytes: max(q.Gigabytes, 0)}
+}
+
+func (t CapacityTotals) add(o CapacityTotals) CapacityTotals {
+	return CapacityTotals{Cores: t.Cores + o.Cores, RAMMB: t.RAMMB + o.RAMMB, Gigabytes: t.Gigabytes + o.Gigabytes}
+}
+
+func (t CapacityTotals) sub(o CapacityTotals) CapacityTotals {
+	return CapacityTotals{Cores: t.Cores - o.Cores, RAMMB: t.RAMMB - o.RAMMB, Gigabytes: t.Gigabytes - o.Gigabytes}
+}
+
+// CapacityReport compares the quota committed to student projects with
+// what the cluster can deliver at the configured over-commit ratios.
+type CapacityReport struct {
+	Mode      config.CapacityMode       `json:"mode"`
+	Capacity  openstack.ClusterCapacity `json:"capacity"`
+	Limit     CapacityTotals            `json:"limit"`     // capacity × ratio, gigabytes -1 = 검사하지 않음
+	Committed CapacityTotals            `json:"committed"` // 프로젝트가 있는 학생들의 유효 쿼타 합
+	Projects  int                       `json:"projects"`
+	// Failed counts students whose effective quota could not be resolved
+	// and is missing from Committed.
+	Failed     int       `json:"failed,omitempty"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// CapacityCheck is the verdict on one change. Allowed 가 true 이고
+// Exceeded 가 비어 있지 않으면 warn 모드에서 통과한 초과다.
+type CapacityCheck struct {
+	Allowed   bool                `json:"allowed"`
+	Mode      config.CapacityMode `json:"mode"`
+	Change    string              `json:"change"`
+	Committed CapacityTotals      `json:"committed"`
+	Delta     CapacityTotals      `json:"delta"`
+	Limit     CapacityTotals      `json:"limit"`
+	Exceeded  []string            `json:"exceeded,omitempty"`
+}
+
+// Warning reports whether the change was allowed despite exceeding a limit.
+func (c *CapacityCheck) Warning() bool {
+	return c.Allowed && len(c.Exceeded) > 0
+}
+
+// Message describes the exceeded dimensions for errors and logs.
+func (c *CapacityCheck) Message() string {
+	after := c.Committed.add(c.Delta)
+	var parts []string
+	for _, dim := range c.Exceeded {
+		switch dim {
+		case "cores":
+			parts = append(parts, fmt.Sprintf("cores %d/%d", after.Cores, c.Limit.Cores))
+		case "ramMB":
+			parts = append(parts, fmt.Sprintf("ramMB %d/%d", after.RAMMB, c.Limit.RAMMB))
+		case "gigabytes":
+			parts = append(parts, fmt.Sprintf("gigabytes %d/%d", after.Gigabytes, c.Limit.Gigabytes))
+		}
+	}
+	return fmt.Sprintf("%s would exceed cluster capacity: %s", c.Change, strings.Join(parts, ", "))
+}
+
+// CapacityService guards course creation, enrollment and profile changes
+// against committing more quota than the cluster can deliver. 용량과
+// committed 합은 cfg.CacheTTL 동안 캐시하고, 허용된 변경의 증가분은 캐시에
+// 바로 더한다 (저장에 실패한 변경은 다음 재계산 때 빠진다).
+type CapacityService struct {
+	db      database.Store
+	clients *openstack.Clients
+	cfg     config.CapacityConfig
+
+	mu     sync.Mutex // report 와 check+반영을 직렬화
+	report *CapacityReport
+}
+
+// NewCapacityService creates a capacity guard. clients 는 하이퍼바이저와
+// 스토리지 풀을 조회할 수 있는 admin 자격이어야 한다.
+func NewCapacityService(db database.Store, clients *openstack.Clients, cfg config.CapacityConfig) *CapacityService {
+	if cfg.Mode == "" {
+		cfg.Mode = config.DefaultCapacityMode
+	}
+	if cfg.CPURatio <= 0 {
+		cfg.CPURatio = config.DefaultCapacityCPURatio
+	}
+	if cfg.RAMRatio <= 0 {
+		cfg.RAMRatio = config.DefaultCapacityRAMRatio
+	}
+	if cfg.DiskRatio <= 0 {
+		cfg.DiskRatio = config.DefaultCapacityDiskRatio
+	}
+	return &CapacityService{db: db, clients: clients, cfg: cfg}
+}
+
+// Report returns the (cached) capacity report.
+func (s *CapacityService) Report(ctx context.Context) (*CapacityReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	report, err := s.reportLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	copied := *report
+	return &copied, nil
+}
+
+func (s *CapacityService) reportLocked(ctx context.Context) (*CapacityReport, error) {
+	if s.report != nil && time.Since(s.report.ComputedAt) < s.cfg.CacheTTL {
+		return s.report, nil
+	}
+
+	capacity, err := s.clients.GetClusterCapacity(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster capacity: %w", err)
+	}
+	students, err := s.db.GetAllStudents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list students: %w", err)
+	}
+
+	report := &CapacityReport{
+		Mode:     s.cfg.Mode,
+		Capacity: *capacity,
+		Limit: CapacityTotals{
+			Cores:     int(float64(capacity.VCPUs) * s.cfg.CPURatio),
+			RAMMB:     int(float64(capacity.MemoryMB) * s.cfg.RAMRatio),
+			Gigabytes: -1,
+		},
+	}
+	if capacity.StorageGB >= 0 {
+		report.Limit.Gigabytes = int(float64(capacity.StorageGB) * s.cfg.DiskRatio)
+	}
+	for _, student := range students {
+		if student.KeystoneProjectID == "" {
+			continue
+		}
+		quota, err := effectiveQuota(s.db, student)
+		if err != nil {
+			log.Printf("capacity: skipping student %s: %v", student.StudentID, err)
+			report.Failed++
+			continue
+		}
+		report.Projects++
+		report.Committed = report.Committed.add(capacityTotalsOf(quota))
+	}
+	report.ComputedAt = time.Now()
+	s.report = report
+	return report, nil
+}
+
+// CheckEnrollment checks adding or changing enrollment. 학생의 유효 쿼타를
+// 등록 전후로 계산해 차이만큼을 검사한다.
+func (s *CapacityService) CheckEnrollment(ctx context.Context, enrollment *models.Enrollment) (*CapacityCheck, error) {
+	change := fmt.Sprintf("enrolling %s in %s", enrollment.StudentID, enrollment.CourseID)
+	if s.cfg.Mode == config.CapacityOff {
+		return s.allowed(change), nil
+	}
+	student, err := s.db.GetStudent(enrollment.StudentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get student: %w", err)
+	}
+	delta := s.delta([]*models.Student{student}, &capacityOverlay{Store: s.db, enrollment: enrollment})
+	return s.check(ctx, change, delta)
+}
+
+// CheckNewCourse checks a course that does not exist yet. 수강생이 없으므로
+// expectedStudents × 프로파일 한도(sum 정책 기준 상한)를 증가분으로 본다.
+func (s *CapacityService) CheckNewCourse(ctx context.Context, course *models.Course, expectedStudents int) (*CapacityCheck, error) {
+	change := fmt.Sprintf("creating course %s for %d students", course.CourseID, expectedStudents)
+	if s.cfg.Mode == config.CapacityOff || expectedStudents <= 0 {
+		return s.allowed(change), nil
+	}
+	profile, err := s.db.GetProfile(course.ProfileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+	per := capacityTotalsOf(profile.Limits)
+	delta := CapacityTotals{
+		Cores:     per.Cores * expectedStudents,
+		RAMMB:     per.RAMMB * expectedStudents,
+		Gigabytes: per.Gigabytes * expectedStudents,
+	}
+	return s.check(ctx, change, delta)
+}
+
+// CheckCourseChange checks replacing a course with course (예: 프로파일
+// 변경) for the students enrolled in it.
+func (s *CapacityService) CheckCourseChange(ctx context.Context, course *models.Course) (*CapacityCheck, error) {
+	change := fmt.Sprintf("changing course %s to profile %s", course.CourseID, course.ProfileName)
+	if s.cfg.Mode == config.CapacityOff {
+		return s.allowed(change), nil
+	}
+	enrollments, err := s.db.ListCourseEnrollments(course.CourseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list enrollments: %w", err)
+	}
+	seen := make(map[string]bool)
+	var students []*models.Student
+	for _, enrollment := range enrollments {
+		if seen[enrollment.StudentID] {
+			continue
+		}
+		seen[enrollment.StudentID] = true
+		student, err := s.db.GetStudent(enrollment.StudentID)
+		if err != nil {
+			log.Printf("capacity: skipping student %s: %v", enrollment.StudentID, err)
+			continue
+		}
+		students = append(students, student)
+	}
+	delta := s.delta(students, &capacityOverlay{Store: s.db, course: course})
+	return s.check(ctx, change, delta)
+}
+
+// CheckProfile checks replacing a catalog profile's limits. 기본 쿼타와
+// 과목 어디에서 쓰이든 반영되도록 모든 학생을 다시 계산한다.
+func (s *CapacityService) CheckProfile(ctx context.Context, profile *models.Profile) (*CapacityCheck, error) {
+	change := fmt.Sprintf("updating profile %s", profile.Name)
+	if s.cfg.Mode == config.CapacityOff {
+		return s.allowed(change), nil
+	}
+	students, err := s.db.GetAllStudents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list students: %w", err)
+	}
+	delta := s.delta(students, &capacityOverlay{Store: s.db, profile: profile})
+	return s.check(ctx, change, delta)
+}
+
+func (s *CapacityService) allowed(change string) *CapacityCheck {
+	return &CapacityCheck{Allowed: true, Mode: s.cfg.Mode, Change: change}
+}
+
+// delta sums how much each student's effective quota grows when read
+// through overlay instead of the store. 계산할 수 없는 학생은 건너뛴다.
+func (s *CapacityService) delta(students []*models.Student, overlay database.Store) CapacityTotals {
+	var delta CapacityTotals
+	for _, student := range students {
+		if student.KeystoneProjectID == "" {
+			continue // 프로젝트가 없으면 committed 에도 들어가지 않는다
+		}
+		before, err := effectiveQuota(s.db, student)
+		if err != nil {
+			log.Printf("capacity: skipping student %s: %v", student.StudentID, err)
+			continue
+		}
+		after, err := effectiveQuota(overlay, student)
+		if err != nil {
+			log.Printf("capacity: skipping student %s: %v", student.StudentID, err)
+			continue
+		}
+		delta = delta.add(capacityTotalsOf(after).sub(capacityTotalsOf(before)))
+	}
+	return delta
+}
+
+// check compares committed + delta with the limits. 늘어나는 항목만
+// 검사하므로 이미 초과한 클러스터에서도 줄이는 변경은 항상 통과한다.
+func (s *CapacityService) check(ctx context.Context, change string, delta CapacityTotals) (*CapacityCheck, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	report, err := s.reportLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &CapacityCheck{
+		Allowed:   true,
+		Mode:      s.cfg.Mode,
+		Change:    change,
+		Committed: report.Committed,
+		Delta:     delta,
+		Limit:     report.Limit,
+	}
+	exceeds := func(committed, delta, limit int) bool {
+		return delta > 0 && limit >= 0 && committed+delta > limit
+	}
+	if exceeds(c.Committed.Cores, delta.Cores, c.Limit.Cores) {
+		c.Exceeded = append(c.Exceeded, "cores")
+	}
+	if exceeds(c.Committed.RAMMB, delta.RAMMB, c.Limit.RAMMB) {
+		c.Exceeded = append(c.Exceeded, "ramMB")
+	}
+	if exceeds(c.Committed.Gigabytes, delta.Gigabytes, c.Limit.Gigabytes) {
+		c.Exceeded = append(c.Exceeded, "gigabytes")
+	}
+
+	if len(c.Exceeded) > 0 {
+		if s.cfg.Mode == config.CapacityBlock {
+			c.Allowed = false
+			log.Printf("capacity: blocked: %s", c.Message())
+			return c, nil
+		}
+		log.Printf("capacity: warning: %s", c.Message())
+	}
+	report.Committed = report.Committed.add(delta)
+	return c, nil
+}
+
+// effectiveQuota resolves a student's effective quota the same way
+// reconciliation does, reading through db.
+func effectiveQuota(db database.Store, student *models.Student) (models.QuotaProfile, error) {
+	summary := StudentQuotaSummary{StudentID: student.StudentID}
+	if !(&QuotaReconciliationService{db: db}).resolveDesiredQuota(student, &summary) {
+		return models.QuotaProfile{}, errors.New(summary.ErrorMessage)
+	}
+	return summary.EffectiveQuota, nil
+}
+
+// capacityOverlay is the store as it would look after a pending change:
+// 저장 전의 프로파일, 과목 또는 수강 등록 하나를 덮어써서 보여준다.
+type capacityOverlay struct {
+	database.Store
+	profile    *models.Profile
+	course     *models.Course
+	enrollment *models.Enrollment
+}
+
+func (o *capacityOverlay) GetProfile(name string) (*models.Profile, error) {
+	if o.profile != nil && o.profile.Name == name {
+		profile := *o.profile
+		return &profile, nil
+	}
+	return o.Store.GetProfile(name)
+}
+
+func (o *capacityOverlay) GetCourse(courseID string) (*models.Course, error) {
+	if o.course != nil && o.course.CourseID == courseID {
+		course := *o.course
+		return &course, nil
+	}
+	return o.Store.GetCourse(courseID)
+}
+
+func (o *capacityOverlay) GetStudentEnrollments(studentID string) ([]models.Enrollment, error) {
+	enrollments, err := o.Store.GetStudentEnrollments(studentID)
+	if err != nil || o.enrollment == nil || o.enrollment.StudentID != studentID {
+		return enrollments, err
+	}
+	for i := range enrollments {
+		if enrollments[i].CourseID == o.enrollment.CourseID {
+			enrollments[i] = *o.enrollment
+			return enrollments, nil
+		}
+	}
+	return append(enrollments, *o.enrollment), nil
+}
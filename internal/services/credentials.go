@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/openstack"
+)
+
+// CredentialTokenTTL is how long a retrieval token stays redeemable.
+const CredentialTokenTTL = 7 * 24 * time.Hour
+
+// ErrAccountNotReady is returned when a valid token is redeemed before the
+// student's Keystone user exists (프로젝트 생성이 아직 백그라운드에서 진행 중).
+// 토큰은 되살려 두므로 나중에 같은 토큰으로 다시 시도할 수 있다.
+var ErrAccountNotReady = errors.New("keystone account is not provisioned yet")
+
+// RetrievalToken is a one-time token handed to staff for delivery to the
+// student. 원문은 발급 시 한 번만 반환되고 DB 에는 해시만 남는다.
+type RetrievalToken struct {
+	Token     string    `json:"credential_token"`
+	ExpiresAt time.Time `json:"credential_token_expires_at"`
+}
+
+// StudentCredential is returned exactly once when a token is redeemed.
+// Keystone 에 change_password_upon_first_use 가 켜져 있으면 첫 로그인 시 변경을 요구한다.
+type StudentCredential struct {
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	ProjectID   string `json:"project_id"`
+	ProjectName string `json:"project_name"`
+}
+
+// CredentialService issues and redeems one-time credential retrieval tokens
+// for student Keystone users. 비밀번호 자체는 어디에도 저장하지 않고,
+// 토큰을 교환하는 순간 새로 생성해 Keystone 에 설정한 뒤 한 번만 돌려준다.
+type CredentialService struct {
+	db         database.Store
+	projectMgr *openstack.ProjectManager
+}
+
+// NewCredentialService creates a new credential service
+func NewCredentialService(db database.Store, projectMgr *openstack.ProjectManager) *CredentialService {
+	return &CredentialService{db: db, projectMgr: projectMgr}
+}
+
+// IssueToken creates a new retrieval token for a student, invalidating any
+// previous one.
+func (s *CredentialService) IssueToken(studentID string) (*RetrievalToken, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("generate credential token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	expiresAt := time.Now().Add(CredentialTokenTTL).UTC()
+
+	if err := s.db.SaveCredentialToken(studentID, hashCredentialToken(token), expiresAt); err != nil {
+		return nil, err
+	}
+	return &RetrievalToken{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// Redeem exchanges a retrieval token for a freshly generated password.
+// 토큰을 가장 먼저 원자적으로 소비하므로 동시 요청 중 하나만 성공하고,
+// 토큰이 없는 호출자는 학생 존재 여부나 진행 상태를 알 수 없다 (모두
+// ErrCredentialTokenInvalid). 이후 단계가 실패하면 같은 토큰을 원래 만료
+// 시각 그대로 되살린다.
+func (s *CredentialService) Redeem(ctx context.Context, studentID, token string) (*StudentCredential, error) {
+	hash := hashCredentialToken(token)
+	if err := s.db.ConsumeCredentialToken(studentID, hash); err != nil {
+		return nil, err
+	}
+	// 그 사이 Reset 으로 새 토큰이 발급됐다면 되살리지 않는다 (RearmCredentialToken)
+	rearm := func() {
+		if err := s.db.RearmCredentialToken(studentID, hash); err != nil {
+			log.Printf("credentials: failed to re-arm token for student %s: %v", studentID, err)
+		}
+	}
+
+	student, err := s.db.GetStudent(studentID)
+	if err != nil {
+		return nil, database.ErrCredentialTokenInvalid
+	}
+	if student.KeystoneUserID == "" {
+		rearm()
+		return nil, ErrAccountNotReady
+	}
+
+	password, err := s.projectMgr.ResetUserPassword(ctx, student.KeystoneUserID)
+	if err != nil {
+		rearm()
+		return nil, err
+	}
+
+	log.Printf("credentials: student %s redeemed a retrieval token", studentID)
+	return &StudentCredential{
+		Username:    openstack.StudentUserName(studentID),
+		Password:    password,
+		ProjectID:   student.KeystoneProjectID,
+		ProjectName: openstack.StudentProjectName(studentID),
+	}, nil
+}
+
+// Reset scrambles the student's current password (so a leaked one stops
+// working) and issues a new retrieval token.
+func (s *CredentialService) Reset(ctx context.Context, studentID string) (*RetrievalToken, error) {
+	student, err := s.db.GetStudent(studentID)
+	if err != nil {
+		return nil, fmt.Errorf("student not found: %s", studentID)
+	}
+	if student.KeystoneUserID != "" {
+		// 반환된 비밀번호는 버린다: 학생은 새 토큰으로만 다시 받을 수 있다
+		if _, err := s.projectMgr.ResetUserPassword(ctx, student.KeystoneUserID); err != nil {
+			return nil, err
+		}
+	}
+	return s.IssueToken(studentID)
+}
+
+func hashCredentialToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
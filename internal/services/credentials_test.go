@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/models"
+	"example.com/quotaapi/internal/openstack"
+	"example.com/quotaapi/internal/openstack/openstacktest"
+)
+
+func TestRedeemChecksTokenFirst(t *testing.T) {
+	fake := openstacktest.NewServer()
+	defer fake.Close()
+	osc, err := openstack.NewServiceClients(fake.Config())
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := database.NewMemoryStore()
+	svc := NewCredentialService(db, openstack.NewProjectManager(osc))
+	ctx := context.Background()
+
+	// 아직 Keystone 사용자가 없는 학생
+	if err := db.CreateStudent(&models.Student{StudentID: "pending", Name: "P", Department: "cs"}); err != nil {
+		t.Fatal(err)
+	}
+	token, err := svc.IssueToken("pending")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		studentID string
+		token     string
+		want      error
+	}{
+		{name: "unknown student", studentID: "nobody", token: "guess", want: database.ErrCredentialTokenInvalid},
+		{name: "bad token for a pending student", studentID: "pending", token: "guess", want: database.ErrCredentialTokenInvalid},
+		{name: "valid token before provisioning", studentID: "pending", token: token.Token, want: ErrAccountNotReady},
+		{name: "token was re-armed", studentID: "pending", token: token.Token, want: ErrAccountNotReady},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Redeem(ctx, tt.studentID, tt.token); !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// 새 토큰이 발급된 뒤에는 예전 토큰의 실패가 새 토큰을 덮어쓰지 않는다
+	reissued, err := svc.IssueToken("pending")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Redeem(ctx, "pending", token.Token); !errors.Is(err, database.ErrCredentialTokenInvalid) {
+		t.Errorf("old token after reissue: err = %v", err)
+	}
+	if _, err := svc.Redeem(ctx, "pending", reissued.Token); !errors.Is(err, ErrAccountNotReady) {
+		t.Errorf("reissued token: err = %v, want %v", err, ErrAccountNotReady)
+	}
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"example.com/quotaapi/internal/models"
+)
+
+// QuotaFieldDiff is one limit whose OpenStack value differs from the
+// desired one.
+type QuotaFieldDiff struct {
+	Field   string `json:"field"`
+	Desired int    `json:"desired"`
+	Actual  int    `json:"actual"`
+}
+
+// diffQuota lists the fields of actual that differ from desired, in
+// quotaKeys order.
+func diffQuota(desired, actual models.QuotaProfile) []QuotaFieldDiff {
+	var diffs []QuotaFieldDiff
+	for _, key := range quotaKeys(desired, actual) {
+		want, got := quotaValue(desired, key), quotaValue(actual, key)
+		if want != got {
+			diffs = append(diffs, QuotaFieldDiff{Field: key, Desired: want, Actual: got})
+		}
+	}
+	return diffs
+}
+
+// ProjectDrift is a student project whose OpenStack quota does not match
+// what reconciliation would apply.
+type ProjectDrift struct {
+	StudentID     string                `json:"student_id"`
+	StudentName   string                `json:"student_name"`
+	ProjectID     string                `json:"project_id"`
+	DesiredQuota  models.QuotaProfile   `json:"desired_quota"` // 사용량 clamp 까지 반영한 값
+	ActualQuota   models.QuotaProfile   `json:"actual_quota"`
+	Fields        []QuotaFieldDiff      `json:"fields"`
+	QuotaBlockers []models.QuotaBlocker `json:"quota_blockers,omitempty"`
+}
+
+// DriftFailure is a student whose drift could not be determined.
+type DriftFailure struct {
+	StudentID string `json:"student_id"`
+	Error     string `json:"error"`
+}
+
+// DriftReport lists the projects whose actual quota differs from the
+// desired quota. 일치하는 프로젝트는 개수만 센다.
+type DriftReport struct {
+	CheckedAt     time.Time      `json:"checked_at"`
+	TotalStudents int            `json:"total_students"`
+	CheckedCount  int            `json:"checked_count"` // OpenStack 프로젝트가 있어 비교한 학생 수
+	InSyncCount   int            `json:"in_sync_count"`
+	DriftedCount  int            `json:"drifted_count"`
+	FailedCount   int            `json:"failed_count"`
+	Partial       bool           `json:"partial"`
+	Error         string         `json:"error,omitempty"`
+	Drifted       []ProjectDrift `json:"drifted"`
+	Failures      []DriftFailure `json:"failures,omitempty"`
+
+	// ReconcileDrift 로 드리프트 프로젝트만 리콘실한 결과
+	Reconciliation *BulkReconciliationResult `json:"reconciliation,omitempty"`
+}
+
+// DetectDrift compares the desired quota of every student with the limits
+// currently set in Nova, Cinder and Neutron without changing anything.
+// Horizon 등에서 직접 바꾼 쿼타를 찾는 데 쓴다.
+func (s *QuotaReconciliationService) DetectDrift(ctx context.Context) (*DriftReport, error) {
+	report, _, err := s.detectDrift(ctx)
+	return report, err
+}
+
+// ReconcileDrift detects drift and then reconciles only the drifted
+// projects as one persisted run (trigger "drift").
+func (s *QuotaReconciliationService) ReconcileDrift(ctx context.Context) (*DriftReport, error) {
+	ctx, unlock, err := s.lockBulk(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	report, drifted, err := s.detectDrift(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(drifted) == 0 || ctx.Err() != nil {
+		return report, nil
+	}
+	scope := &models.ReconciliationScope{}
+	for _, student := range drifted {
+		scope.StudentIDs = append(scope.StudentIDs, student.StudentID)
+	}
+	report.Reconciliation, err = s.runBulk(ctx, "drift", scope, drifted)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// detectDrift builds the report and returns the drifted students in
+// report order.
+func (s *QuotaReconciliationService) detectDrift(ctx context.Context) (*DriftReport, []*models.Student, error) {
+	if s.projectMgr == nil {
+		return nil, nil, errors.New("OpenStack is not configured")
+	}
+	all, err := s.db.GetAllStudents()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get students: %w", err)
+	}
+	var students []*models.Student
+	for _, student := range all {
+		if student.KeystoneProjectID != "" {
+			students = append(students, student)
+		}
+	}
+
+	report := &DriftReport{
+		CheckedAt:     time.Now(),
+		TotalStudents: len(all),
+		Drifted:       []ProjectDrift{},
+	}
+	drifts := make([]*ProjectDrift, len(students))
+	failures := make([]error, len(students))
+	s.runPool(ctx, len(students), func(i int) {
+		drifts[i], failures[i] = s.checkStudentDrift(ctx, students[i])
+	}, func(i int) {
+		report.CheckedCount++
+		switch {
+		case failures[i] != nil:
+			report.FailedCount++
+		case drifts[i] != nil:
+			report.DriftedCount++
+		default:
+			report.InSyncCount++
+		}
+	})
+
+	var drifted []*models.Student
+	for i, student := range students {
+		switch {
+		case failures[i] != nil:
+			report.Failures = append(report.Failures, DriftFailure{StudentID: student.StudentID, Error: failures[i].Error()})
+		case drifts[i] != nil:
+			report.Drifted = append(report.Drifted, *drifts[i])
+			drifted = append(drifted, student)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		report.Partial = true
+		report.Error = fmt.Sprintf("stopped after %d of %d projects: %v", report.CheckedCount, len(students), err)
+	}
+
+	log.Printf("Quota drift check: %d drifted, %d in sync, %d failed of %d projects",
+		report.DriftedCount, report.InSyncCount, report.FailedCount, len(students))
+	return report, drifted, nil
+}
+
+// checkStudentDrift returns the student's drift, or nil if the project
+// already has the quota reconciliation would apply.
+func (s *QuotaReconciliationService) checkStudentDrift(ctx context.Context, student *models.Student) (*ProjectDrift, error) {
+	summary := StudentQuotaSummary{StudentID: student.StudentID}
+	if !s.resolveDesiredQuota(student, &summary) {
+		return nil, errors.New(summary.ErrorMessage)
+	}
+	current, err := s.quotas().Get(ctx, student.KeystoneProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quota: %w", err)
+	}
+	actual, usage := current.Limits(), current.Usage()
+	desired, blockers := clampToUsage(withUnmanagedVolumeTypes(summary.EffectiveQuota, actual), usage)
+	fields := diffQuota(desired, actual)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return &ProjectDrift{
+		StudentID:     student.StudentID,
+		StudentName:   student.Name,
+		ProjectID:     student.KeystoneProjectID,
+		DesiredQuota:  desired,
+		ActualQuota:   actual,
+		Fields:        fields,
+		QuotaBlockers: blockers,
+	}, nil
+}
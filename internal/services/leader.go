@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"example.com/quotaapi/internal/config"
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/models"
+)
+
+// backgroundLeaseName is the lease every replica competes for; its holder
+// runs all registered background tasks.
+const backgroundLeaseName = "background"
+
+// LeaderStatus describes this replica's view of leadership.
+type LeaderStatus struct {
+	ID          string              `json:"id"` // 이 레플리카의 LEADER_ID
+	IsLeader    bool                `json:"is_leader"`
+	LeaderSince *time.Time          `json:"leader_since,omitempty"`
+	Lease       *models.LeaderLease `json:"lease"` // DB 에 기록된 현재 lease (없으면 null)
+	Tasks       []string            `json:"tasks"` // 리더일 때 실행하는 작업
+}
+
+type leaderTask struct {
+	name string
+	run  func(ctx context.Context)
+}
+
+// LeaderElector elects one server replica through a lease in the store and
+// runs the registered background tasks only while this replica holds it.
+// 리더는 RenewInterval 마다 lease 를 갱신하고, 갱신이 끊기면 LeaseTTL 이
+// 지난 뒤 다른 레플리카가 이어받는다. 리더십을 잃으면 작업의 ctx 를 취소한다.
+type LeaderElector struct {
+	db    database.LeaderStore
+	cfg   config.LeaderConfig
+	tasks []leaderTask
+
+	mu     sync.Mutex
+	leader bool
+	since  *time.Time
+}
+
+// NewLeaderElector creates an elector; zero cfg fields take the defaults.
+func NewLeaderElector(db database.LeaderStore, cfg config.LeaderConfig) *LeaderElector {
+	return &LeaderElector{db: db, cfg: leaderDefaults(cfg)}
+}
+
+// leaderDefaults fills zero or inconsistent lease settings with the defaults.
+func leaderDefaults(cfg config.LeaderConfig) config.LeaderConfig {
+	if cfg.ID == "" {
+		cfg.ID = config.DefaultLeaderID()
+	}
+	if cfg.RenewInterval <= 0 {
+		cfg.RenewInterval = config.DefaultLeaderRenewInterval
+	}
+	if cfg.LeaseTTL < 2*cfg.RenewInterval {
+		cfg.LeaseTTL = max(config.DefaultLeaderLeaseTTL, 2*cfg.RenewInterval)
+	}
+	return cfg
+}
+
+// Go registers a task that runs while this replica is leader. run must
+// return soon after its ctx is done. Run 전에 등록해야 한다.
+func (e *LeaderElector) Go(name string, run func(ctx context.Context)) {
+	e.tasks = append(e.tasks, leaderTask{name: name, run: run})
+}
+
+// Run competes for leadership until ctx is done, starting the tasks when
+// this replica becomes leader and stopping them when it loses the lease.
+// 종료 시에는 작업을 멈춘 뒤 lease 를 놓아 다른 레플리카가 바로 이어받게 한다.
+func (e *LeaderElector) Run(ctx context.Context) {
+	log.Printf("leader: %s competing for %q (lease %s, renew every %s)",
+		e.cfg.ID, backgroundLeaseName, e.cfg.LeaseTTL, e.cfg.RenewInterval)
+
+	var stop func()
+	var renewed time.Time
+	stepDown := func(reason string) {
+		log.Printf("leader: %s stepping down: %s", e.cfg.ID, reason)
+		stop()
+		stop = nil
+		e.setLeader(false)
+	}
+
+	ticker := time.NewTicker(e.cfg.RenewInterval)
+	defer ticker.Stop()
+	for {
+		lease, err := e.db.AcquireLeaderLease(backgroundLeaseName, e.cfg.ID, e.cfg.LeaseTTL)
+		switch {
+		case err != nil:
+			log.Printf("leader: %v", err)
+			// 갱신하지 못한 채 lease 가 끝나면 다른 레플리카가 리더가 되므로
+			// 만료 전에(갱신 한 번만큼 여유를 두고) 먼저 물러난다
+			if stop != nil && time.Since(renewed) >= e.cfg.LeaseTTL-e.cfg.RenewInterval {
+				stepDown("lease could not be renewed")
+			}
+		case lease != nil && lease.Holder == e.cfg.ID:
+			renewed = time.Now()
+			if stop == nil {
+				log.Printf("leader: %s is now leader, starting %d tasks", e.cfg.ID, len(e.tasks))
+				stop = e.start(ctx)
+				e.setLeader(true)
+			}
+		case stop != nil:
+			stepDown("lease taken over")
+		}
+
+		select {
+		case <-ctx.Done():
+			if stop != nil {
+				stop()
+				e.setLeader(false)
+				if err := e.db.ReleaseLeaderLease(backgroundLeaseName, e.cfg.ID); err != nil {
+					log.Printf("leader: %v", err)
+				}
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// start runs every task under a context derived from ctx and returns a
+// function that cancels them and waits for them to return.
+func (e *LeaderElector) start(ctx context.Context) func() {
+	taskCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for _, task := range e.tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			task.run(taskCtx)
+		}()
+	}
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+func (e *LeaderElector) setLeader(leader bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.leader = leader
+	if leader {
+		now := time.Now()
+		e.since = &now
+	} else {
+		e.since = nil
+	}
+}
+
+// IsLeader reports whether this replica is running the background tasks.
+func (e *LeaderElector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader
+}
+
+// Status returns this replica's leadership and the lease currently stored,
+// which names the leader even when called on a follower.
+func (e *LeaderElector) Status() (*LeaderStatus, error) {
+	lease, err := e.db.GetLeaderLease(backgroundLeaseName)
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	status := &LeaderStatus{
+		ID:          e.cfg.ID,
+		IsLeader:    e.leader,
+		LeaderSince: e.since,
+		Lease:       lease,
+		Tasks:       make([]string, 0, len(e.tasks)),
+	}
+	for _, task := range e.tasks {
+		status.Tasks = append(status.Tasks, task.name)
+	}
+	return status, nil
+}
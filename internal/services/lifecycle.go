@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"example.com/quotaapi/internal/config"
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/models"
+	"example.com/quotaapi/internal/openstack"
+)
+
+// ErrLifecycleRunning is returned when a lifecycle pass is already running
+// in this process (스케줄러와 수동 실행이 겹친 경우).
+var ErrLifecycleRunning = errors.New("lifecycle pass already running")
+
+// lifecycleRunning serializes passes across every LifecycleService.
+var lifecycleRunning sync.Mutex
+
+// ReclaimNotifier warns a student that a course quota will be reclaimed.
+type ReclaimNotifier interface {
+	NotifyReclaim(ctx context.Context, student *models.Student, enrollment models.Enrollment) error
+}
+
+// LogNotifier writes reclaim warnings to the server log. 메일 등 실제 알림
+// 채널이 붙기 전까지의 기본 구현이다.
+type LogNotifier struct{}
+
+// NotifyReclaim logs the warning.
+func (LogNotifier) NotifyReclaim(_ context.Context, student *models.Student, e models.Enrollment) error {
+	log.Printf("lifecycle: warning %s <%s>: quota of course %s will be reclaimed at %s",
+		student.StudentID, student.Email, e.CourseID, e.GraceUntil.Format(time.RFC3339))
+	return nil
+}
+
+// LifecycleEvent is one transition made by a lifecycle pass.
+type LifecycleEvent struct {
+	StudentID string `json:"student_id"`
+	CourseID  string `json:"course_id,omitempty"`
+	Action    string `json:"action"` // completed, warned, archived, reclaimed, failed
+	Detail    string `json:"detail,omitempty"`
+}
+
+// LifecycleResult summarizes one lifecycle pass.
+type LifecycleResult struct {
+	RanAt          time.Time        `json:"ran_at"`
+	CompletedCount int              `json:"completed_count"`
+	WarnedCount    int              `json:"warned_count"`
+	ReclaimedCount int              `json:"reclaimed_count"`
+	ArchivedCount  int              `json:"archived_count"`
+	FailedCount    int              `json:"failed_count"`
+	Events         []LifecycleEvent `json:"events"`
+}
+
+func (r *LifecycleResult) record(ev LifecycleEvent) {
+	switch ev.Action {
+	case "completed":
+		r.CompletedCount++
+	case "warned":
+		r.WarnedCount++
+	case "reclaimed":
+		r.ReclaimedCount++
+	case "archived":
+		r.ArchivedCount++
+	case "failed":
+		r.FailedCount++
+	}
+	r.Events = append(r.Events, ev)
+}
+
+// LifecycleService moves enrollments through the end-of-course lifecycle:
+// active → (EndAt 경과) completed, 유예 기간 동안 과목 쿼타 유지 →
+// (회수 WarnBefore 전) 경고 → (GraceUntil 경과) 쿼타 회수. Archive 가 켜져
+// 있으면 남은 과목이 없는 학생의 서버를 회수 전에 shelve 한다.
+// 각 단계는 수강 행에 기록되므로 중간에 실패해도 다음 실행에서 이어간다.
+type LifecycleService struct {
+	db         database.Store
+	projectMgr *openstack.ProjectManager
+	reconciler *QuotaReconciliationService
+	notifier   ReclaimNotifier
+	cfg        config.LifecycleConfig
+}
+
+// NewLifecycleService creates a new lifecycle service. reconciler 는 main 에서
+// RECONCILE_* 설정을 적용한 공용 인스턴스를 넘긴다 (동시성/RPS 제한 공유).
+func NewLifecycleService(db database.Store, projectMgr *openstack.ProjectManager, reconciler *QuotaReconciliationService, cfg config.LifecycleConfig, notifier ReclaimNotifier) *LifecycleService {
+	return &LifecycleService{
+		db:         db,
+		projectMgr: projectMgr,
+		reconciler: reconciler,
+		notifier:   notifier,
+		cfg:        cfg,
+	}
+}
+
+// Run executes a pass every cfg.Interval until ctx is done. Interval 이 0
+// 이면 아무것도 하지 않는다.
+func (s *LifecycleService) Run(ctx context.Context) {
+	if s.cfg.Interval <= 0 {
+		log.Println("lifecycle: scheduler disabled (LIFECYCLE_INTERVAL=0)")
+		return
+	}
+	log.Printf("lifecycle: scheduler every %s (grace %s, warn %s before, archive=%t)",
+		s.cfg.Interval, s.cfg.GracePeriod, s.cfg.WarnBefore, s.cfg.Archive)
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if result, err := s.RunOnce(ctx); err != nil {
+			log.Printf("lifecycle: pass failed: %v", err)
+		} else if len(result.Events) > 0 {
+			log.Printf("lifecycle: %d completed, %d warned, %d archived, %d reclaimed, %d failed",
+				result.CompletedCount, result.WarnedCount, result.ArchivedCount, result.ReclaimedCount, result.FailedCount)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce performs a single lifecycle pass.
+func (s *LifecycleService) RunOnce(ctx context.Context) (*LifecycleResult, error) {
+	if !lifecycleRunning.TryLock() {
+		return nil, ErrLifecycleRunning
+	}
+	defer lifecycleRunning.Unlock()
+
+	now := time.Now()
+	result := &LifecycleResult{RanAt: now, Events: []LifecycleEvent{}}
+
+	// 1. 종료일이 지난 활성 수강 → completed (유예 기간 끝 = EndAt + GracePeriod)
+	active, err := s.db.ListEnrollmentsByStatus("active")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active enrollments: %w", err)
+	}
+	for _, e := range active {
+		if !e.EndAt.Before(now) {
+			continue
+		}
+		graceUntil := e.EndAt.Add(s.cfg.GracePeriod)
+		ev := LifecycleEvent{StudentID: e.StudentID, CourseID: e.CourseID, Action: "completed",
+			Detail: "quota kept until " + graceUntil.Format(time.RFC3339)}
+		if err := s.db.UpdateEnrollment(e.StudentID, e.CourseID, map[string]interface{}{
+			"status":      "completed",
+			"grace_until": graceUntil,
+		}); err != nil {
+			ev.Action, ev.Detail = "failed", err.Error()
+		}
+		result.record(ev)
+	}
+
+	// 2. 유예 기간이 곧 끝나는 수강은 경고, 끝난 수강은 학생별로 모아 회수
+	completed, err := s.db.ListEnrollmentsByStatus("completed")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list completed enrollments: %w", err)
+	}
+	reclaim := map[string][]string{} // studentID → courseIDs
+	for _, e := range completed {
+		if e.GraceUntil == nil || e.ReclaimedAt != nil {
+			continue
+		}
+		if e.WarnedAt == nil && !now.Before(e.GraceUntil.Add(-s.cfg.WarnBefore)) {
+			result.record(s.warn(ctx, e, now))
+		}
+		if !now.Before(*e.GraceUntil) {
+			reclaim[e.StudentID] = append(reclaim[e.StudentID], e.CourseID)
+		}
+	}
+
+	studentIDs := make([]string, 0, len(reclaim))
+	for id := range reclaim {
+		studentIDs = append(studentIDs, id)
+	}
+	sort.Strings(studentIDs)
+	for _, id := range studentIDs {
+		for _, ev := range s.reclaim(ctx, id, reclaim[id], now) {
+			result.record(ev)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *LifecycleService) warn(ctx context.Context, e models.Enrollment, now time.Time) LifecycleEvent {
+	ev := LifecycleEvent{StudentID: e.StudentID, CourseID: e.CourseID, Action: "warned",
+		Detail: "reclaim at " + e.GraceUntil.Format(time.RFC3339)}
+	student, err := s.db.GetStudent(e.StudentID)
+	if err == nil {
+		err = s.notifier.NotifyReclaim(ctx, student, e)
+	}
+	if err == nil {
+		err = s.db.UpdateEnrollment(e.StudentID, e.CourseID, map[string]interface{}{"warned_at": now})
+	}
+	if err != nil {
+		ev.Action, ev.Detail = "failed", fmt.Sprintf("warn: %v", err)
+	}
+	return ev
+}
+
+// reclaim recomputes the student's quota without the ended courses and
+// marks them reclaimed. 리콘실이 실패하면 표시하지 않고 다음 실행에서 재시도한다.
+func (s *LifecycleService) reclaim(ctx context.Context, studentID string, courseIDs []string, now time.Time) []LifecycleEvent {
+	fail := func(err error) []LifecycleEvent {
+		return []LifecycleEvent{{StudentID: studentID, Action: "failed", Detail: fmt.Sprintf("reclaim: %v", err)}}
+	}
+
+	student, err := s.db.GetStudent(studentID)
+	if err != nil {
+		return fail(err)
+	}
+
+	var events []LifecycleEvent
+	if s.cfg.Archive && student.KeystoneProjectID != "" && s.projectMgr != nil {
+		remaining, err := s.hasContributingEnrollment(studentID, now)
+		if err != nil {
+			return fail(err)
+		}
+		if !remaining {
+			shelved, err := s.projectMgr.GetClients().ShelveProjectServers(ctx, student.KeystoneProjectID)
+			if err != nil {
+				return fail(err)
+			}
+			if len(shelved) > 0 {
+				events = append(events, LifecycleEvent{StudentID: studentID, Action: "archived",
+					Detail: fmt.Sprintf("shelved %d servers", len(shelved))})
+			}
+		}
+	}
+
+	summary := s.reconciler.reconcileStudentQuota(ctx, student, ReconcileOptions{})
+	if summary.Status == "failed" {
+		return append(events, fail(errors.New(summary.ErrorMessage))...)
+	}
+	for _, courseID := range courseIDs {
+		ev := LifecycleEvent{StudentID: studentID, CourseID: courseID, Action: "reclaimed", Detail: "quota " + summary.Status}
+		if err := s.db.UpdateEnrollment(studentID, courseID, map[string]interface{}{"reclaimed_at": now}); err != nil {
+			ev.Action, ev.Detail = "failed", err.Error()
+		}
+		events = append(events, ev)
+	}
+	return events
+}
+
+func (s *LifecycleService) hasContributingEnrollment(studentID string, now time.Time) (bool, error) {
+	enrollments, err := s.db.GetStudentEnrollments(studentID)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range enrollments {
+		if e.ContributesQuota(now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"sort"
+	"strings"
+
+	"example.com/quotaapi/internal/models"
+)
+
+// quotaFieldNames are the JSON names of the fixed limits of
+// models.QuotaProfile. 볼륨 타입별 한도는 Cinder 처럼 volumes_<type>,
+// gigabytes_<type> 이름으로 다룬다.
+var quotaFieldNames = []string{
+	"instances", "cores", "ramMB", "volumes", "gigabytes", "ports", "floatingIPs", "snapshots",
+	"networks", "subnets", "routers", "securityGroups", "securityGroupRules",
+	"keyPairs", "serverGroups", "serverGroupMembers", "metadataItems", "backups", "backupGigabytes",
+}
+
+const (
+	volumeTypeVolumesKey   = "volumes_"
+	volumeTypeGigabytesKey = "gigabytes_"
+)
+
+// quotaField returns a pointer to the named fixed limit, or nil for a
+// volume type limit.
+func quotaField(q *models.QuotaProfile, name string) *int {
+	switch name {
+	case "instances":
+		return &q.Instances
+	case "cores":
+		return &q.Cores
+	case "ramMB":
+		return &q.RAMMB
+	case "volumes":
+		return &q.Volumes
+	case "gigabytes":
+		return &q.Gigabytes
+	case "ports":
+		return &q.Ports
+	case "floatingIPs":
+		return &q.FloatingIPs
+	case "snapshots":
+		return &q.Snapshots
+	case "networks":
+		return &q.Networks
+	case "subnets":
+		return &q.Subnets
+	case "routers":
+		return &q.Routers
+	case "securityGroups":
+		return &q.SecurityGroups
+	case "securityGroupRules":
+		return &q.SecurityGroupRules
+	case "keyPairs":
+		return &q.KeyPairs
+	case "serverGroups":
+		return &q.ServerGroups
+	case "serverGroupMembers":
+		return &q.ServerGroupMembers
+	case "metadataItems":
+		return &q.MetadataItems
+	case "backups":
+		return &q.Backups
+	case "backupGigabytes":
+		return &q.BackupGigabytes
+	}
+	return nil
+}
+
+// quotaKeys lists every limit of the profiles so policies can work
+// dimension by dimension: the fixed fields in quotaFieldNames order, then
+// volumes_/gigabytes_ of each volume type any profile sets, by type name.
+func quotaKeys(qs ...models.QuotaProfile) []string {
+	keys := append([]string(nil), quotaFieldNames...)
+	types := map[string]bool{}
+	for _, q := range qs {
+		for name := range q.VolumeTypes {
+			types[name] = true
+		}
+	}
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		keys = append(keys, volumeTypeVolumesKey+name, volumeTypeGigabytesKey+name)
+	}
+	return keys
+}
+
+// splitVolumeTypeKey returns the volume type of a volumes_/gigabytes_ key
+// and whether it names the volume count. ok 는 key 가 볼륨 타입 한도 이름이
+// 아닐 때 false 이며, 호출자가 건너뛸지 실패할지 정한다.
+func splitVolumeTypeKey(key string) (name string, volumes, ok bool) {
+	if name, ok := strings.CutPrefix(key, volumeTypeVolumesKey); ok {
+		return name, true, true
+	}
+	if name, ok := strings.CutPrefix(key, volumeTypeGigabytesKey); ok {
+		return name, false, true
+	}
+	return "", false, false
+}
+
+// quotaValue returns the named limit; a volume type the profile does not
+// set, or a key that names no limit, counts as 0.
+func quotaValue(q models.QuotaProfile, key string) int {
+	if f := quotaField(&q, key); f != nil {
+		return *f
+	}
+	name, volumes, ok := splitVolumeTypeKey(key)
+	if !ok {
+		return 0
+	}
+	t := q.VolumeTypes[name]
+	if volumes {
+		return t.Volumes
+	}
+	return t.Gigabytes
+}
+
+// setQuotaValue sets the named limit. 볼륨 타입 맵은 복사해서 바꾸므로 값으로
+// 복사된 다른 프로파일과 공유되지 않으며, 없던 타입을 0 으로 추가하지는 않는다.
+// 한도 이름이 아닌 key 는 무시한다.
+func setQuotaValue(q *models.QuotaProfile, key string, v int) {
+	if f := quotaField(q, key); f != nil {
+		*f = v
+		return
+	}
+	name, volumes, ok := splitVolumeTypeKey(key)
+	if !ok {
+		return
+	}
+	t, ok := q.VolumeTypes[name]
+	if !ok && v == 0 {
+		return
+	}
+	if volumes {
+		t.Volumes = v
+	} else {
+		t.Gigabytes = v
+	}
+	types := make(map[string]models.VolumeTypeQuota, len(q.VolumeTypes)+1)
+	for n, vt := range q.VolumeTypes {
+		types[n] = vt
+	}
+	types[name] = t
+	q.VolumeTypes = types
+}
+
+// withUnmanagedVolumeTypes returns desired with every volume type that
+// OpenStack reports but no profile sets marked unlimited (-1), so a type
+// dropped from the profiles loses its old per-type limit.
+func withUnmanagedVolumeTypes(desired, actual models.QuotaProfile) models.QuotaProfile {
+	for name := range actual.VolumeTypes {
+		if _, ok := desired.VolumeTypes[name]; !ok {
+			setQuotaValue(&desired, volumeTypeVolumesKey+name, -1)
+			setQuotaValue(&desired, volumeTypeGigabytesKey+name, -1)
+		}
+	}
+	return desired
+}
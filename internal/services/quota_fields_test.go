@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+
+	"example.com/quotaapi/internal/models"
+)
+
+func TestQuotaValueKeys(t *testing.T) {
+	q := models.QuotaProfile{
+		Cores:       4,
+		VolumeTypes: map[string]models.VolumeTypeQuota{"ssd": {Volumes: 2, Gigabytes: 50}},
+	}
+	tests := []struct {
+		key  string
+		set  int
+		want int // set 이후 quotaValue
+	}{
+		{key: "cores", set: 8, want: 8},
+		{key: "volumes_ssd", set: 3, want: 3},
+		{key: "gigabytes_ssd", set: 60, want: 60},
+		{key: "volumes_hdd", set: 0, want: 0}, // 없는 타입을 0 으로 추가하지 않는다
+		{key: "bogus", set: 5, want: 0},       // 한도 이름이 아니면 무시 (panic 하지 않음)
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			setQuotaValue(&q, tt.key, tt.set)
+			if got := quotaValue(q, tt.key); got != tt.want {
+				t.Errorf("quotaValue(%q) = %d, want %d", tt.key, got, tt.want)
+			}
+		})
+	}
+	if _, ok := q.VolumeTypes["hdd"]; ok {
+		t.Errorf("volume type hdd was added: %v", q.VolumeTypes)
+	}
+}
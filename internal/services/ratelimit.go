@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"example.com/quotaapi/internal/config"
+	"example.com/quotaapi/internal/openstack"
+)
+
+// rateLimiter spaces calls at least 1/rps apart (burst 1). 0 이하 rps 는 제한 없음.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(rps float64) *rateLimiter {
+	if rps <= 0 {
+		return &rateLimiter{}
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / rps)}
+}
+
+// Wait blocks until the caller may issue a request or ctx is done.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	if l == nil || l.interval == 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// serviceLimiters holds one limiter per OpenStack service. nil 이면 제한 없음.
+type serviceLimiters map[openstack.QuotaServiceName]*rateLimiter
+
+func newServiceLimiters(cfg config.ReconcileConfig) serviceLimiters {
+	return serviceLimiters{
+		openstack.QuotaNova:    newRateLimiter(cfg.NovaRPS),
+		openstack.QuotaCinder:  newRateLimiter(cfg.CinderRPS),
+		openstack.QuotaNeutron: newRateLimiter(cfg.NeutronRPS),
+	}
+}
+
+// wait waits for the service's limiter (openstack.QuotaThrottle).
+func (l serviceLimiters) wait(ctx context.Context, svc openstack.QuotaServiceName) error {
+	return l[svc].Wait(ctx)
+}
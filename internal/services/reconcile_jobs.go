@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"example.com/quotaapi/internal/config"
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/models"
+)
+
+const (
+	// reconcileJobTimeout bounds one job attempt (예전 수강 후 goroutine 과 같은 30초).
+	reconcileJobTimeout = 30 * time.Second
+	// reconcileJobLease is how long a claimed job stays with its worker;
+	// 워커가 죽으면 이 시간이 지난 뒤 다른 워커가 다시 가져간다.
+	reconcileJobLease = 2 * reconcileJobTimeout
+)
+
+// Reasons recorded on reconcile jobs.
+const (
+	JobReasonEnroll        = "enroll"
+	JobReasonUnenroll      = "unenroll"
+	JobReasonCourseProfile = "course_profile"
+)
+
+// ErrJobNotDead is returned when retrying a job that has not failed for good.
+var ErrJobNotDead = errors.New("only dead jobs can be retried")
+
+// errStudentNotProvisioned fails a job whose student has no Keystone project
+// yet (summary "pending"). 성공으로 끝내면 과목 쿼타가 적용되지 않은 채 남으므로
+// bootstrap 이 끝날 때까지 백오프로 다시 시도한다.
+var errStudentNotProvisioned = errors.New("student has no OpenStack project yet")
+
+// ReconcileJobWorker drains the reconcile_jobs queue written by enrollment
+// changes. 실패한 작업은 지수 백오프로 재시도하고 MaxAttempts 를 넘으면 dead.
+type ReconcileJobWorker struct {
+	db         database.Store
+	reconciler *QuotaReconciliationService
+	cfg        config.JobsConfig
+}
+
+// NewReconcileJobWorker creates a worker; zero cfg fields take the defaults
+// (Workers 는 0 이면 그대로 꺼진다).
+func NewReconcileJobWorker(db database.Store, reconciler *QuotaReconciliationService, cfg config.JobsConfig) *ReconcileJobWorker {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = config.DefaultJobPollInterval
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = config.DefaultJobMaxAttempts
+	}
+	if cfg.BackoffBase <= 0 {
+		cfg.BackoffBase = config.DefaultJobBackoffBase
+	}
+	if cfg.BackoffMax < cfg.BackoffBase {
+		cfg.BackoffMax = max(config.DefaultJobBackoffMax, cfg.BackoffBase)
+	}
+	return &ReconcileJobWorker{db: db, reconciler: reconciler, cfg: cfg}
+}
+
+// Run starts cfg.Workers workers and blocks until ctx is done.
+func (w *ReconcileJobWorker) Run(ctx context.Context) {
+	if w.cfg.Workers <= 0 {
+		log.Println("reconcile jobs: workers disabled (JOBS_WORKERS=0)")
+		return
+	}
+	log.Printf("reconcile jobs: %d workers, up to %d attempts", w.cfg.Workers, w.cfg.MaxAttempts)
+
+	var wg sync.WaitGroup
+	for i := 0; i < w.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// work claims and runs jobs until ctx is done, sleeping PollInterval
+// whenever the queue is empty.
+func (w *ReconcileJobWorker) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := w.db.ClaimReconcileJob(reconcileJobLease)
+		if err != nil {
+			log.Printf("reconcile jobs: %v", err)
+		}
+		if job != nil {
+			w.process(ctx, job)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(w.cfg.PollInterval):
+		}
+	}
+}
+
+// process runs one attempt of job and records the outcome.
+func (w *ReconcileJobWorker) process(ctx context.Context, job *models.ReconcileJob) {
+	jobCtx, cancel := context.WithTimeout(ctx, reconcileJobTimeout)
+	summary, err := w.reconciler.ReconcileQuota(jobCtx, job.StudentID, job.CourseID, ReconcileOptions{})
+	cancel()
+	if err == nil && summary.Status == "pending" {
+		err = fmt.Errorf("%w: %s", errStudentNotProvisioned, summary.ErrorMessage)
+	}
+
+	now := time.Now()
+	job.LockedUntil = nil
+	switch {
+	case err == nil:
+		job.Status = models.ReconcileJobSucceeded
+		job.LastError = ""
+		job.FinishedAt = &now
+	case job.Attempts >= w.cfg.MaxAttempts:
+		job.Status = models.ReconcileJobDead
+		job.LastError = err.Error()
+		job.FinishedAt = &now
+		log.Printf("reconcile jobs: job %d (student %s) dead after %d attempts: %v", job.ID, job.StudentID, job.Attempts, err)
+	default:
+		job.Status = models.ReconcileJobPending
+		job.LastError = err.Error()
+		job.RunAfter = now.Add(w.backoff(job.Attempts))
+		log.Printf("reconcile jobs: job %d (student %s) attempt %d failed, retrying at %s: %v",
+			job.ID, job.StudentID, job.Attempts, job.RunAfter.Format(time.RFC3339), err)
+	}
+	if err := w.db.UpdateReconcileJob(job); err != nil {
+		log.Printf("reconcile jobs: failed to record job %d: %v", job.ID, err)
+	}
+}
+
+// backoff is the wait after the given failed attempt: BackoffBase,
+// 2×, 4×, ... capped at BackoffMax.
+func (w *ReconcileJobWorker) backoff(attempt int) time.Duration {
+	d := w.cfg.BackoffBase
+	for i := 1; i < attempt && d < w.cfg.BackoffMax; i++ {
+		d *= 2
+	}
+	return min(d, w.cfg.BackoffMax)
+}
+
+// RetryDeadJob puts a dead job back in the queue with fresh attempts.
+func (w *ReconcileJobWorker) RetryDeadJob(id int64) (*models.ReconcileJob, error) {
+	job, err := w.db.GetReconcileJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != models.ReconcileJobDead {
+		return nil, fmt.Errorf("%w: job %d is %s", ErrJobNotDead, id, job.Status)
+	}
+	job.Status = models.ReconcileJobPending
+	job.Attempts = 0
+	job.RunAfter = time.Now()
+	job.FinishedAt = nil
+	if err := w.db.UpdateReconcileJob(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
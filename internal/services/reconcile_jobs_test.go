@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"example.com/quotaapi/internal/config"
+	"example.com/quotaapi/internal/database"
+	"example.com/quotaapi/internal/models"
+	"example.com/quotaapi/internal/openstack"
+	"example.com/quotaapi/internal/openstack/openstacktest"
+)
+
+// TestReconcileJobWaitsForBootstrap enrols a student before their Keystone
+// project exists: 작업은 성공으로 끝나지 않고 재시도되며, bootstrap 이후 과목 쿼타가 적용된다.
+func TestReconcileJobWaitsForBootstrap(t *testing.T) {
+	fake := openstacktest.NewServer()
+	defer fake.Close()
+	osc, err := openstack.NewServiceClients(fake.Config())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pm := openstack.NewProjectManager(osc)
+	db := database.NewMemoryStore()
+	reconciler := NewQuotaReconciliationService(db, pm)
+	worker := NewReconcileJobWorker(db, reconciler, config.JobsConfig{Workers: 1, BackoffBase: time.Millisecond})
+	ctx := context.Background()
+
+	now := time.Now()
+	student := &models.Student{StudentID: "20240003", Name: "Park", Department: "cs"}
+	mustCreate(t, db.CreateStudent(student))
+	mustCreate(t, db.CreateCourse(&models.Course{CourseID: "CS102", Title: "DB", Department: "cs", Semester: "2024-1",
+		StartAt: now.Add(-time.Hour), EndAt: now.Add(time.Hour), ProfileName: "lab"}))
+	job := &models.ReconcileJob{StudentID: student.StudentID, CourseID: "CS102", Reason: JobReasonEnroll}
+	mustCreate(t, db.EnrollStudentWithJob(&models.Enrollment{StudentID: student.StudentID, CourseID: "CS102", Status: "active",
+		StartAt: now.Add(-time.Hour), EndAt: now.Add(time.Hour)}, job))
+
+	runJob := func() *models.ReconcileJob {
+		t.Helper()
+		time.Sleep(5 * time.Millisecond) // 백오프가 지나야 다시 가져갈 수 있다
+		claimed, err := db.ClaimReconcileJob(time.Minute)
+		if err != nil || claimed == nil || claimed.ID != job.ID {
+			t.Fatalf("claim = %+v, %v", claimed, err)
+		}
+		worker.process(ctx, claimed)
+		got, err := db.GetReconcileJob(job.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	// 1. 프로젝트가 아직 없으면 재시도 대기
+	if got := runJob(); got.Status != models.ReconcileJobPending || got.LastError == "" {
+		t.Fatalf("before bootstrap: job = %s (%q), want pending with error", got.Status, got.LastError)
+	}
+
+	// 2. bootstrap 이 과목 쿼타까지 적용하고, 다시 실행된 작업은 성공한다
+	bootstrapped, err := NewBootstrapService(db, pm, reconciler).Run(ctx, student.StudentID)
+	if err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	want := models.BuiltinProfiles["basic"].Cores + models.BuiltinProfiles["lab"].Cores
+	if cores := fake.Quota(openstacktest.Compute, bootstrapped.KeystoneProjectID)["cores"].Limit; cores != want {
+		t.Errorf("cores after bootstrap = %d, want %d", cores, want)
+	}
+	if got := runJob(); got.Status != models.ReconcileJobSucceeded {
+		t.Fatalf("after bootstrap: job = %s (%q), want succeeded", got.Status, got.LastError)
+	}
+}
@@ -2,9 +2,14 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
+	"time"
 
+	"example.com/quotaapi/internal/config"
 	"example.com/quotaapi/internal/database"
 	"example.com/quotaapi/internal/models"
 	"example.com/quotaapi/internal/openstack"
@@ -12,197 +17,522 @@ import (
 
 // QuotaReconciliationService handles bulk quota reconciliation
 type QuotaReconciliationService struct {
-	db         *database.Database
-	projectMgr *openstack.ProjectManager
+	db          database.Store
+	projectMgr  *openstack.ProjectManager
+	concurrency int
+	limits      serviceLimiters
+	lease       config.LeaderConfig // 대량 실행 lease 의 TTL 과 갱신 주기
+	holder      string              // 대량 실행 lease 와 실행 기록의 holder (bulkHolder)
 }
 
 // NewQuotaReconciliationService creates a new reconciliation service
-func NewQuotaReconciliationService(db *database.Database, projectMgr *openstack.ProjectManager) *QuotaReconciliationService {
-	return &QuotaReconciliationService{
-		db:         db,
-		projectMgr: projectMgr,
+func NewQuotaReconciliationService(db database.Store, projectMgr *openstack.ProjectManager) *QuotaReconciliationService {
+	s := &QuotaReconciliationService{
+		db:          db,
+		projectMgr:  projectMgr,
+		concurrency: config.DefaultReconcileConcurrency,
 	}
+	return s.WithLease(config.LeaderConfig{})
+}
+
+// WithConfig sets the bulk worker count and per-service rate limits.
+// 설정하지 않으면 기본 동시성에 요청 속도 제한 없이 동작한다.
+func (s *QuotaReconciliationService) WithConfig(cfg config.ReconcileConfig) *QuotaReconciliationService {
+	if cfg.Concurrency > 0 {
+		s.concurrency = cfg.Concurrency
+	}
+	s.limits = newServiceLimiters(cfg)
+	return s
+}
+
+// WithLease identifies this replica on the bulk reconciliation lease, using
+// the leader election ID, TTL and renew interval (LEADER_*).
+func (s *QuotaReconciliationService) WithLease(cfg config.LeaderConfig) *QuotaReconciliationService {
+	s.lease = leaderDefaults(cfg)
+	s.holder = bulkHolder(s.lease.ID)
+	return s
+}
+
+// quotas returns the quota service for the student projects, throttled by
+// the per-service rate limits.
+func (s *QuotaReconciliationService) quotas() *openstack.QuotaService {
+	return openstack.NewQuotaService(s.projectMgr.GetClients()).WithThrottle(s.limits.wait)
+}
+
+// ReconcileOptions changes how a reconciliation is carried out.
+type ReconcileOptions struct {
+	// DryRun computes current limits, desired limits and the per-field diff
+	// without touching OpenStack or recording anything (학기 시작 전 검토용).
+	DryRun bool
+	// Scope limits the bulk run to matching students (비어 있으면 전체)
+	Scope models.ReconciliationScope
 }
 
 // StudentQuotaSummary represents a student's quota summary
 type StudentQuotaSummary struct {
-	StudentID      string              `json:"student_id"`
-	StudentName    string              `json:"student_name"`
-	BaselineQuota  models.QuotaProfile `json:"baseline_quota"`
-	ActiveCourses  []models.Course     `json:"active_courses"`
-	EffectiveQuota models.QuotaProfile `json:"effective_quota"`
-	AppliedQuota   models.QuotaProfile `json:"applied_quota,omitempty"`
-	Status         string              `json:"status"` // success, failed, pending
-	ErrorMessage   string              `json:"error_message,omitempty"`
+	StudentID      string                         `json:"student_id"`
+	StudentName    string                         `json:"student_name"`
+	BaselineQuota  models.QuotaProfile            `json:"baseline_quota"`
+	BaselineSource models.BaselineSource          `json:"baseline_source"`
+	Aggregation    models.AggregationSource       `json:"aggregation"`
+	Contributions  []CourseContribution           `json:"course_contributions,omitempty"`
+	ActiveCourses  []models.Course                `json:"active_courses"`
+	EffectiveQuota models.QuotaProfile            `json:"effective_quota"`
+	CurrentQuota   models.QuotaProfile            `json:"current_quota,omitempty"`  // 리콘실 전 OpenStack 쿼타
+	AppliedQuota   models.QuotaProfile            `json:"applied_quota,omitempty"`  // dry run 이면 적용될 값
+	Drift          []QuotaFieldDiff               `json:"drift,omitempty"`          // 적용 전 OpenStack 값과 달랐던 항목 (없으면 쓰지 않음)
+	Blockers       []models.QuotaBlocker          `json:"quota_blockers,omitempty"` // 사용량 때문에 줄이지 못한 항목
+	ApplyResults   []openstack.QuotaServiceResult `json:"apply_results,omitempty"`  // 서비스별 적용/되돌리기 결과
+	Status         string                         `json:"status"`                   // success, over_quota_pending, failed, pending
+	ErrorMessage   string                         `json:"error_message,omitempty"`
+	DryRun         bool                           `json:"dry_run,omitempty"`
 }
 
 // BulkReconciliationResult represents the result of bulk reconciliation
 type BulkReconciliationResult struct {
+	RunID          int64                 `json:"run_id,omitempty"` // dry run 은 기록하지 않는다
+	DryRun         bool                  `json:"dry_run"`
 	TotalStudents  int                   `json:"total_students"`
 	SuccessCount   int                   `json:"success_count"`
+	OverQuotaCount int                   `json:"over_quota_pending_count"`
 	FailedCount    int                   `json:"failed_count"`
 	PendingCount   int                   `json:"pending_count"`
+	SkippedCount   int                   `json:"skipped_count"`   // 마감 시각 때문에 시작하지 못한 학생 수
+	Partial        bool                  `json:"partial"`         // 모든 학생을 처리하지 못하고 끝났는지
+	Error          string                `json:"error,omitempty"` // Partial 인 이유
 	StudentResults []StudentQuotaSummary `json:"student_results"`
 	Summary        string                `json:"summary"`
 }
 
-// RunBulkReconciliation runs bulk quota reconciliation for all students
-func (s *QuotaReconciliationService) RunBulkReconciliation(ctx context.Context) (*BulkReconciliationResult, error) {
-	log.Println("Starting bulk quota reconciliation...")
+// ErrReconciliationRunning is returned when a bulk run is already in
+// progress in this process or on another replica (스케줄러와 수동 실행이 겹친 경우).
+var ErrReconciliationRunning = errors.New("bulk reconciliation already running")
 
-	// 1. 모든 학생 조회
-	students, err := s.db.GetAllStudents()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get students: %w", err)
+// RunBulkReconciliation runs bulk quota reconciliation for the students in
+// opts.Scope (기본 전체) with a bounded worker pool. 실행과 학생별 결과는 reconciliation_runs 에
+// 기록되며, 학생 하나가 끝날 때마다 진행 상황(processed)이 갱신된다.
+// ctx 가 취소되거나 마감 시각이 지나면 새 학생을 시작하지 않고, 그때까지의
+// 결과를 Partial 로 돌려준다. trigger 는 manual 또는 scheduled.
+// opts.DryRun 이면 OpenStack 과 DB 를 건드리지 않고 학생별 계획만 돌려주며,
+// 실행 중인 다른 리콘실과 겹쳐도 된다.
+func (s *QuotaReconciliationService) RunBulkReconciliation(ctx context.Context, trigger string, opts ReconcileOptions) (*BulkReconciliationResult, error) {
+	if !opts.DryRun {
+		var unlock func()
+		var err error
+		if ctx, unlock, err = s.lockBulk(ctx); err != nil {
+			return nil, err
+		}
+		defer unlock()
 	}
 
-	result := &BulkReconciliationResult{
-		TotalStudents:  len(students),
-		StudentResults: make([]StudentQuotaSummary, 0, len(students)),
+	// 1. 대상 학생 조회
+	students, err := s.selectStudents(opts.Scope)
+	if err != nil {
+		return nil, err
 	}
+	if opts.DryRun {
+		return s.planBulk(ctx, students), nil
+	}
+	var scope *models.ReconciliationScope
+	if !opts.Scope.IsZero() {
+		scope = &opts.Scope
+	}
+	return s.runBulk(ctx, trigger, scope, students)
+}
 
-	// 2. 각 학생별로 리콘실 실행
-	for _, student := range students {
-		studentResult := s.reconcileStudentQuota(ctx, student)
-		result.StudentResults = append(result.StudentResults, studentResult)
+// planBulk is the dry-run counterpart of runBulk; nothing is persisted.
+func (s *QuotaReconciliationService) planBulk(ctx context.Context, students []*models.Student) *BulkReconciliationResult {
+	log.Printf("Planning bulk quota reconciliation (dry run, %d students, %d workers)...", len(students), s.concurrency)
 
-		// 통계 업데이트
-		switch studentResult.Status {
+	result := &BulkReconciliationResult{DryRun: true, TotalStudents: len(students)}
+	summaries := make([]*StudentQuotaSummary, len(students))
+	processed := 0
+	s.runPool(ctx, len(students), func(i int) {
+		summary := s.reconcileStudentQuota(ctx, students[i], ReconcileOptions{DryRun: true})
+		summaries[i] = &summary
+	}, func(i int) {
+		processed++
+		switch summaries[i].Status {
 		case "success":
 			result.SuccessCount++
+		case string(models.QuotaStateOverQuotaPending):
+			result.OverQuotaCount++
 		case "failed":
 			result.FailedCount++
 		case "pending":
 			result.PendingCount++
 		}
+	})
+
+	result.SkippedCount = len(students) - processed
+	result.StudentResults = make([]StudentQuotaSummary, 0, processed)
+	changed := 0
+	for _, summary := range summaries {
+		if summary != nil {
+			result.StudentResults = append(result.StudentResults, *summary)
+			if len(summary.Drift) > 0 {
+				changed++
+			}
+		}
 	}
+	result.Summary = fmt.Sprintf("Dry run: %d of %d students would change, %d over quota pending, %d failed, %d pending",
+		changed, processed, result.OverQuotaCount, result.FailedCount, result.PendingCount)
+	if err := ctx.Err(); err != nil {
+		result.Partial = true
+		result.Error = fmt.Sprintf("stopped after %d of %d students: %v", processed, len(students), err)
+	}
+	return result
+}
+
+// runBulk reconciles the given students as one persisted run; scope is
+// recorded on the run (nil = 전체). 호출 측이 lockBulk 로 잠가 두어야 한다.
+func (s *QuotaReconciliationService) runBulk(ctx context.Context, trigger string, scope *models.ReconciliationScope, students []*models.Student) (*BulkReconciliationResult, error) {
+	log.Printf("Starting bulk quota reconciliation (%s, %d students, %d workers)...", trigger, len(students), s.concurrency)
+
+	run := &models.ReconciliationRun{
+		Trigger:       trigger,
+		Scope:         scope,
+		Status:        models.ReconciliationRunning,
+		Holder:        s.holder,
+		TotalStudents: len(students),
+		StartedAt:     time.Now(),
+	}
+	if err := s.db.CreateReconciliationRun(run); err != nil {
+		return nil, err
+	}
+
+	// 2. 워커 풀로 학생별 리콘실 실행. 결과 기록(run 갱신)은 이 고루틴에서만 한다.
+	summaries := make([]*StudentQuotaSummary, len(students))
+	s.runPool(ctx, len(students), func(i int) {
+		summary := s.reconcileStudentQuota(ctx, students[i], ReconcileOptions{})
+		summaries[i] = &summary
+	}, func(i int) {
+		// 통계 업데이트
+		switch summaries[i].Status {
+		case "success":
+			run.SuccessCount++
+		case string(models.QuotaStateOverQuotaPending):
+			run.OverQuotaCount++
+		case "failed":
+			run.FailedCount++
+		case "pending":
+			run.PendingCount++
+		}
+		s.recordResult(run, *summaries[i])
+	})
 
 	// 3. 결과 요약 생성
-	result.Summary = fmt.Sprintf("Reconciliation completed: %d success, %d failed, %d pending",
-		result.SuccessCount, result.FailedCount, result.PendingCount)
+	result := &BulkReconciliationResult{
+		RunID:          run.ID,
+		TotalStudents:  len(students),
+		SuccessCount:   run.SuccessCount,
+		OverQuotaCount: run.OverQuotaCount,
+		FailedCount:    run.FailedCount,
+		PendingCount:   run.PendingCount,
+		SkippedCount:   run.TotalStudents - run.Processed,
+		StudentResults: make([]StudentQuotaSummary, 0, run.Processed),
+	}
+	for _, summary := range summaries {
+		if summary != nil {
+			result.StudentResults = append(result.StudentResults, *summary)
+		}
+	}
+	counts := fmt.Sprintf("%d success, %d over quota pending, %d failed, %d pending",
+		run.SuccessCount, run.OverQuotaCount, run.FailedCount, run.PendingCount)
+	if err := ctx.Err(); err != nil {
+		result.Partial = true
+		result.Error = fmt.Sprintf("stopped after %d of %d students: %v", run.Processed, run.TotalStudents, err)
+		run.Summary = fmt.Sprintf("Reconciliation interrupted: %s, %d skipped", counts, result.SkippedCount)
+		s.finishRun(run, models.ReconciliationFailed, result.Error)
+	} else {
+		run.Summary = "Reconciliation completed: " + counts
+		s.finishRun(run, models.ReconciliationCompleted, "")
+	}
+	result.Summary = run.Summary
 
-	log.Printf("Bulk reconciliation completed: %s", result.Summary)
+	log.Printf("Bulk reconciliation run %d %s: %s", run.ID, run.Status, result.Summary)
 	return result, nil
 }
 
-// ReconcileQuota reconciles quota for a single student after enrollment changes
-func (s *QuotaReconciliationService) ReconcileQuota(ctx context.Context, studentID, courseID string) error {
+// runPool calls work(i) for every i in [0, n) on at most s.concurrency
+// goroutines and done(i) on the calling goroutine as each one finishes, so
+// done needs no locking. ctx 가 끝나면 새 작업을 시작하지 않고, 이미 시작한
+// 작업이 끝나면 돌아온다.
+func (s *QuotaReconciliationService) runPool(ctx context.Context, n int, work func(i int), done func(i int)) {
+	jobs := make(chan int)
+	finished := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(s.concurrency, max(n, 1)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				work(i)
+				finished <- i
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for i := 0; i < n; i++ {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	for i := range finished {
+		done(i)
+	}
+}
+
+// recordResult persists one student's outcome and the run's progress.
+// 기록 실패는 리콘실 자체를 멈추지 않는다.
+func (s *QuotaReconciliationService) recordResult(run *models.ReconciliationRun, summary StudentQuotaSummary) {
+	run.Processed++
+	detail, err := json.Marshal(summary)
+	if err == nil {
+		err = s.db.AddReconciliationResult(run.ID, &models.ReconciliationResult{
+			StudentID:    summary.StudentID,
+			Status:       summary.Status,
+			ErrorMessage: summary.ErrorMessage,
+			Detail:       detail,
+		})
+	}
+	if err != nil {
+		log.Printf("Warning: failed to record reconciliation result of student %s in run %d: %v", summary.StudentID, run.ID, err)
+	}
+	if err := s.db.UpdateReconciliationRun(run); err != nil {
+		log.Printf("Warning: failed to record progress of reconciliation run %d: %v", run.ID, err)
+	}
+}
+
+func (s *QuotaReconciliationService) finishRun(run *models.ReconciliationRun, status models.ReconciliationRunStatus, errMsg string) {
+	now := time.Now()
+	run.Status = status
+	run.Error = errMsg
+	run.FinishedAt = &now
+	if err := s.db.UpdateReconciliationRun(run); err != nil {
+		log.Printf("Warning: failed to finish reconciliation run %d: %v", run.ID, err)
+	}
+}
+
+// ReconcileQuota reconciles quota for a single student after enrollment
+// changes (courseID 는 로그용, 직접 요청이면 빈 문자열). 학생을 찾지 못하면
+// summary 없이, 리콘실이 실패하면 summary 와 함께 error 를 돌려준다.
+func (s *QuotaReconciliationService) ReconcileQuota(ctx context.Context, studentID, courseID string, opts ReconcileOptions) (*StudentQuotaSummary, error) {
 	// 학생 정보 조회
 	student, err := s.db.GetStudent(studentID)
 	if err != nil {
-		return fmt.Errorf("failed to get student %s: %w", studentID, err)
+		return nil, err
 	}
 
 	// 개별 학생 리콘실 실행
-	summary := s.reconcileStudentQuota(ctx, student)
+	summary := s.reconcileStudentQuota(ctx, student, opts)
 
-	if summary.Status == "success" {
+	switch {
+	case opts.DryRun:
+		log.Printf("Dry run for student %s: %d quota fields would change", studentID, len(summary.Drift))
+	case summary.Status == "success":
 		log.Printf("Successfully reconciled quota for student %s after enrollment in course %s", studentID, courseID)
-	} else {
+	case summary.Status == string(models.QuotaStateOverQuotaPending):
+		log.Printf("Quota for student %s kept above target after course %s until resources are freed: %s",
+			studentID, courseID, describeBlockers(summary.Blockers))
+	default:
 		log.Printf("Failed to reconcile quota for student %s: %s", studentID, summary.ErrorMessage)
 	}
 
-	return nil
+	if summary.Status == "failed" {
+		return &summary, fmt.Errorf("failed to reconcile quota of student %s: %s", studentID, summary.ErrorMessage)
+	}
+	return &summary, nil
 }
 
 // reconcileStudentQuota reconciles quota for a single student
-func (s *QuotaReconciliationService) reconcileStudentQuota(ctx context.Context, student *models.Student) StudentQuotaSummary {
+func (s *QuotaReconciliationService) reconcileStudentQuota(ctx context.Context, student *models.Student, opts ReconcileOptions) StudentQuotaSummary {
 	summary := StudentQuotaSummary{
-		StudentID:     student.StudentID,
-		StudentName:   student.Name,
-		BaselineQuota: models.GetBasicProfile(), // 기본 프로파일
-		Status:        "pending",
+		StudentID:   student.StudentID,
+		StudentName: student.Name,
+		Status:      "pending",
+		DryRun:      opts.DryRun,
+	}
+	if !s.resolveDesiredQuota(student, &summary) {
+		return summary
+	}
+
+	// 4. OpenStack 프로젝트가 있는 경우 쿼타 적용.
+	// 사용량보다 작게 줄이지 않도록 먼저 사용량을 읽고, 읽지 못하면 적용을 미룬다.
+	// 이미 OpenStack 값과 같으면 쓰지 않는다.
+	if student.KeystoneProjectID != "" && s.projectMgr != nil {
+		current, err := s.quotas().Get(ctx, student.KeystoneProjectID)
+		if err != nil {
+			summary.Status = "failed"
+			summary.ErrorMessage = fmt.Sprintf("quota change deferred, failed to read usage: %v", err)
+			return summary
+		}
+		actual, usage := current.Limits(), current.Usage()
+		applied, blockers := clampToUsage(withUnmanagedVolumeTypes(summary.EffectiveQuota, actual), usage)
+		summary.CurrentQuota = actual
+		summary.Drift = diffQuota(applied, actual)
+		if len(summary.Drift) > 0 && !opts.DryRun {
+			results, err := s.applyQuotaToOpenStack(ctx, current, applied)
+			summary.ApplyResults = results
+			if err != nil {
+				summary.Status = "failed"
+				summary.ErrorMessage = fmt.Sprintf("failed to apply quota: %v", err)
+				return summary
+			}
+		}
+		summary.AppliedQuota = applied
+		summary.Blockers = blockers
+		summary.Status = "success"
+		state := models.QuotaStateInSync
+		if len(blockers) > 0 {
+			state = models.QuotaStateOverQuotaPending
+			summary.Status = string(state)
+			summary.ErrorMessage = "resources must be freed before quota can shrink: " + describeBlockers(blockers)
+		}
+		if opts.DryRun {
+			return summary
+		}
+		if err := s.saveQuotaState(student, state, blockers); err != nil {
+			log.Printf("Warning: failed to record quota state of student %s: %v", student.StudentID, err)
+		}
+	} else {
+		summary.Status = "pending"
+		summary.ErrorMessage = "no OpenStack project or project manager"
+	}
+
+	return summary
+}
+
+// resolveDesiredQuota fills the baseline, course contributions and
+// effective quota of summary. 실패하면 summary 를 failed 로 채우고 false 를
+// 돌려준다.
+func (s *QuotaReconciliationService) resolveDesiredQuota(student *models.Student, summary *StudentQuotaSummary) bool {
+	// 0. 가장 구체적인 기본 쿼타 규칙 적용 (학생 > cohort > 학과 > basic)
+	baseline, source, err := ResolveBaseline(s.db, student)
+	summary.BaselineSource = source
+	if err != nil {
+		summary.Status = "failed"
+		summary.ErrorMessage = err.Error()
+		return false
 	}
+	summary.BaselineQuota = baseline.Limits
 
 	// 1. 학생의 활성 수강 과목 조회
 	enrollments, err := s.db.GetStudentEnrollments(student.StudentID)
 	if err != nil {
 		summary.Status = "failed"
 		summary.ErrorMessage = fmt.Sprintf("failed to get enrollments: %v", err)
-		return summary
+		return false
 	}
 
-	// 2. 활성 과목만 필터링
+	// 2. 쿼타에 반영되는 과목(활성 또는 종료 후 유예 기간)만 골라 프로파일을 카탈로그에서 조회
 	var activeCourses []models.Course
+	var courseQuotas []models.QuotaProfile
+	var contributions []CourseContribution
+	now := time.Now()
 	for _, enrollment := range enrollments {
-		if enrollment.Status == "active" {
+		if enrollment.ContributesQuota(now) {
 			course, err := s.db.GetCourse(enrollment.CourseID)
 			if err != nil {
 				log.Printf("Warning: failed to get course %s: %v", enrollment.CourseID, err)
 				continue
 			}
+			profile, err := s.db.GetProfile(course.ProfileName)
+			if err != nil {
+				summary.Status = "failed"
+				summary.ErrorMessage = fmt.Sprintf("failed to resolve profile %q of course %s: %v", course.ProfileName, course.CourseID, err)
+				return false
+			}
 			activeCourses = append(activeCourses, *course)
+			courseQuotas = append(courseQuotas, profile.Limits)
+			contributions = append(contributions, CourseContribution{
+				CourseID:       course.CourseID,
+				Profile:        profile.Name,
+				ProfileVersion: profile.Version,
+				Quota:          profile.Limits,
+				GraceUntil:     enrollment.GraceUntil,
+			})
 		}
 	}
 	summary.ActiveCourses = activeCourses
 
-	// 3. 유효 쿼터 계산: baseline + Σ(활성 과목 쿼타)
-	effectiveQuota := s.calculateEffectiveQuota(summary.BaselineQuota, activeCourses)
-	summary.EffectiveQuota = effectiveQuota
-
-	// 4. OpenStack 프로젝트가 있는 경우 쿼타 적용
-	if student.KeystoneProjectID != "" && s.projectMgr != nil {
-		if err := s.applyQuotaToOpenStack(ctx, student.KeystoneProjectID, effectiveQuota); err != nil {
-			summary.Status = "failed"
-			summary.ErrorMessage = fmt.Sprintf("failed to apply quota: %v", err)
-			return summary
-		}
-		summary.AppliedQuota = effectiveQuota
-		summary.Status = "success"
-	} else {
-		summary.Status = "pending"
-		summary.ErrorMessage = "no OpenStack project or project manager"
+	// 3. 유효 쿼터 계산: baseline + 합산 정책(학과 > 전역 > sum)에 따른 과목 기여분
+	aggregator, aggregation, err := ResolveAggregation(s.db, student.Department)
+	summary.Aggregation = aggregation
+	if err != nil {
+		summary.Status = "failed"
+		summary.ErrorMessage = err.Error()
+		return false
+	}
+	effectiveQuota, perCourse := s.calculateEffectiveQuota(summary.BaselineQuota, aggregator, courseQuotas)
+	for i := range contributions {
+		contributions[i].Contribution = perCourse[i]
 	}
+	summary.Contributions = contributions
+	summary.EffectiveQuota = effectiveQuota
 
-	return summary
+	return true
 }
 
-// calculateEffectiveQuota calculates effective quota by adding course quotas to baseline
-func (s *QuotaReconciliationService) calculateEffectiveQuota(baseline models.QuotaProfile, courses []models.Course) models.QuotaProfile {
+// calculateEffectiveQuota applies the aggregation policy and returns the
+// effective quota together with each course's contribution.
+func (s *QuotaReconciliationService) calculateEffectiveQuota(baseline models.QuotaProfile, aggregator QuotaAggregator, courseQuotas []models.QuotaProfile) (models.QuotaProfile, []models.QuotaProfile) {
 	effective := baseline // baseline 복사
 
-	// 각 활성 과목의 쿼타를 baseline에 추가
-	for _, course := range courses {
-		effective.Instances += course.QuotaProfile.Instances
-		effective.Cores += course.QuotaProfile.Cores
-		effective.RAMMB += course.QuotaProfile.RAMMB
-		effective.Volumes += course.QuotaProfile.Volumes
-		effective.Gigabytes += course.QuotaProfile.Gigabytes
-		effective.Ports += course.QuotaProfile.Ports
-		effective.FloatingIPs += course.QuotaProfile.FloatingIPs
-		effective.Snapshots += course.QuotaProfile.Snapshots
+	contributions := aggregator.Contributions(baseline, courseQuotas)
+	for _, key := range quotaKeys(append([]models.QuotaProfile{baseline}, contributions...)...) {
+		for i := range contributions {
+			setQuotaValue(&effective, key, quotaValue(effective, key)+quotaValue(contributions[i], key))
+		}
 	}
 
-	return effective
+	return effective, contributions
 }
 
-// applyQuotaToOpenStack applies the calculated quota to OpenStack project
-func (s *QuotaReconciliationService) applyQuotaToOpenStack(ctx context.Context, projectID string, quota models.QuotaProfile) error {
-	// Nova 쿼타 적용
-	cores := quota.Cores
-	ramMB := quota.RAMMB
-	instances := quota.Instances
-	if err := s.projectMgr.GetClients().ApplyNovaQuota(ctx, projectID, &cores, &ramMB, &instances); err != nil {
-		return fmt.Errorf("failed to apply Nova quota: %w", err)
+// saveQuotaState records the reconciliation outcome on the student so an
+// over-quota student stays visible until a later run brings it back in sync.
+func (s *QuotaReconciliationService) saveQuotaState(student *models.Student, state models.QuotaState, blockers []models.QuotaBlocker) error {
+	blockersJSON, err := json.Marshal(blockers)
+	if err != nil {
+		return fmt.Errorf("failed to marshal quota blockers: %w", err)
 	}
-
-	// Cinder 쿼타 적용
-	volumes := quota.Volumes
-	gigabytes := quota.Gigabytes
-	snapshots := quota.Snapshots
-	if err := s.projectMgr.GetClients().ApplyCinderQuota(ctx, projectID, &volumes, &snapshots, &gigabytes); err != nil {
-		return fmt.Errorf("failed to apply Cinder quota: %w", err)
+	if err := s.db.UpdateStudent(student.StudentID, map[string]interface{}{
+		"quota_state":    string(state),
+		"quota_blockers": blockersJSON,
+	}); err != nil {
+		return err
 	}
+	student.QuotaState = state
+	student.QuotaBlockers = blockers
+	return nil
+}
 
-	// Neutron 쿼타 적용
-	ports := quota.Ports
-	floatingIPs := quota.FloatingIPs
-	if err := s.projectMgr.GetClients().ApplyNeutronQuota(ctx, projectID, &ports, &floatingIPs); err != nil {
-		return fmt.Errorf("failed to apply Neutron quota: %w", err)
+// applyQuotaToOpenStack applies the calculated quota to the OpenStack
+// project as one unit: 한 서비스라도 실패하면 이미 바꾼 서비스는 current
+// (적용 직전 읽은 값) 로 되돌리고, 서비스별 결과를 돌려준다.
+func (s *QuotaReconciliationService) applyQuotaToOpenStack(ctx context.Context, current *openstack.ProjectQuota, quota models.QuotaProfile) ([]openstack.QuotaServiceResult, error) {
+	projectID := current.ProjectID
+	results, err := s.quotas().ApplyFrom(ctx, current, openstack.QuotaUpdateFromProfile(quota))
+	if err != nil {
+		var svcErr *openstack.QuotaServiceError
+		if errors.As(err, &svcErr) {
+			return results, fmt.Errorf("failed to apply %s quota: %w", svcErr.Service, err)
+		}
+		return results, err
 	}
 
-	log.Printf("Applied quota to project %s: vCPU=%d, RAM=%dMB, Instances=%d, Volumes=%d, Disk=%dGB, Ports=%d, FloatingIPs=%d",
-		projectID, cores, ramMB, instances, volumes, gigabytes, ports, floatingIPs)
+	log.Printf("Applied quota to project %s: vCPU=%d, RAM=%dMB, Instances=%d, Volumes=%d, Disk=%dGB, Ports=%d, FloatingIPs=%d, Networks=%d, Routers=%d, VolumeTypes=%d",
+		projectID, quota.Cores, quota.RAMMB, quota.Instances, quota.Volumes, quota.Gigabytes,
+		quota.Ports, quota.FloatingIPs, quota.Networks, quota.Routers, len(quota.VolumeTypes))
 
-	return nil
+	return results, nil
 }
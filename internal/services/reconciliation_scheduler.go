@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"example.com/quotaapi/internal/config"
+)
+
+// reconciliationRunTimeout bounds one scheduled bulk run.
+const reconciliationRunTimeout = 30 * time.Minute
+
+// ReconciliationScheduler runs bulk reconciliation on a cron-style
+// schedule inside the server process.
+type ReconciliationScheduler struct {
+	service  *QuotaReconciliationService
+	schedule config.Schedule
+
+	mu      sync.Mutex
+	nextRun *time.Time
+}
+
+// NewReconciliationScheduler creates a new reconciliation scheduler
+func NewReconciliationScheduler(service *QuotaReconciliationService, schedule config.Schedule) *ReconciliationScheduler {
+	return &ReconciliationScheduler{service: service, schedule: schedule}
+}
+
+// Schedule returns the configured schedule.
+func (s *ReconciliationScheduler) Schedule() config.Schedule { return s.schedule }
+
+// NextRun returns when the next scheduled run starts (nil if disabled).
+func (s *ReconciliationScheduler) NextRun() *time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nextRun
+}
+
+// Run marks runs left running by a dead process as failed, then runs bulk
+// reconciliation on the schedule until ctx is done. 실행이 다음 예정
+// 시각을 넘기면 놓친 회차는 건너뛴다.
+func (s *ReconciliationScheduler) Run(ctx context.Context) {
+	s.service.FailOrphanedRuns()
+
+	if !s.schedule.Enabled() {
+		log.Println("reconcile scheduler: disabled (RECONCILE_SCHEDULE=off)")
+		return
+	}
+	log.Printf("reconcile scheduler: %s", s.schedule)
+
+	last := time.Now()
+	for {
+		next := s.schedule.Next(last)
+		if now := time.Now(); next.Before(now) {
+			next = s.schedule.Next(now)
+		}
+		s.setNextRun(&next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.setNextRun(nil)
+			return
+		case <-timer.C:
+		}
+
+		last = time.Now()
+		runCtx, cancel := context.WithTimeout(ctx, reconciliationRunTimeout)
+		result, err := s.service.RunBulkReconciliation(runCtx, "scheduled", ReconcileOptions{})
+		cancel()
+		switch {
+		case errors.Is(err, ErrReconciliationRunning):
+			log.Println("reconcile scheduler: previous run still in progress, skipping")
+		case err != nil:
+			log.Printf("reconcile scheduler: run failed: %v", err)
+		case result.Partial:
+			log.Printf("reconcile scheduler: run %d incomplete: %s", result.RunID, result.Error)
+		}
+	}
+}
+
+func (s *ReconciliationScheduler) setNextRun(t *time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextRun = t
+}
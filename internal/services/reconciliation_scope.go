@@ -0,0 +1,100 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"example.com/quotaapi/internal/models"
+)
+
+// ErrInvalidScope is returned when a scope names a student or course that
+// does not exist.
+var ErrInvalidScope = errors.New("invalid reconciliation scope")
+
+// selectStudents returns the students matching every condition of scope,
+// in GetAllStudents order. 빈 scope 는 전체 학생.
+func (s *QuotaReconciliationService) selectStudents(scope models.ReconciliationScope) ([]*models.Student, error) {
+	students, err := s.db.GetAllStudents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get students: %w", err)
+	}
+	if scope.IsZero() {
+		return students, nil
+	}
+
+	// 조건마다 허용 학생 집합을 만들고 모두에 속한 학생만 남긴다
+	var sets []map[string]bool
+	if len(scope.StudentIDs) > 0 {
+		known := make(map[string]bool, len(students))
+		for _, student := range students {
+			known[student.StudentID] = true
+		}
+		set := make(map[string]bool, len(scope.StudentIDs))
+		for _, id := range scope.StudentIDs {
+			if !known[id] {
+				return nil, fmt.Errorf("%w: student %s not found", ErrInvalidScope, id)
+			}
+			set[id] = true
+		}
+		sets = append(sets, set)
+	}
+	if scope.CourseID != "" {
+		if _, err := s.db.GetCourse(scope.CourseID); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidScope, err)
+		}
+		set, err := s.courseStudents(scope.CourseID)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, set)
+	}
+	if scope.Semester != "" {
+		courses, err := s.db.ListCourses("", scope.Semester)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list courses of semester %s: %w", scope.Semester, err)
+		}
+		set := map[string]bool{}
+		for _, course := range courses {
+			ids, err := s.courseStudents(course.CourseID)
+			if err != nil {
+				return nil, err
+			}
+			for id := range ids {
+				set[id] = true
+			}
+		}
+		sets = append(sets, set)
+	}
+
+	var selected []*models.Student
+	for _, student := range students {
+		if scope.Department != "" && student.Department != scope.Department {
+			continue
+		}
+		in := true
+		for _, set := range sets {
+			if !set[student.StudentID] {
+				in = false
+				break
+			}
+		}
+		if in {
+			selected = append(selected, student)
+		}
+	}
+	return selected, nil
+}
+
+// courseStudents returns the IDs of every student enrolled in the course,
+// whatever the enrollment status (종료·철회된 수강도 쿼타에서 빠져야 하므로).
+func (s *QuotaReconciliationService) courseStudents(courseID string) (map[string]bool, error) {
+	enrollments, err := s.db.ListCourseEnrollments(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list enrollments of course %s: %w", courseID, err)
+	}
+	ids := make(map[string]bool, len(enrollments))
+	for _, e := range enrollments {
+		ids[e.StudentID] = true
+	}
+	return ids, nil
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"fmt"
+
+	"example.com/quotaapi/internal/models"
+)
+
+// clampToUsage never lets a limit drop below what the project already uses.
+// 목표치가 사용량보다 작은 항목은 사용량으로 올려 적용하고 반납해야 할 양을
+// blocker 로 돌려준다. -1 (제한 없음) 은 그대로 둔다.
+func clampToUsage(target models.QuotaProfile, usage models.QuotaProfile) (models.QuotaProfile, []models.QuotaBlocker) {
+	applied := target
+	var blockers []models.QuotaBlocker
+	for _, key := range quotaKeys(target) {
+		want := quotaValue(target, key)
+		inUse := quotaValue(usage, key)
+		if want < 0 || want >= inUse {
+			continue
+		}
+		setQuotaValue(&applied, key, inUse)
+		blockers = append(blockers, models.QuotaBlocker{
+			Resource: key,
+			Target:   want,
+			InUse:    inUse,
+			Applied:  inUse,
+			MustFree: inUse - want,
+		})
+	}
+	return applied, blockers
+}
+
+// describeBlockers renders blockers for logs and error messages.
+func describeBlockers(blockers []models.QuotaBlocker) string {
+	msg := ""
+	for i, b := range blockers {
+		if i > 0 {
+			msg += ", "
+		}
+		msg += fmt.Sprintf("%s: free %d (in use %d, target %d)", b.Resource, b.MustFree, b.InUse, b.Target)
+	}
+	return msg
+}
@@ -60,15 +60,7 @@ run_tests() {
         "semester": "2025-1",
         "start_at": "2025-03-01",
         "end_at": "2025-06-30",
-        "quota_profile": {
-            "instances": 2,
-            "cores": 4,
-            "ramMB": 8192,
-            "volumes": 2,
-            "gigabytes": 100,
-            "ports": 2,
-            "floatingIPs": 1
-        },
+        "profile": "lab",
         "defaults": {
             "imageId": "ubuntu-20.04",
             "flavorIds": ["m1.medium", "m1.large"],